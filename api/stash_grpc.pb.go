@@ -20,28 +20,119 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Transporter_SendChunks_FullMethodName         = "/Transporter/SendChunks"
-	Transporter_GetDestination_FullMethodName     = "/Transporter/GetDestination"
-	Transporter_ReceiveInfo_FullMethodName        = "/Transporter/ReceiveInfo"
-	Transporter_ReceiveChunks_FullMethodName      = "/Transporter/ReceiveChunks"
-	Transporter_SyncNodes_FullMethodName          = "/Transporter/SyncNodes"
-	Transporter_Rebase_FullMethodName             = "/Transporter/Rebase"
-	Transporter_AnnounceNewNode_FullMethodName    = "/Transporter/AnnounceNewNode"
-	Transporter_AnnounceRemoveNode_FullMethodName = "/Transporter/AnnounceRemoveNode"
+	Transporter_SendChunks_FullMethodName            = "/Transporter/SendChunks"
+	Transporter_GetDestination_FullMethodName        = "/Transporter/GetDestination"
+	Transporter_ReceiveInfo_FullMethodName           = "/Transporter/ReceiveInfo"
+	Transporter_ReceiveChunks_FullMethodName         = "/Transporter/ReceiveChunks"
+	Transporter_Stat_FullMethodName                  = "/Transporter/Stat"
+	Transporter_ListKeys_FullMethodName              = "/Transporter/ListKeys"
+	Transporter_Identify_FullMethodName              = "/Transporter/Identify"
+	Transporter_SyncNodes_FullMethodName             = "/Transporter/SyncNodes"
+	Transporter_Rebase_FullMethodName                = "/Transporter/Rebase"
+	Transporter_WatchRebase_FullMethodName           = "/Transporter/WatchRebase"
+	Transporter_CancelRebase_FullMethodName          = "/Transporter/CancelRebase"
+	Transporter_AnnounceNewNode_FullMethodName       = "/Transporter/AnnounceNewNode"
+	Transporter_AnnounceRemoveNode_FullMethodName    = "/Transporter/AnnounceRemoveNode"
+	Transporter_DeleteKey_FullMethodName             = "/Transporter/DeleteKey"
+	Transporter_DeleteHash_FullMethodName            = "/Transporter/DeleteHash"
+	Transporter_GetScrubReport_FullMethodName        = "/Transporter/GetScrubReport"
+	Transporter_CollectGarbage_FullMethodName        = "/Transporter/CollectGarbage"
+	Transporter_GetPlacementReport_FullMethodName    = "/Transporter/GetPlacementReport"
+	Transporter_SetNodeState_FullMethodName          = "/Transporter/SetNodeState"
+	Transporter_Decommission_FullMethodName          = "/Transporter/Decommission"
+	Transporter_GetDecommissionStatus_FullMethodName = "/Transporter/GetDecommissionStatus"
 )
 
 // TransporterClient is the client API for Transporter service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type TransporterClient interface {
+	// SendChunks is used to upload Chunks of data to the Stash. Recommended
+	// chunk size is 32Kb, for more info see: https://github.com/grpc/grpc.github.io/issues/371
 	SendChunks(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[Chunk, StreamStatus], error)
+	// GetDestination uses KeyRequest to get information about a node where
+	// the data will be saved. If the node responsible for the key is unavailable,
+	// the first available node storing its replica is returned. Nodes are picked
+	// according to their states and the access of the request (see KeyRequest.Access).
 	GetDestination(ctx context.Context, in *KeyRequest, opts ...grpc.CallOption) (*NodeInfo, error)
+	// ReceiveInfo returns a list of files stored under a certain key.
 	ReceiveInfo(ctx context.Context, in *ReceiveInfoRequest, opts ...grpc.CallOption) (*ReceiveInfoResponse, error)
+	// ReceiveChunks returns the file based on the supplied hash. Optional offset
+	// and length can be used to receive only a byte range of the file.
 	ReceiveChunks(ctx context.Context, in *ReceiveChunkRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReceiveChunkResponse], error)
+	// Stat returns metadata of the file with the supplied hash without
+	// transferring its content. If the file is not stored on the target node,
+	// the response has exists set to false.
+	Stat(ctx context.Context, in *StatRequest, opts ...grpc.CallOption) (*StatResponse, error)
+	// ListKeys streams keys starting with the supplied prefix in lexicographical
+	// order, page by page. Every page contains a continuation token, which can be
+	// used to resume listing right after that page. If cluster is set, keys stored
+	// on all the nodes are listed.
+	ListKeys(ctx context.Context, in *ListKeysRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ListKeysResponse], error)
+	// Identify returns NodeInfo of the target node itself, including its id.
+	// Nodes known only by their address (e.g. from config) are identified
+	// this way to be placed by their ids.
+	Identify(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*NodeInfo, error)
+	// SyncNodes returns a list of nodes known by the target node,
+	// epochs of the nodes are the ring epoch of the target node.
 	SyncNodes(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[NodeInfo], error)
-	Rebase(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Rebase will start a process of rebasing files.
+	// During rebase all the stored files will be checked on whether or not they should
+	// be stored on the current node. If not, the node will attempt to move the files
+	// to other nodes. The rebase runs in the background as a job, which plans
+	// the keys and bytes moved to every node first. Returns the started job,
+	// or the finished job with its plan if dry_run is set. Only one job runs at a time.
+	Rebase(ctx context.Context, in *RebaseRequest, opts ...grpc.CallOption) (*RebaseJob, error)
+	// WatchRebase streams the rebase job every time it makes progress,
+	// until the job is finished. The last job is watched if id is empty.
+	WatchRebase(ctx context.Context, in *RebaseJobRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RebaseJob], error)
+	// CancelRebase stops the rebase job. Keys which are already copied are
+	// removed from the target node, the rest of them are kept. A cancelled job
+	// can be resumed from its checkpoint (see RebaseRequest.resume).
+	CancelRebase(ctx context.Context, in *RebaseJobRequest, opts ...grpc.CallOption) (*RebaseJob, error)
+	// AnnounceNewNode will make the target node announce the new NodeInfo to all the
+	// other nodes it's connected to. It is recommended to trigger rebase after adding
+	// a new node to re-distribute files.
 	AnnounceNewNode(ctx context.Context, in *NodeInfo, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// AnnounceRemoveNode will make the target node announce other nodes to stop
+	// connecting to a certain node.
 	AnnounceRemoveNode(ctx context.Context, in *NodeInfo, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// DeleteKey removes all the files stored under the key. The request is routed
+	// to the node responsible for the key and all of its replicas, the response
+	// contains a status for each of them. Files shared with other keys are kept,
+	// the rest of them are removed by the garbage collector.
+	DeleteKey(ctx context.Context, in *DeleteKeyRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	// DeleteHash removes the file with the supplied hash from the key. The key is
+	// also used to route the request to the node responsible for it and all of its
+	// replicas, the response contains a status for each of them.
+	DeleteHash(ctx context.Context, in *DeleteHashRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	// GetScrubReport returns the report of the last integrity check of the files
+	// stored on the target node. Corrupted files are listed along with the reason
+	// and whether they were repaired using a copy from another node.
+	GetScrubReport(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ScrubReport, error)
+	// CollectGarbage removes files which are not referenced by any key from the
+	// target node. If dry_run is set, the files are only reported.
+	CollectGarbage(ctx context.Context, in *CollectGarbageRequest, opts ...grpc.CallOption) (*CollectGarbageResponse, error)
+	// GetPlacementReport checks placement of the keys stored on the target node
+	// and lists under-diversified keys, which available copies span fewer zones
+	// than the number of copies. Such keys can't survive a failure of a single zone,
+	// e.g. when there are fewer zones than copies or when nodes storing copies
+	// are unavailable. If limit is set, at most limit keys are listed.
+	GetPlacementReport(ctx context.Context, in *PlacementReportRequest, opts ...grpc.CallOption) (*PlacementReport, error)
+	// SetNodeState moves a node to another lifecycle state (see NodeInfo.State).
+	// The request is forwarded to the node itself, which spreads its new state
+	// to the cluster, so the node must be available. Returns NodeInfo of the node
+	// in the new state. Invalid transitions (e.g. from dead to active) are rejected.
+	SetNodeState(ctx context.Context, in *SetNodeStateRequest, opts ...grpc.CallOption) (*NodeInfo, error)
+	// Decommission retires the target node. The node becomes draining and copies
+	// every key it stores to the nodes which take writes of the key, verifying
+	// every transferred file. Then it becomes leaving, announces its removal
+	// to other nodes and shuts down. Progress is saved, so a failed or interrupted
+	// decommission is resumed by calling Decommission again (or by restarting the node).
+	// Returns the status of the started decommission.
+	Decommission(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*DecommissionStatus, error)
+	// GetDecommissionStatus returns the progress of the decommission of the target node.
+	GetDecommissionStatus(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*DecommissionStatus, error)
 }
 
 type transporterClient struct {
@@ -104,9 +195,48 @@ func (c *transporterClient) ReceiveChunks(ctx context.Context, in *ReceiveChunkR
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type Transporter_ReceiveChunksClient = grpc.ServerStreamingClient[ReceiveChunkResponse]
 
+func (c *transporterClient) Stat(ctx context.Context, in *StatRequest, opts ...grpc.CallOption) (*StatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatResponse)
+	err := c.cc.Invoke(ctx, Transporter_Stat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transporterClient) ListKeys(ctx context.Context, in *ListKeysRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ListKeysResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Transporter_ServiceDesc.Streams[2], Transporter_ListKeys_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListKeysRequest, ListKeysResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Transporter_ListKeysClient = grpc.ServerStreamingClient[ListKeysResponse]
+
+func (c *transporterClient) Identify(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*NodeInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NodeInfo)
+	err := c.cc.Invoke(ctx, Transporter_Identify_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *transporterClient) SyncNodes(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[NodeInfo], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &Transporter_ServiceDesc.Streams[2], Transporter_SyncNodes_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &Transporter_ServiceDesc.Streams[3], Transporter_SyncNodes_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -123,9 +253,9 @@ func (c *transporterClient) SyncNodes(ctx context.Context, in *emptypb.Empty, op
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type Transporter_SyncNodesClient = grpc.ServerStreamingClient[NodeInfo]
 
-func (c *transporterClient) Rebase(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+func (c *transporterClient) Rebase(ctx context.Context, in *RebaseRequest, opts ...grpc.CallOption) (*RebaseJob, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(emptypb.Empty)
+	out := new(RebaseJob)
 	err := c.cc.Invoke(ctx, Transporter_Rebase_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
@@ -133,6 +263,35 @@ func (c *transporterClient) Rebase(ctx context.Context, in *emptypb.Empty, opts
 	return out, nil
 }
 
+func (c *transporterClient) WatchRebase(ctx context.Context, in *RebaseJobRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RebaseJob], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Transporter_ServiceDesc.Streams[4], Transporter_WatchRebase_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RebaseJobRequest, RebaseJob]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Transporter_WatchRebaseClient = grpc.ServerStreamingClient[RebaseJob]
+
+func (c *transporterClient) CancelRebase(ctx context.Context, in *RebaseJobRequest, opts ...grpc.CallOption) (*RebaseJob, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RebaseJob)
+	err := c.cc.Invoke(ctx, Transporter_CancelRebase_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *transporterClient) AnnounceNewNode(ctx context.Context, in *NodeInfo, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(emptypb.Empty)
@@ -153,18 +312,176 @@ func (c *transporterClient) AnnounceRemoveNode(ctx context.Context, in *NodeInfo
 	return out, nil
 }
 
+func (c *transporterClient) DeleteKey(ctx context.Context, in *DeleteKeyRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, Transporter_DeleteKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transporterClient) DeleteHash(ctx context.Context, in *DeleteHashRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, Transporter_DeleteHash_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transporterClient) GetScrubReport(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ScrubReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScrubReport)
+	err := c.cc.Invoke(ctx, Transporter_GetScrubReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transporterClient) CollectGarbage(ctx context.Context, in *CollectGarbageRequest, opts ...grpc.CallOption) (*CollectGarbageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CollectGarbageResponse)
+	err := c.cc.Invoke(ctx, Transporter_CollectGarbage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transporterClient) GetPlacementReport(ctx context.Context, in *PlacementReportRequest, opts ...grpc.CallOption) (*PlacementReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PlacementReport)
+	err := c.cc.Invoke(ctx, Transporter_GetPlacementReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transporterClient) SetNodeState(ctx context.Context, in *SetNodeStateRequest, opts ...grpc.CallOption) (*NodeInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NodeInfo)
+	err := c.cc.Invoke(ctx, Transporter_SetNodeState_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transporterClient) Decommission(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*DecommissionStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DecommissionStatus)
+	err := c.cc.Invoke(ctx, Transporter_Decommission_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transporterClient) GetDecommissionStatus(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*DecommissionStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DecommissionStatus)
+	err := c.cc.Invoke(ctx, Transporter_GetDecommissionStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TransporterServer is the server API for Transporter service.
 // All implementations must embed UnimplementedTransporterServer
 // for forward compatibility.
 type TransporterServer interface {
+	// SendChunks is used to upload Chunks of data to the Stash. Recommended
+	// chunk size is 32Kb, for more info see: https://github.com/grpc/grpc.github.io/issues/371
 	SendChunks(grpc.ClientStreamingServer[Chunk, StreamStatus]) error
+	// GetDestination uses KeyRequest to get information about a node where
+	// the data will be saved. If the node responsible for the key is unavailable,
+	// the first available node storing its replica is returned. Nodes are picked
+	// according to their states and the access of the request (see KeyRequest.Access).
 	GetDestination(context.Context, *KeyRequest) (*NodeInfo, error)
+	// ReceiveInfo returns a list of files stored under a certain key.
 	ReceiveInfo(context.Context, *ReceiveInfoRequest) (*ReceiveInfoResponse, error)
+	// ReceiveChunks returns the file based on the supplied hash. Optional offset
+	// and length can be used to receive only a byte range of the file.
 	ReceiveChunks(*ReceiveChunkRequest, grpc.ServerStreamingServer[ReceiveChunkResponse]) error
+	// Stat returns metadata of the file with the supplied hash without
+	// transferring its content. If the file is not stored on the target node,
+	// the response has exists set to false.
+	Stat(context.Context, *StatRequest) (*StatResponse, error)
+	// ListKeys streams keys starting with the supplied prefix in lexicographical
+	// order, page by page. Every page contains a continuation token, which can be
+	// used to resume listing right after that page. If cluster is set, keys stored
+	// on all the nodes are listed.
+	ListKeys(*ListKeysRequest, grpc.ServerStreamingServer[ListKeysResponse]) error
+	// Identify returns NodeInfo of the target node itself, including its id.
+	// Nodes known only by their address (e.g. from config) are identified
+	// this way to be placed by their ids.
+	Identify(context.Context, *emptypb.Empty) (*NodeInfo, error)
+	// SyncNodes returns a list of nodes known by the target node,
+	// epochs of the nodes are the ring epoch of the target node.
 	SyncNodes(*emptypb.Empty, grpc.ServerStreamingServer[NodeInfo]) error
-	Rebase(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	// Rebase will start a process of rebasing files.
+	// During rebase all the stored files will be checked on whether or not they should
+	// be stored on the current node. If not, the node will attempt to move the files
+	// to other nodes. The rebase runs in the background as a job, which plans
+	// the keys and bytes moved to every node first. Returns the started job,
+	// or the finished job with its plan if dry_run is set. Only one job runs at a time.
+	Rebase(context.Context, *RebaseRequest) (*RebaseJob, error)
+	// WatchRebase streams the rebase job every time it makes progress,
+	// until the job is finished. The last job is watched if id is empty.
+	WatchRebase(*RebaseJobRequest, grpc.ServerStreamingServer[RebaseJob]) error
+	// CancelRebase stops the rebase job. Keys which are already copied are
+	// removed from the target node, the rest of them are kept. A cancelled job
+	// can be resumed from its checkpoint (see RebaseRequest.resume).
+	CancelRebase(context.Context, *RebaseJobRequest) (*RebaseJob, error)
+	// AnnounceNewNode will make the target node announce the new NodeInfo to all the
+	// other nodes it's connected to. It is recommended to trigger rebase after adding
+	// a new node to re-distribute files.
 	AnnounceNewNode(context.Context, *NodeInfo) (*emptypb.Empty, error)
+	// AnnounceRemoveNode will make the target node announce other nodes to stop
+	// connecting to a certain node.
 	AnnounceRemoveNode(context.Context, *NodeInfo) (*emptypb.Empty, error)
+	// DeleteKey removes all the files stored under the key. The request is routed
+	// to the node responsible for the key and all of its replicas, the response
+	// contains a status for each of them. Files shared with other keys are kept,
+	// the rest of them are removed by the garbage collector.
+	DeleteKey(context.Context, *DeleteKeyRequest) (*DeleteResponse, error)
+	// DeleteHash removes the file with the supplied hash from the key. The key is
+	// also used to route the request to the node responsible for it and all of its
+	// replicas, the response contains a status for each of them.
+	DeleteHash(context.Context, *DeleteHashRequest) (*DeleteResponse, error)
+	// GetScrubReport returns the report of the last integrity check of the files
+	// stored on the target node. Corrupted files are listed along with the reason
+	// and whether they were repaired using a copy from another node.
+	GetScrubReport(context.Context, *emptypb.Empty) (*ScrubReport, error)
+	// CollectGarbage removes files which are not referenced by any key from the
+	// target node. If dry_run is set, the files are only reported.
+	CollectGarbage(context.Context, *CollectGarbageRequest) (*CollectGarbageResponse, error)
+	// GetPlacementReport checks placement of the keys stored on the target node
+	// and lists under-diversified keys, which available copies span fewer zones
+	// than the number of copies. Such keys can't survive a failure of a single zone,
+	// e.g. when there are fewer zones than copies or when nodes storing copies
+	// are unavailable. If limit is set, at most limit keys are listed.
+	GetPlacementReport(context.Context, *PlacementReportRequest) (*PlacementReport, error)
+	// SetNodeState moves a node to another lifecycle state (see NodeInfo.State).
+	// The request is forwarded to the node itself, which spreads its new state
+	// to the cluster, so the node must be available. Returns NodeInfo of the node
+	// in the new state. Invalid transitions (e.g. from dead to active) are rejected.
+	SetNodeState(context.Context, *SetNodeStateRequest) (*NodeInfo, error)
+	// Decommission retires the target node. The node becomes draining and copies
+	// every key it stores to the nodes which take writes of the key, verifying
+	// every transferred file. Then it becomes leaving, announces its removal
+	// to other nodes and shuts down. Progress is saved, so a failed or interrupted
+	// decommission is resumed by calling Decommission again (or by restarting the node).
+	// Returns the status of the started decommission.
+	Decommission(context.Context, *emptypb.Empty) (*DecommissionStatus, error)
+	// GetDecommissionStatus returns the progress of the decommission of the target node.
+	GetDecommissionStatus(context.Context, *emptypb.Empty) (*DecommissionStatus, error)
 	mustEmbedUnimplementedTransporterServer()
 }
 
@@ -187,18 +504,57 @@ func (UnimplementedTransporterServer) ReceiveInfo(context.Context, *ReceiveInfoR
 func (UnimplementedTransporterServer) ReceiveChunks(*ReceiveChunkRequest, grpc.ServerStreamingServer[ReceiveChunkResponse]) error {
 	return status.Errorf(codes.Unimplemented, "method ReceiveChunks not implemented")
 }
+func (UnimplementedTransporterServer) Stat(context.Context, *StatRequest) (*StatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stat not implemented")
+}
+func (UnimplementedTransporterServer) ListKeys(*ListKeysRequest, grpc.ServerStreamingServer[ListKeysResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ListKeys not implemented")
+}
+func (UnimplementedTransporterServer) Identify(context.Context, *emptypb.Empty) (*NodeInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Identify not implemented")
+}
 func (UnimplementedTransporterServer) SyncNodes(*emptypb.Empty, grpc.ServerStreamingServer[NodeInfo]) error {
 	return status.Errorf(codes.Unimplemented, "method SyncNodes not implemented")
 }
-func (UnimplementedTransporterServer) Rebase(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+func (UnimplementedTransporterServer) Rebase(context.Context, *RebaseRequest) (*RebaseJob, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Rebase not implemented")
 }
+func (UnimplementedTransporterServer) WatchRebase(*RebaseJobRequest, grpc.ServerStreamingServer[RebaseJob]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchRebase not implemented")
+}
+func (UnimplementedTransporterServer) CancelRebase(context.Context, *RebaseJobRequest) (*RebaseJob, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelRebase not implemented")
+}
 func (UnimplementedTransporterServer) AnnounceNewNode(context.Context, *NodeInfo) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AnnounceNewNode not implemented")
 }
 func (UnimplementedTransporterServer) AnnounceRemoveNode(context.Context, *NodeInfo) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AnnounceRemoveNode not implemented")
 }
+func (UnimplementedTransporterServer) DeleteKey(context.Context, *DeleteKeyRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteKey not implemented")
+}
+func (UnimplementedTransporterServer) DeleteHash(context.Context, *DeleteHashRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteHash not implemented")
+}
+func (UnimplementedTransporterServer) GetScrubReport(context.Context, *emptypb.Empty) (*ScrubReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetScrubReport not implemented")
+}
+func (UnimplementedTransporterServer) CollectGarbage(context.Context, *CollectGarbageRequest) (*CollectGarbageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CollectGarbage not implemented")
+}
+func (UnimplementedTransporterServer) GetPlacementReport(context.Context, *PlacementReportRequest) (*PlacementReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPlacementReport not implemented")
+}
+func (UnimplementedTransporterServer) SetNodeState(context.Context, *SetNodeStateRequest) (*NodeInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetNodeState not implemented")
+}
+func (UnimplementedTransporterServer) Decommission(context.Context, *emptypb.Empty) (*DecommissionStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Decommission not implemented")
+}
+func (UnimplementedTransporterServer) GetDecommissionStatus(context.Context, *emptypb.Empty) (*DecommissionStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDecommissionStatus not implemented")
+}
 func (UnimplementedTransporterServer) mustEmbedUnimplementedTransporterServer() {}
 func (UnimplementedTransporterServer) testEmbeddedByValue()                     {}
 
@@ -274,6 +630,53 @@ func _Transporter_ReceiveChunks_Handler(srv interface{}, stream grpc.ServerStrea
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type Transporter_ReceiveChunksServer = grpc.ServerStreamingServer[ReceiveChunkResponse]
 
+func _Transporter_Stat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransporterServer).Stat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Transporter_Stat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransporterServer).Stat(ctx, req.(*StatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transporter_ListKeys_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListKeysRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TransporterServer).ListKeys(m, &grpc.GenericServerStream[ListKeysRequest, ListKeysResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Transporter_ListKeysServer = grpc.ServerStreamingServer[ListKeysResponse]
+
+func _Transporter_Identify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransporterServer).Identify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Transporter_Identify_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransporterServer).Identify(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Transporter_SyncNodes_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(emptypb.Empty)
 	if err := stream.RecvMsg(m); err != nil {
@@ -286,7 +689,7 @@ func _Transporter_SyncNodes_Handler(srv interface{}, stream grpc.ServerStream) e
 type Transporter_SyncNodesServer = grpc.ServerStreamingServer[NodeInfo]
 
 func _Transporter_Rebase_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(emptypb.Empty)
+	in := new(RebaseRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -298,7 +701,36 @@ func _Transporter_Rebase_Handler(srv interface{}, ctx context.Context, dec func(
 		FullMethod: Transporter_Rebase_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TransporterServer).Rebase(ctx, req.(*emptypb.Empty))
+		return srv.(TransporterServer).Rebase(ctx, req.(*RebaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transporter_WatchRebase_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RebaseJobRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TransporterServer).WatchRebase(m, &grpc.GenericServerStream[RebaseJobRequest, RebaseJob]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Transporter_WatchRebaseServer = grpc.ServerStreamingServer[RebaseJob]
+
+func _Transporter_CancelRebase_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RebaseJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransporterServer).CancelRebase(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Transporter_CancelRebase_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransporterServer).CancelRebase(ctx, req.(*RebaseJobRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -339,6 +771,150 @@ func _Transporter_AnnounceRemoveNode_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Transporter_DeleteKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransporterServer).DeleteKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Transporter_DeleteKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransporterServer).DeleteKey(ctx, req.(*DeleteKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transporter_DeleteHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteHashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransporterServer).DeleteHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Transporter_DeleteHash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransporterServer).DeleteHash(ctx, req.(*DeleteHashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transporter_GetScrubReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransporterServer).GetScrubReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Transporter_GetScrubReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransporterServer).GetScrubReport(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transporter_CollectGarbage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CollectGarbageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransporterServer).CollectGarbage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Transporter_CollectGarbage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransporterServer).CollectGarbage(ctx, req.(*CollectGarbageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transporter_GetPlacementReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlacementReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransporterServer).GetPlacementReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Transporter_GetPlacementReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransporterServer).GetPlacementReport(ctx, req.(*PlacementReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transporter_SetNodeState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetNodeStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransporterServer).SetNodeState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Transporter_SetNodeState_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransporterServer).SetNodeState(ctx, req.(*SetNodeStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transporter_Decommission_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransporterServer).Decommission(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Transporter_Decommission_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransporterServer).Decommission(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transporter_GetDecommissionStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransporterServer).GetDecommissionStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Transporter_GetDecommissionStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransporterServer).GetDecommissionStatus(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Transporter_ServiceDesc is the grpc.ServiceDesc for Transporter service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -354,10 +930,22 @@ var Transporter_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ReceiveInfo",
 			Handler:    _Transporter_ReceiveInfo_Handler,
 		},
+		{
+			MethodName: "Stat",
+			Handler:    _Transporter_Stat_Handler,
+		},
+		{
+			MethodName: "Identify",
+			Handler:    _Transporter_Identify_Handler,
+		},
 		{
 			MethodName: "Rebase",
 			Handler:    _Transporter_Rebase_Handler,
 		},
+		{
+			MethodName: "CancelRebase",
+			Handler:    _Transporter_CancelRebase_Handler,
+		},
 		{
 			MethodName: "AnnounceNewNode",
 			Handler:    _Transporter_AnnounceNewNode_Handler,
@@ -366,6 +954,38 @@ var Transporter_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "AnnounceRemoveNode",
 			Handler:    _Transporter_AnnounceRemoveNode_Handler,
 		},
+		{
+			MethodName: "DeleteKey",
+			Handler:    _Transporter_DeleteKey_Handler,
+		},
+		{
+			MethodName: "DeleteHash",
+			Handler:    _Transporter_DeleteHash_Handler,
+		},
+		{
+			MethodName: "GetScrubReport",
+			Handler:    _Transporter_GetScrubReport_Handler,
+		},
+		{
+			MethodName: "CollectGarbage",
+			Handler:    _Transporter_CollectGarbage_Handler,
+		},
+		{
+			MethodName: "GetPlacementReport",
+			Handler:    _Transporter_GetPlacementReport_Handler,
+		},
+		{
+			MethodName: "SetNodeState",
+			Handler:    _Transporter_SetNodeState_Handler,
+		},
+		{
+			MethodName: "Decommission",
+			Handler:    _Transporter_Decommission_Handler,
+		},
+		{
+			MethodName: "GetDecommissionStatus",
+			Handler:    _Transporter_GetDecommissionStatus_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -378,11 +998,21 @@ var Transporter_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Transporter_ReceiveChunks_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ListKeys",
+			Handler:       _Transporter_ListKeys_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "SyncNodes",
 			Handler:       _Transporter_SyncNodes_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "WatchRebase",
+			Handler:       _Transporter_WatchRebase_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "stash.proto",
 }
@@ -488,3 +1118,163 @@ var HealthChecker_ServiceDesc = grpc.ServiceDesc{
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "stash.proto",
 }
+
+const (
+	Gossip_Ping_FullMethodName    = "/Gossip/Ping"
+	Gossip_PingReq_FullMethodName = "/Gossip/PingReq"
+)
+
+// GossipClient is the client API for Gossip service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Gossip is used by nodes to detect failures of each other and to spread changes
+// of the membership (SWIM). Every protocol period a node pings a random member,
+// and if the member doesn't respond, asks several other members to ping it.
+// Members which don't respond to indirect pings either become suspected and are
+// declared dead unless they refute the suspicion in time. Membership updates
+// are piggybacked on all the messages.
+type GossipClient interface {
+	// Ping checks that the target node is alive.
+	Ping(ctx context.Context, in *GossipMessage, opts ...grpc.CallOption) (*GossipMessage, error)
+	// PingReq asks the target node to ping another member on behalf of the sender.
+	// An error is returned if the member doesn't respond.
+	PingReq(ctx context.Context, in *PingReqRequest, opts ...grpc.CallOption) (*GossipMessage, error)
+}
+
+type gossipClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGossipClient(cc grpc.ClientConnInterface) GossipClient {
+	return &gossipClient{cc}
+}
+
+func (c *gossipClient) Ping(ctx context.Context, in *GossipMessage, opts ...grpc.CallOption) (*GossipMessage, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GossipMessage)
+	err := c.cc.Invoke(ctx, Gossip_Ping_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gossipClient) PingReq(ctx context.Context, in *PingReqRequest, opts ...grpc.CallOption) (*GossipMessage, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GossipMessage)
+	err := c.cc.Invoke(ctx, Gossip_PingReq_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GossipServer is the server API for Gossip service.
+// All implementations must embed UnimplementedGossipServer
+// for forward compatibility.
+//
+// Gossip is used by nodes to detect failures of each other and to spread changes
+// of the membership (SWIM). Every protocol period a node pings a random member,
+// and if the member doesn't respond, asks several other members to ping it.
+// Members which don't respond to indirect pings either become suspected and are
+// declared dead unless they refute the suspicion in time. Membership updates
+// are piggybacked on all the messages.
+type GossipServer interface {
+	// Ping checks that the target node is alive.
+	Ping(context.Context, *GossipMessage) (*GossipMessage, error)
+	// PingReq asks the target node to ping another member on behalf of the sender.
+	// An error is returned if the member doesn't respond.
+	PingReq(context.Context, *PingReqRequest) (*GossipMessage, error)
+	mustEmbedUnimplementedGossipServer()
+}
+
+// UnimplementedGossipServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGossipServer struct{}
+
+func (UnimplementedGossipServer) Ping(context.Context, *GossipMessage) (*GossipMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
+}
+func (UnimplementedGossipServer) PingReq(context.Context, *PingReqRequest) (*GossipMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PingReq not implemented")
+}
+func (UnimplementedGossipServer) mustEmbedUnimplementedGossipServer() {}
+func (UnimplementedGossipServer) testEmbeddedByValue()                {}
+
+// UnsafeGossipServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GossipServer will
+// result in compilation errors.
+type UnsafeGossipServer interface {
+	mustEmbedUnimplementedGossipServer()
+}
+
+func RegisterGossipServer(s grpc.ServiceRegistrar, srv GossipServer) {
+	// If the following call pancis, it indicates UnimplementedGossipServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Gossip_ServiceDesc, srv)
+}
+
+func _Gossip_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GossipMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GossipServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gossip_Ping_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GossipServer).Ping(ctx, req.(*GossipMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gossip_PingReq_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PingReqRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GossipServer).PingReq(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gossip_PingReq_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GossipServer).PingReq(ctx, req.(*PingReqRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Gossip_ServiceDesc is the grpc.ServiceDesc for Gossip service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Gossip_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "Gossip",
+	HandlerType: (*GossipServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Ping",
+			Handler:    _Gossip_Ping_Handler,
+		},
+		{
+			MethodName: "PingReq",
+			Handler:    _Gossip_PingReq_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "stash.proto",
+}
@@ -24,19 +24,30 @@ func main() {
 
 	// Set up logging
 	logger := setupLogger(cfg.Env)
-	cfg.Validate(logger)
+	if err := cfg.Validate(logger); err != nil {
+		panic(err)
+	}
+
+	hashAlgorithm, err := cas.GetHashAlgorithm(cfg.Storage.HashAlgorithm)
+	if err != nil {
+		panic(err)
+	}
 
 	// Prepare options
 	storageOpts := cas.StorageOpts{
 		BaseDir:           cfg.Storage.Path,
-		PathFunc:          cas.DefaultTransformPathFunc,
+		HashAlgorithm:     hashAlgorithm,
 		Pack:              cas.ZLibPack,
 		Unpack:            cas.ZLibUnpack,
+		PackWriter:        cas.ZLibPackWriter,
+		UnpackReader:      cas.ZLibUnpackReader,
+		UnpackFrame:       cas.ZLibUnpackFrame,
 		ReplicationFactor: cfg.Storage.ReplicationFactor,
 	}
 	appOpts := &app.ApplicationOpts{
-		GRPCOpts:    cfg.GRPC,
-		StorageOpts: storageOpts,
+		GRPCOpts:      cfg.GRPC,
+		StorageOpts:   storageOpts,
+		StorageConfig: cfg.Storage,
 	}
 
 	application := app.NewApp(logger, appOpts)
@@ -62,8 +73,11 @@ func main() {
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 
-	// Wait for a termination signal
-	<-stop
+	// Wait for a termination signal or for the end of the decommission
+	select {
+	case <-stop:
+	case <-application.Decommissioned:
+	}
 
 	// Stop the GRPC server gracefully
 	application.GRPC.Stop()
@@ -5,23 +5,38 @@ import (
 	grpcapp "github.com/gfxv/go-stash/internal/app/grpc"
 	senderapp "github.com/gfxv/go-stash/internal/app/sender"
 	"github.com/gfxv/go-stash/internal/config"
+	"github.com/gfxv/go-stash/internal/grpc/gossiper"
 	"github.com/gfxv/go-stash/internal/sender"
 	"github.com/gfxv/go-stash/internal/services"
 	"github.com/gfxv/go-stash/internal/utils"
 	"github.com/gfxv/go-stash/pkg/cas"
 	"github.com/gfxv/go-stash/pkg/dht"
+	"github.com/gfxv/go-stash/pkg/gossip"
 	"log/slog"
-	"net"
+	"slices"
+	"strconv"
+	"strings"
 )
 
+// MEMBER_CHANGES_BUFFER is the number of membership changes which can be
+// buffered, the gossip protocol queues the rest without blocking
+const MEMBER_CHANGES_BUFFER = 64
+
+// NEWER_EPOCHS_BUFFER is the number of newer ring epochs seen on other nodes
+// which can be queued, the rest are dropped until the queued ones are handled
+const NEWER_EPOCHS_BUFFER = 8
+
 type ApplicationOpts struct {
-	GRPCOpts    config.GRPCConfig
-	StorageOpts cas.StorageOpts
+	GRPCOpts      config.GRPCConfig
+	StorageOpts   cas.StorageOpts
+	StorageConfig config.StorageConfig
 }
 
 type App struct {
 	GRPC   *grpcapp.App
 	Sender *senderapp.App
+	// Decommissioned is notified once the node is decommissioned and must be shut down
+	Decommissioned <-chan bool
 }
 
 func NewApp(logger *slog.Logger, opts *ApplicationOpts) *App {
@@ -30,53 +45,216 @@ func NewApp(logger *slog.Logger, opts *ApplicationOpts) *App {
 		panic(err)
 	}
 
-	ring, errs := loadRingFromConfig(&opts.GRPCOpts)
+	nodeID, err := dht.LoadNodeID(opts.StorageOpts.BaseDir)
+	if err != nil {
+		panic(err)
+	}
+	logger.Info("node identity loaded", slog.String("id", nodeID), slog.String("name", opts.GRPCOpts.Name))
+
+	ring, err := dht.NewPlacement(opts.GRPCOpts.Placement, opts.GRPCOpts.VirtualNodes)
+	if err != nil {
+		panic(err)
+	}
+	// saved nodes are loaded first, so the config can override their weights and zones
+	members, skipped, err := dht.LoadMembers(opts.StorageOpts.BaseDir)
+	if err != nil {
+		utils.HandleFatal(logger, "can't load saved nodes", err)
+	}
+	for _, err := range skipped {
+		logger.Warn("saved node is skipped", slog.Any("error", err.Error()))
+	}
+	ring.AddNode(members...)
+	logger.Info("saved nodes loaded", slog.Int("count", len(members)))
+
+	configured, errs := loadRingFromConfig(ring, &opts.GRPCOpts)
 	if len(errs) != 0 {
 		utils.HandleFatal(logger, "can't load nodes from config", errs...)
 	}
 
-	notifyRebase := make(chan bool)
+	initialState, err := dht.ParseNodeState(opts.GRPCOpts.InitialState)
+	if err != nil {
+		utils.HandleFatal(logger, "can't parse initial node state", err)
+	}
+	selfState, err := dht.LoadNodeState(opts.StorageOpts.BaseDir, initialState)
+	if err != nil {
+		utils.HandleFatal(logger, "can't load node state", err)
+	}
+	logger.Info("node state loaded", slog.String("state", selfState.String()))
+
+	epoch, err := dht.LoadRingEpoch(opts.StorageOpts.BaseDir)
+	if err != nil {
+		utils.HandleFatal(logger, "can't load ring epoch", err)
+	}
+	logger.Info("ring epoch loaded", slog.String("epoch", epoch.String()))
+
+	decommission, err := services.NewDecommissionService(opts.StorageOpts.BaseDir)
+	if err != nil {
+		utils.HandleFatal(logger, "can't load decommission progress", err)
+	}
+
+	// the sender is notified without blocking the requests, a single notification is kept
+	notifyRebase := make(chan bool, 1)
+	notifyDecommission := make(chan bool, 1)
+	decommissioned := make(chan bool, 1)
 	replicationChan := make(chan *cas.KeyHashPair)
+	memberChanges := make(chan gossip.Member, MEMBER_CHANGES_BUFFER)
+	newerEpochs := make(chan services.NewerEpoch, NEWER_EPOCHS_BUFFER)
 
 	storageService := services.NewStorageService(storage)
-	dhtService := services.NewDHTService(ring)
+	dhtService := services.NewDHTService(ring, opts.StorageOpts.BaseDir, epoch, newerEpochs)
+	if err := dhtService.SaveMembers(); err != nil {
+		logger.Error("can't save nodes", slog.Any("error", err.Error()))
+	}
+	rebase, err := services.NewRebaseService(storageService)
+	if err != nil {
+		utils.HandleFatal(logger, "can't load rebase job", err)
+	}
+
+	self := gossip.Member{
+		ID:      nodeID,
+		Name:    opts.GRPCOpts.Name,
+		Address: fmt.Sprintf(":%d", opts.GRPCOpts.Port),
+		Weight:  opts.GRPCOpts.Weight,
+		Zone:    opts.GRPCOpts.Zone,
+		// the lifecycle is spread to other nodes, which apply it to their DHT
+		Lifecycle: selfState.String(),
+	}
+	membership := gossip.New(self, gossiper.NewTransport(dhtService), gossip.Config{
+		ProbeTimeout:     opts.GRPCOpts.ProbeTimeout,
+		IndirectProbes:   opts.GRPCOpts.IndirectProbes,
+		SuspicionTimeout: opts.GRPCOpts.SuspicionTimeout,
+		Phi: gossip.PhiConfig{
+			Threshold:              opts.GRPCOpts.PhiThreshold,
+			MinStdDev:              opts.GRPCOpts.PhiMinStdDev,
+			AcceptablePause:        opts.GRPCOpts.PhiAcceptablePause,
+			FirstHeartbeatEstimate: opts.GRPCOpts.HealthCheckInterval,
+		},
+		Changes: memberChanges,
+	})
 
 	senderOpts := sender.SenderOpts{
 		Port:              opts.GRPCOpts.Port,
+		ID:                nodeID,
+		Name:              opts.GRPCOpts.Name,
+		Weight:            opts.GRPCOpts.Weight,
+		Zone:              opts.GRPCOpts.Zone,
 		CheckInterval:     opts.GRPCOpts.HealthCheckInterval,
 		SyncNode:          opts.GRPCOpts.SyncNode,
 		AnnounceNew:       opts.GRPCOpts.AnnounceNewNode,
 		ReplicationFactor: opts.StorageOpts.ReplicationFactor,
+		GCInterval:        opts.StorageConfig.GCInterval,
+		GCGracePeriod:     opts.StorageConfig.GCGracePeriod,
+		ScrubInterval:     opts.StorageConfig.ScrubInterval,
+		ScrubRate:         opts.StorageConfig.ScrubRate,
+		MigrateHashes:     opts.StorageConfig.MigrateHashes,
+		RememberedNodes:   rememberedNodes(members, configured),
+		Gossip:            membership,
+		Decommission:      decommission,
+		Rebase:            rebase,
 		Logger:            logger,
 		NotifyRebase:      notifyRebase,
 		ReplicationChan:   replicationChan,
+		MemberChanges:     memberChanges,
+		NewerEpochs:       newerEpochs,
+
+		NotifyDecommission: notifyDecommission,
+		Decommissioned:     decommissioned,
 	}
 	senderApp := senderapp.New(&senderOpts, storageService, dhtService)
 	grpcOpts := grpcapp.GRPCOpts{
-		Port:            opts.GRPCOpts.Port,
-		Logger:          logger,
-		NotifyRebase:    notifyRebase,
-		ReplicationChan: replicationChan,
+		Port:              opts.GRPCOpts.Port,
+		NodeID:            nodeID,
+		NodeName:          opts.GRPCOpts.Name,
+		Weight:            opts.GRPCOpts.Weight,
+		Zone:              opts.GRPCOpts.Zone,
+		ReplicationFactor: opts.StorageOpts.ReplicationFactor,
+		GCGracePeriod:     opts.StorageConfig.GCGracePeriod,
+		Logger:            logger,
+		Gossip:            membership,
+		Decommission:      decommission,
+		Rebase:            rebase,
+		NotifyRebase:      notifyRebase,
+		ReplicationChan:   replicationChan,
+
+		NotifyDecommission: notifyDecommission,
 	}
 	grpcApp := grpcapp.New(&grpcOpts, storageService, dhtService)
 
 	return &App{
-		GRPC:   grpcApp,
-		Sender: senderApp,
+		GRPC:           grpcApp,
+		Sender:         senderApp,
+		Decommissioned: decommissioned,
 	}
 }
 
-func loadRingFromConfig(cfg *config.GRPCConfig) (*dht.HashRing, []error) {
-	nodes := cfg.Nodes
-	ring := dht.NewHashRing()
+// loadRingFromConfig adds the nodes from the config to the ring and returns them.
+// Weights and zones of the nodes which are already in the ring (e.g. loaded
+// from the saved membership) are replaced with the ones from the config.
+func loadRingFromConfig(ring dht.Placement, cfg *config.GRPCConfig) ([]*dht.Node, []error) {
+	nodes := make([]*dht.Node, 0)
 	errorNodes := make([]error, 0)
 
-	for _, n := range nodes {
-		addr, err := net.ResolveTCPAddr("tcp", n)
+	for _, n := range cfg.Nodes {
+		address, weight, zone, err := parseNodeEntry(n)
+		if err != nil {
+			errorNodes = append(errorNodes, err)
+			continue
+		}
+		node, err := dht.ResolveNode(address, weight, zone)
 		if err != nil {
 			errorNodes = append(errorNodes, fmt.Errorf("error resolving node address: %s", err.Error()))
+			continue
+		}
+		nodes = append(nodes, node)
+
+		stored := findNodeByAddr(ring, node.Addr.String())
+		if stored != nil {
+			if stored.Weight == node.Weight && stored.Zone == node.Zone {
+				continue
+			}
+			node.ID, node.Name, node.State = stored.ID, stored.Name, stored.State
+			ring.RemoveNode(stored)
 		}
-		ring.AddNode(dht.NewNode(addr))
+		ring.AddNode(node)
+	}
+	return nodes, errorNodes
+}
+
+func findNodeByAddr(ring dht.Placement, address string) *dht.Node {
+	for _, node := range ring.GetNodes() {
+		if node.Addr.String() == address {
+			return node
+		}
+	}
+	return nil
+}
+
+// rememberedNodes returns the saved nodes which are not in the config
+func rememberedNodes(members []*dht.Node, configured []*dht.Node) []*dht.Node {
+	remembered := make([]*dht.Node, 0)
+	for _, member := range members {
+		inConfig := slices.ContainsFunc(configured, func(node *dht.Node) bool {
+			return node.Addr.String() == member.Addr.String()
+		})
+		if !inConfig {
+			remembered = append(remembered, member)
+		}
+	}
+	return remembered
+}
+
+// parseNodeEntry splits an entry of the nodes list into the address, the weight
+// and the zone of the node, e.g. "10.0.0.1:5555=2@rack-1".
+// Entries without weight get dht.DEFAULT_WEIGHT, entries without zone get empty zone
+func parseNodeEntry(entry string) (string, float64, string, error) {
+	entry, zone, _ := strings.Cut(entry, "@")
+	address, rawWeight, ok := strings.Cut(entry, "=")
+	if !ok {
+		return address, dht.DEFAULT_WEIGHT, zone, nil
+	}
+	weight, err := strconv.ParseFloat(rawWeight, 64)
+	if err != nil || weight <= 0 {
+		return "", 0, "", fmt.Errorf("invalid weight of node '%s': %s", address, rawWeight)
 	}
-	return ring, errorNodes
+	return address, weight, zone, nil
 }
@@ -4,13 +4,16 @@ import (
 	"context"
 	"fmt"
 	"github.com/gfxv/go-stash/pkg/cas"
+	"github.com/gfxv/go-stash/pkg/gossip"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"log"
 	"log/slog"
 	"net"
+	"time"
 
+	"github.com/gfxv/go-stash/internal/grpc/gossiper"
 	"github.com/gfxv/go-stash/internal/grpc/healthchecker"
 	"github.com/gfxv/go-stash/internal/grpc/transporter"
 	"github.com/gfxv/go-stash/internal/services"
@@ -20,11 +23,21 @@ import (
 )
 
 type GRPCOpts struct {
-	Port   int
-	Logger *slog.Logger
-
-	NotifyRebase    chan<- bool
-	ReplicationChan chan<- *cas.KeyHashPair
+	Port              int
+	NodeID            string
+	NodeName          string
+	Weight            float64
+	Zone              string
+	ReplicationFactor int
+	GCGracePeriod     time.Duration
+	Logger            *slog.Logger
+	Gossip            *gossip.Gossip
+	Decommission      *services.DecommissionService
+	Rebase            *services.RebaseService
+
+	NotifyRebase       chan<- bool
+	NotifyDecommission chan<- bool
+	ReplicationChan    chan<- *cas.KeyHashPair
 }
 
 type App struct {
@@ -55,7 +68,23 @@ func New(opts *GRPCOpts, storage *services.StorageService, dht *services.DHTServ
 	))
 
 	healthchecker.Register(server)
-	transporter.Register(server, storage, dht, opts.NotifyRebase, opts.ReplicationChan)
+	gossiper.Register(server, opts.Gossip, dht)
+	transporter.Register(server, storage, dht, &transporter.TransporterOpts{
+		Port:              opts.Port,
+		NodeID:            opts.NodeID,
+		NodeName:          opts.NodeName,
+		Weight:            opts.Weight,
+		Zone:              opts.Zone,
+		ReplicationFactor: opts.ReplicationFactor,
+		GCGracePeriod:     opts.GCGracePeriod,
+		Gossip:            opts.Gossip,
+		Decommission:      opts.Decommission,
+		Rebase:            opts.Rebase,
+		NotifyRebase:      opts.NotifyRebase,
+		ReplicationChan:   opts.ReplicationChan,
+
+		NotifyDecommission: opts.NotifyDecommission,
+	})
 
 	reflection.Register(server)
 
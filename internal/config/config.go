@@ -2,6 +2,7 @@ package config
 
 import (
 	"flag"
+	"fmt"
 	"log/slog"
 	"os"
 	"time"
@@ -49,12 +50,52 @@ type GRPCConfig struct {
 	// Can be set via the `STASH_TIMEOUT` environment variable.
 	Timeout time.Duration `yaml:"timeout" env:"STASH_TIMEOUT" env-default:"10s"`
 
-	// HealthCheckInterval sets the interval for health check pings
-	// to be sent to the nodes in the system. This helps ensure that nodes
-	// are responsive and can handle requests.
+	// HealthCheckInterval sets the gossip protocol period. Every period the node
+	// pings a single random node, so every node is pinged by about one node per period.
+	// Failures are detected within a few periods plus the suspicion timeout.
 	// The default interval is 10 seconds
 	// Can be set via the `STASH_HEALTH_CHECK_INTERVAL` environment variable.
-	HealthCheckInterval time.Duration `yaml:"health-check-interval" env:"STASH_HEALTH_CHECK_INTERVAL" env-default:"10s""`
+	HealthCheckInterval time.Duration `yaml:"health-check-interval" env:"STASH_HEALTH_CHECK_INTERVAL" env-default:"10s"`
+
+	// ProbeTimeout is the time to wait for a reply to a gossip ping before
+	// asking other nodes to ping the node indirectly.
+	// The default value is 1 second
+	// Can be set via the `STASH_PROBE_TIMEOUT` environment variable.
+	ProbeTimeout time.Duration `yaml:"probe-timeout" env:"STASH_PROBE_TIMEOUT" env-default:"1s"`
+
+	// IndirectProbes is the number of nodes asked to ping the node which
+	// didn't reply to a gossip ping, so a broken link between two nodes
+	// doesn't make one of them suspect the other.
+	// The default value is 3
+	// Can be set via the `STASH_INDIRECT_PROBES` environment variable.
+	IndirectProbes int `yaml:"indirect-probes" env:"STASH_INDIRECT_PROBES" env-default:"3"`
+
+	// SuspicionTimeout is the time a suspected node has to refute the suspicion
+	// before it's declared dead. Suspected nodes are still considered available.
+	// The default value is 30 seconds
+	// Can be set via the `STASH_SUSPICION_TIMEOUT` environment variable.
+	SuspicionTimeout time.Duration `yaml:"suspicion-timeout" env:"STASH_SUSPICION_TIMEOUT" env-default:"30s"`
+
+	// PhiThreshold is the suspicion level (phi) of the phi accrual failure detector,
+	// above which a node which doesn't reply to pings becomes suspected. The detector
+	// of every node learns the intervals between messages from the node, so a single
+	// slow reply of a node which was heard from recently doesn't make it suspected.
+	// Phi of 8 means the chance the node is alive but slow is about 10^-8.
+	// The default value is 8
+	// Can be set via the `STASH_PHI_THRESHOLD` environment variable.
+	PhiThreshold float64 `yaml:"phi-threshold" env:"STASH_PHI_THRESHOLD" env-default:"8"`
+
+	// PhiMinStdDev is the lower bound of the standard deviation of intervals between
+	// messages from a node, it prevents suspecting nodes with very regular messages too early.
+	// The default value is 500 milliseconds
+	// Can be set via the `STASH_PHI_MIN_STD_DEV` environment variable.
+	PhiMinStdDev time.Duration `yaml:"phi-min-std-dev" env:"STASH_PHI_MIN_STD_DEV" env-default:"500ms"`
+
+	// PhiAcceptablePause is the pause in messages from a node (e.g. a GC pause)
+	// which raises its suspicion level only slightly.
+	// The default value is 0
+	// Can be set via the `STASH_PHI_ACCEPTABLE_PAUSE` environment variable.
+	PhiAcceptablePause time.Duration `yaml:"phi-acceptable-pause" env:"STASH_PHI_ACCEPTABLE_PAUSE" env-default:"0s"`
 
 	// SyncNode identifies the specific node that should be synchronized with.
 	// This field can be set through the `STASH_SYNC_NODE` environment variable
@@ -62,9 +103,58 @@ type GRPCConfig struct {
 	SyncNode string `yaml:"sync-node" env:"STASH_SYNC_NODE"`
 
 	// Nodes is a list of nodes that the gRPC server can communicate with.
+	// Weight of a node can be appended to its address after `=`, e.g. `10.0.0.1:5555=2`,
+	// nodes without weight get weight 1. Zone of a node can be appended after `@`,
+	// e.g. `10.0.0.1:5555@rack-1` or `10.0.0.1:5555=2@rack-1`.
 	// This field can be populated with multiple values passed to env. separated by a semicolon (`;`)
 	Nodes []string `yaml:"nodes" env:"STASH_NODES" env-separator:";"`
 
+	// Name is an optional human-readable name of this node, which is announced
+	// to other nodes along with its address and ID. The ID of the node is generated
+	// on the first start and stored in the storage directory.
+	// Can be set via the `STASH_NODE_NAME` environment variable.
+	Name string `yaml:"name" env:"STASH_NODE_NAME"`
+
+	// Weight is the relative capacity of this node, e.g. size of its disk in terabytes.
+	// The node gets a share of keys proportional to its weight, so a node with
+	// weight 4 gets about twice the keys of a node with weight 2.
+	// The weight is announced to other nodes along with the address of the node.
+	// The default value is 1
+	// Can be set via the `STASH_NODE_WEIGHT` environment variable.
+	Weight float64 `yaml:"weight" env:"STASH_NODE_WEIGHT" env-default:"1"`
+
+	// Zone is the failure domain of this node, e.g. a rack or a data center.
+	// Copies of a key are spread across distinct zones whenever possible.
+	// The zone is announced to other nodes along with the address of the node.
+	// Nodes without zone are treated as separate failure domains.
+	// Can be set via the `STASH_ZONE` environment variable.
+	Zone string `yaml:"zone" env:"STASH_ZONE"`
+
+	// InitialState is the lifecycle state of this node on its first start.
+	// A node joining a cluster which already stores data should start as `joining`,
+	// so it doesn't serve reads until it receives the data of its keys, and then
+	// it's moved to the `active` state with the SetNodeState RPC.
+	// The state is kept across restarts, so the value is ignored afterwards.
+	// Acceptable values: active, joining. The default value is `active`
+	// Can be set via the `STASH_INITIAL_STATE` environment variable.
+	InitialState string `yaml:"initial-state" env:"STASH_INITIAL_STATE" env-default:"active"`
+
+	// Placement is the strategy used to decide which node stores a key.
+	// Acceptable values: ring (consistent hashing with virtual nodes),
+	// rendezvous (highest random weight hashing), jump (jump consistent hash).
+	// All nodes of the cluster must use the same value.
+	// The default value is `ring`
+	// Can be set via the `STASH_PLACEMENT` environment variable.
+	Placement string `yaml:"placement" env:"STASH_PLACEMENT" env-default:"ring"`
+
+	// VirtualNodes is the number of points every node takes on the hash ring.
+	// More virtual nodes spread keys between nodes more evenly.
+	// Used only by the `ring` placement.
+	// All nodes of the cluster must use the same value.
+	// The default value is 128
+	// Can be set via the `STASH_VIRTUAL_NODES` environment variable.
+	VirtualNodes int `yaml:"virtual-nodes" env:"STASH_VIRTUAL_NODES" env-default:"128"`
+
 	// AnnounceNewNode is a boolean flag that indicates whether the server should
 	// announce the addition of a new node to the system.
 	// If set to true, the server will broadcast the new node's presence to other nodes in the network.
@@ -101,6 +191,44 @@ type StorageConfig struct {
 	// The default is `false`
 	// Can be configured using the `STASH_ALLOW_SERVER_SIDE_COMPRESSION` environment variable.
 	AllowServerSideCompression bool `yaml:"allow-server-side-compression" env:"STASH_ALLOW_SERVER_SIDE_COMPRESSION" env-default:"false"` // TODO: <-- ???
+
+	// GCInterval sets the interval between garbage collection runs, which remove
+	// files no longer referenced by any key. Zero value disables garbage collection.
+	// The default interval is 1 hour
+	// Can be set via the `STASH_GC_INTERVAL` environment variable.
+	GCInterval time.Duration `yaml:"gc-interval" env:"STASH_GC_INTERVAL" env-default:"1h"`
+
+	// GCGracePeriod protects recently written files from the garbage collector,
+	// since they may be not yet referenced by their keys.
+	// The default grace period is 1 hour
+	// Can be set via the `STASH_GC_GRACE_PERIOD` environment variable.
+	GCGracePeriod time.Duration `yaml:"gc-grace-period" env:"STASH_GC_GRACE_PERIOD" env-default:"1h"`
+
+	// ScrubInterval sets the interval between scrubber runs, which check that
+	// the stored files match their hashes. Zero value disables the scrubber.
+	// The default interval is 24 hours
+	// Can be set via the `STASH_SCRUB_INTERVAL` environment variable.
+	ScrubInterval time.Duration `yaml:"scrub-interval" env:"STASH_SCRUB_INTERVAL" env-default:"24h"`
+
+	// ScrubRate limits the rate (in bytes per second) at which the scrubber
+	// reads the stored files. Zero value means no limit.
+	// The default rate is 8 MiB per second
+	// Can be set via the `STASH_SCRUB_RATE` environment variable.
+	ScrubRate int64 `yaml:"scrub-rate" env:"STASH_SCRUB_RATE" env-default:"8388608"`
+
+	// HashAlgorithm is the tag of the hash algorithm used to address newly stored files.
+	// Acceptable values: sha1, sha256, sha512 and tags of algorithms registered
+	// through cas.RegisterHashAlgorithm. Files hashed with other algorithms are still available.
+	// The default algorithm is `sha256`
+	// Can be set via the `STASH_HASH_ALGORITHM` environment variable.
+	HashAlgorithm string `yaml:"hash-algorithm" env:"STASH_HASH_ALGORITHM" env-default:"sha256"`
+
+	// MigrateHashes is a boolean flag that determines whether files hashed with
+	// other algorithms (including legacy SHA-1 hashes without algorithm tag)
+	// are rehashed with HashAlgorithm once the node is started.
+	// The default is `false`
+	// Can be set via the `STASH_MIGRATE_HASHES` environment variable.
+	MigrateHashes bool `yaml:"migrate-hashes" env:"STASH_MIGRATE_HASHES" env-default:"false"`
 }
 
 func MustLoad() *Config {
@@ -132,8 +260,17 @@ func parseConfigPath() string {
 	return *configPath
 }
 
-func (c *Config) Validate(logger *slog.Logger) {
+// Validate warns about settings which are replaced with defaults
+// and returns an error if a setting is invalid
+func (c *Config) Validate(logger *slog.Logger) error {
 	if len(c.GRPC.Nodes) == 0 && c.GRPC.SyncNode == "" {
 		logger.Warn("Nodes and SyncNode are not configured")
 	}
+	if c.GRPC.Weight <= 0 {
+		logger.Warn("Weight is not positive, default weight is used", slog.Float64("weight", c.GRPC.Weight))
+	}
+	if c.GRPC.InitialState != "active" && c.GRPC.InitialState != "joining" {
+		return fmt.Errorf("config: initial-state must be 'active' or 'joining', got '%s'", c.GRPC.InitialState)
+	}
+	return nil
 }
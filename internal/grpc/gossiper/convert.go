@@ -0,0 +1,88 @@
+package gossiper
+
+import (
+	"net"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/pkg/dht"
+	"github.com/gfxv/go-stash/pkg/gossip"
+)
+
+func toProto(msg gossip.Message) *gen.GossipMessage {
+	updates := make([]*gen.Member, 0, len(msg.Updates))
+	for _, update := range msg.Updates {
+		updates = append(updates, memberToProto(update))
+	}
+	return &gen.GossipMessage{From: memberToProto(msg.From), Updates: updates}
+}
+
+func fromProto(msg *gen.GossipMessage) gossip.Message {
+	updates := make([]gossip.Member, 0, len(msg.GetUpdates()))
+	for _, update := range msg.GetUpdates() {
+		updates = append(updates, memberFromProto(update))
+	}
+	return gossip.Message{From: memberFromProto(msg.GetFrom()), Updates: updates}
+}
+
+func memberToProto(m gossip.Member) *gen.Member {
+	// lifecycles are set only from dht node states, so they are always known
+	state, _ := dht.ParseNodeState(m.Lifecycle)
+	return &gen.Member{
+		Node: &gen.NodeInfo{
+			Address: m.Address,
+			Alive:   m.State != gossip.StateDead,
+			Weight:  m.Weight,
+			Zone:    m.Zone,
+			Id:      m.ID,
+			Name:    m.Name,
+			State:   gen.NodeInfo_State(state),
+		},
+		State:       gen.Member_State(m.State),
+		Incarnation: m.Incarnation,
+	}
+}
+
+func memberFromProto(m *gen.Member) gossip.Member {
+	node := m.GetNode()
+	return gossip.Member{
+		ID:          node.GetId(),
+		Name:        node.GetName(),
+		Address:     node.GetAddress(),
+		Weight:      node.GetWeight(),
+		Zone:        node.GetZone(),
+		Lifecycle:   dht.NodeState(node.GetState()).String(),
+		State:       gossip.State(m.GetState()),
+		Incarnation: m.GetIncarnation(),
+	}
+}
+
+// fixAddress replaces the host of the sender address with the given host,
+// if the sender doesn't know its host (e.g. it's listening on ":5555").
+// Updates about the sender are fixed as well.
+func fixAddress(msg *gossip.Message, host string) {
+	fixed, ok := withHost(msg.From.Address, host)
+	if !ok {
+		return
+	}
+
+	key := msg.From.Key()
+	msg.From.Address = fixed
+	for i := range msg.Updates {
+		if msg.Updates[i].Key() == key {
+			msg.Updates[i].Address = fixed
+		}
+	}
+}
+
+// withHost returns the address with the given host
+// if the host of the address is empty or unspecified
+func withHost(address string, host string) (string, bool) {
+	addrHost, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", false
+	}
+	if ip := net.ParseIP(addrHost); addrHost != "" && (ip == nil || !ip.IsUnspecified()) {
+		return "", false
+	}
+	return net.JoinHostPort(host, port), true
+}
@@ -0,0 +1,53 @@
+package gossiper
+
+import (
+	"testing"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/pkg/gossip"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMessageRoundTrip(t *testing.T) {
+	msg := gossip.Message{
+		From: gossip.Member{
+			ID: "node-0", Name: "first", Address: "10.0.0.1:5555", Weight: 2, Zone: "rack-1",
+			Lifecycle: "active", State: gossip.StateAlive, Incarnation: 3,
+		},
+		Updates: []gossip.Member{
+			{ID: "node-1", Address: "10.0.0.2:5555", Weight: 1, Lifecycle: "draining", State: gossip.StateSuspect, Incarnation: 1},
+			{ID: "node-2", Address: "10.0.0.3:5555", Weight: 0.5, Zone: "rack-2", Lifecycle: "joining", State: gossip.StateDead},
+			{Address: "10.0.0.4:5555", Weight: 1, Lifecycle: "leaving", State: gossip.StateAlive},
+		},
+	}
+
+	// the message is sent over the wire
+	data, err := proto.Marshal(toProto(msg))
+	assert.NoError(t, err)
+	received := &gen.GossipMessage{}
+	assert.NoError(t, proto.Unmarshal(data, received))
+
+	assert.Equal(t, msg, fromProto(received))
+	assert.False(t, received.GetUpdates()[1].GetNode().GetAlive(), "dead members are not alive")
+	assert.True(t, received.GetUpdates()[0].GetNode().GetAlive(), "suspected members are alive")
+}
+
+func TestFixAddress(t *testing.T) {
+	msg := gossip.Message{
+		From: gossip.Member{ID: "node-0", Address: ":5555"},
+		Updates: []gossip.Member{
+			{ID: "node-0", Address: ":5555"},
+			{ID: "node-1", Address: ":5556"},
+		},
+	}
+	fixAddress(&msg, "10.0.0.1")
+	assert.Equal(t, "10.0.0.1:5555", msg.From.Address)
+	assert.Equal(t, "10.0.0.1:5555", msg.Updates[0].Address)
+	assert.Equal(t, ":5556", msg.Updates[1].Address, "only updates about the sender are fixed")
+
+	// known hosts are kept
+	msg.From.Address = "10.0.0.2:5555"
+	fixAddress(&msg, "10.0.0.1")
+	assert.Equal(t, "10.0.0.2:5555", msg.From.Address)
+}
@@ -0,0 +1,32 @@
+package gossiper
+
+import (
+	"net"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/internal/services"
+	"github.com/gfxv/go-stash/pkg/dht"
+	"github.com/gfxv/go-stash/pkg/gossip"
+)
+
+// withEpoch sets the ring epoch of the current node on the sender of the message.
+// Gossip messages are always sent by the current node itself
+func withEpoch(msg *gen.GossipMessage, dhtService *services.DHTService) *gen.GossipMessage {
+	epoch := dhtService.Epoch()
+	msg.From.Node.Epoch, msg.From.Node.EpochDigest = epoch.Version, epoch.Digest
+	return msg
+}
+
+// observeEpoch reports the ring epoch of the sender of the received message,
+// so the current node pulls the nodes of the sender if the epoch is newer.
+// from is the sender of the message with the fixed address
+func observeEpoch(dhtService *services.DHTService, msg *gen.GossipMessage, from gossip.Member) {
+	addr, err := net.ResolveTCPAddr("tcp", from.Address)
+	if err != nil {
+		return
+	}
+	node := dht.NewNode(addr)
+	node.ID, node.Name = from.ID, from.Name
+	info := msg.GetFrom().GetNode()
+	dhtService.ObserveEpoch(node, dht.Epoch{Version: info.GetEpoch(), Digest: info.GetEpochDigest()})
+}
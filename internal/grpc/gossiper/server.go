@@ -0,0 +1,58 @@
+package gossiper
+
+import (
+	"context"
+	"net"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/internal/services"
+	"github.com/gfxv/go-stash/pkg/gossip"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type serverAPI struct {
+	gen.UnimplementedGossipServer
+	gossip *gossip.Gossip
+	// dhtService carries the ring epoch in the messages
+	dhtService *services.DHTService
+}
+
+func Register(gRPC *grpc.Server, g *gossip.Gossip, dhtService *services.DHTService) {
+	gen.RegisterGossipServer(gRPC, &serverAPI{gossip: g, dhtService: dhtService})
+}
+
+// Ping ...
+func (s *serverAPI) Ping(ctx context.Context, msg *gen.GossipMessage) (*gen.GossipMessage, error) {
+	received := fromProto(msg)
+	fixSenderAddress(ctx, &received)
+	observeEpoch(s.dhtService, msg, received.From)
+	return withEpoch(toProto(s.gossip.HandlePing(received)), s.dhtService), nil
+}
+
+// PingReq ...
+func (s *serverAPI) PingReq(ctx context.Context, req *gen.PingReqRequest) (*gen.GossipMessage, error) {
+	received := fromProto(req.GetMessage())
+	fixSenderAddress(ctx, &received)
+	observeEpoch(s.dhtService, req.GetMessage(), received.From)
+	reply, err := s.gossip.HandlePingReq(ctx, req.GetTarget(), received)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "%v", err)
+	}
+	return withEpoch(toProto(reply), s.dhtService), nil
+}
+
+// fixSenderAddress fixes the address of the sender with the host the message came from
+func fixSenderAddress(ctx context.Context, msg *gossip.Message) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return
+	}
+	fixAddress(msg, host)
+}
@@ -0,0 +1,98 @@
+package gossiper
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/internal/services"
+	"github.com/gfxv/go-stash/pkg/gossip"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Transport delivers gossip messages over gRPC.
+// Connections to members are kept open and reused between protocol periods.
+// Messages carry the ring epoch of the current node, and newer epochs
+// of the replying members are reported to dhtService.
+type Transport struct {
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+
+	dhtService *services.DHTService
+}
+
+// NewTransport creates Transport without open connections
+func NewTransport(dhtService *services.DHTService) *Transport {
+	return &Transport{conns: make(map[string]*grpc.ClientConn), dhtService: dhtService}
+}
+
+// Ping implements gossip.Transport
+func (t *Transport) Ping(ctx context.Context, address string, msg gossip.Message) (gossip.Message, error) {
+	client, err := t.client(address)
+	if err != nil {
+		return gossip.Message{}, err
+	}
+	reply, err := client.Ping(ctx, withEpoch(toProto(msg), t.dhtService))
+	if err != nil {
+		return gossip.Message{}, err
+	}
+	received := withTargetHost(fromProto(reply), address)
+	observeEpoch(t.dhtService, reply, received.From)
+	return received, nil
+}
+
+// PingReq implements gossip.Transport
+func (t *Transport) PingReq(ctx context.Context, via string, target string, msg gossip.Message) (gossip.Message, error) {
+	client, err := t.client(via)
+	if err != nil {
+		return gossip.Message{}, err
+	}
+	reply, err := client.PingReq(ctx, &gen.PingReqRequest{Target: target, Message: withEpoch(toProto(msg), t.dhtService)})
+	if err != nil {
+		return gossip.Message{}, err
+	}
+	received := withTargetHost(fromProto(reply), via)
+	observeEpoch(t.dhtService, reply, received.From)
+	return received, nil
+}
+
+// Close closes all the open connections
+func (t *Transport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for address, conn := range t.conns {
+		conn.Close()
+		delete(t.conns, address)
+	}
+	return nil
+}
+
+func (t *Transport) client(address string) (gen.GossipClient, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	conn, ok := t.conns[address]
+	if !ok {
+		var err error
+		conn, err = grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, err
+		}
+		t.conns[address] = conn
+	}
+	return gen.NewGossipClient(conn), nil
+}
+
+// withTargetHost fixes the address of the replying member
+// with the host of the address the message was sent to
+func withTargetHost(msg gossip.Message, address string) gossip.Message {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil || host == "" {
+		return msg
+	}
+	fixAddress(&msg, host)
+	return msg
+}
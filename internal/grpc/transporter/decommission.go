@@ -0,0 +1,62 @@
+package transporter
+
+import (
+	"context"
+	"errors"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/internal/services"
+	"github.com/gfxv/go-stash/pkg/dht"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+var decommissionStates = map[services.DecommissionState]gen.DecommissionStatus_State{
+	services.DecommissionNotStarted: gen.DecommissionStatus_NOT_STARTED,
+	services.DecommissionRunning:    gen.DecommissionStatus_RUNNING,
+	services.DecommissionFailed:     gen.DecommissionStatus_FAILED,
+	services.DecommissionDone:       gen.DecommissionStatus_DONE,
+}
+
+// Decommission moves the current node to the draining state and starts
+// (or resumes) moving its data to other nodes, which is done by the sender
+func (s *serverAPI) Decommission(ctx context.Context, _ *emptypb.Empty) (*gen.DecommissionStatus, error) {
+	if s.selfState() != dht.NodeDraining {
+		if _, err := s.setSelfState(ctx, dht.NodeDraining); err != nil {
+			return nil, err
+		}
+	}
+
+	err := s.decommission.Start()
+	if errors.Is(err, services.ErrDecommissionRunning) || errors.Is(err, services.ErrDecommissionDone) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "can't start decommission: %v", err)
+	}
+	select {
+	case s.notifyDecommission <- true:
+	default: // the sender is notified already
+	}
+
+	return makeDecommissionStatus(s.decommission.Progress()), nil
+}
+
+// GetDecommissionStatus returns the progress of the decommission of the current node
+func (s *serverAPI) GetDecommissionStatus(ctx context.Context, _ *emptypb.Empty) (*gen.DecommissionStatus, error) {
+	return makeDecommissionStatus(s.decommission.Progress()), nil
+}
+
+func makeDecommissionStatus(progress services.DecommissionProgress) *gen.DecommissionStatus {
+	return &gen.DecommissionStatus{
+		State:         decommissionStates[progress.State],
+		StartedAt:     makeTimestamp(progress.StartedAt),
+		FinishedAt:    makeTimestamp(progress.FinishedAt),
+		LastKey:       progress.LastKey,
+		MovedKeys:     uint64(progress.MovedKeys),
+		VerifiedFiles: uint64(progress.VerifiedFiles),
+		SentBytes:     uint64(progress.SentBytes),
+		Error:         progress.Error,
+	}
+}
@@ -0,0 +1,146 @@
+package transporter
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/pkg/dht"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DeleteKey removes files stored under the key from the node
+// responsible for it and from all of its replicas.
+// Files are unlinked from the key and removed later by the garbage collector
+func (s *serverAPI) DeleteKey(
+	ctx context.Context,
+	deleteRequest *gen.DeleteKeyRequest,
+) (*gen.DeleteResponse, error) {
+	key := deleteRequest.GetKey()
+	if len(key) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "empty key")
+	}
+
+	if deleteRequest.GetForwarded() {
+		return &gen.DeleteResponse{
+			Replicas: []*gen.ReplicaStatus{s.deleteKeyLocally(key)},
+		}, nil
+	}
+
+	forwarded := &gen.DeleteKeyRequest{Key: key, Forwarded: true}
+	return s.routeDelete(ctx, key,
+		func() *gen.ReplicaStatus { return s.deleteKeyLocally(key) },
+		func(client gen.TransporterClient) (*gen.DeleteResponse, error) {
+			return client.DeleteKey(ctx, forwarded)
+		},
+	)
+}
+
+// DeleteHash unlinks the file with provided hash from the key on the node
+// responsible for the key and on all of its replicas
+func (s *serverAPI) DeleteHash(
+	ctx context.Context,
+	deleteRequest *gen.DeleteHashRequest,
+) (*gen.DeleteResponse, error) {
+	key := deleteRequest.GetKey()
+	if len(key) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "empty key")
+	}
+	hash := deleteRequest.GetHash()
+	if len(hash) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "empty hash")
+	}
+
+	if deleteRequest.GetForwarded() {
+		return &gen.DeleteResponse{
+			Replicas: []*gen.ReplicaStatus{s.deleteHashLocally(key, hash)},
+		}, nil
+	}
+
+	forwarded := &gen.DeleteHashRequest{Key: key, Hash: hash, Forwarded: true}
+	return s.routeDelete(ctx, key,
+		func() *gen.ReplicaStatus { return s.deleteHashLocally(key, hash) },
+		func(client gen.TransporterClient) (*gen.DeleteResponse, error) {
+			return client.DeleteHash(ctx, forwarded)
+		},
+	)
+}
+
+// routeDelete runs the deletion on every node storing the key (including
+// replicas and nodes keeping the key for joining nodes) concurrently. Deletion on the current node is done by local,
+// other nodes receive a forwarded request sent by remote.
+// Returns status of the deletion for every node.
+func (s *serverAPI) routeDelete(
+	ctx context.Context,
+	key string,
+	local func() *gen.ReplicaStatus,
+	remote func(client gen.TransporterClient) (*gen.DeleteResponse, error),
+) (*gen.DeleteResponse, error) {
+	nodes, err := s.dhtService.GetStoringNodes(key, s.replicationFactor)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	statuses := forEachNode(nodes, func(node *dht.Node) *gen.ReplicaStatus {
+		if s.isSelf(node) {
+			return local()
+		}
+
+		var replicaStatus *gen.ReplicaStatus
+		err := callNode(node, func(client gen.TransporterClient) error {
+			response, err := remote(client)
+			if err != nil {
+				return err
+			}
+			if len(response.GetReplicas()) != 1 {
+				return errors.New("unexpected response from node")
+			}
+			replicaStatus = response.GetReplicas()[0]
+			return nil
+		})
+		if err != nil {
+			return failedStatus(err)
+		}
+		return replicaStatus
+	})
+
+	for i, node := range nodes {
+		statuses[i].Address = node.Addr.String()
+	}
+	return &gen.DeleteResponse{Replicas: statuses}, nil
+}
+
+func (s *serverAPI) deleteKeyLocally(key string) *gen.ReplicaStatus {
+	hashes, err := s.storageService.GetHashesByKey(key)
+	if err != nil {
+		return failedStatus(err)
+	}
+	if len(hashes) == 0 {
+		return &gen.ReplicaStatus{Address: s.selfAddr, Status: gen.ReplicaStatus_NOT_FOUND}
+	}
+
+	if err := s.storageService.RemoveByKey(key); err != nil {
+		return failedStatus(err)
+	}
+	return &gen.ReplicaStatus{Address: s.selfAddr, Status: gen.ReplicaStatus_DELETED}
+}
+
+func (s *serverAPI) deleteHashLocally(key, hash string) *gen.ReplicaStatus {
+	err := s.storageService.Unlink(key, hash)
+	if errors.Is(err, os.ErrNotExist) {
+		return &gen.ReplicaStatus{Address: s.selfAddr, Status: gen.ReplicaStatus_NOT_FOUND}
+	}
+	if err != nil {
+		return failedStatus(err)
+	}
+	return &gen.ReplicaStatus{Address: s.selfAddr, Status: gen.ReplicaStatus_DELETED}
+}
+
+func failedStatus(err error) *gen.ReplicaStatus {
+	return &gen.ReplicaStatus{
+		Status: gen.ReplicaStatus_FAILED,
+		Error:  err.Error(),
+	}
+}
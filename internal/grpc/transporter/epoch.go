@@ -0,0 +1,55 @@
+package transporter
+
+import (
+	"context"
+	"net"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/pkg/dht"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// checkOrigin compares the ring epoch of the node sending a file with the ring
+// epoch of the current node (see dht.Epoch). Files from nodes with older epochs
+// are rejected, since the nodes may place keys on wrong nodes. If the epoch of
+// the node is newer, the current node pulls the nodes of the sending node.
+// Nodes with the same view of the nodes accept each other's files regardless
+// of the versions of their epochs.
+func (s *serverAPI) checkOrigin(ctx context.Context, origin *gen.NodeInfo) error {
+	epoch := dht.Epoch{Version: origin.GetEpoch(), Digest: origin.GetEpochDigest()}
+	current := s.dhtService.Epoch()
+	if epoch.Older(current) {
+		return status.Errorf(codes.Aborted,
+			"stale ring epoch %s of node %s, current epoch is %s", epoch, origin.GetAddress(), current)
+	}
+	if epoch.Newer(current) {
+		if node, err := originNode(ctx, origin); err == nil {
+			s.dhtService.ObserveEpoch(node, epoch)
+		}
+	}
+	return nil
+}
+
+// originNode returns the node sending a file. If the node doesn't know its host
+// (e.g. it's listening on ":5555"), the host is taken from the connection
+func originNode(ctx context.Context, origin *gen.NodeInfo) (*dht.Node, error) {
+	address := origin.GetAddress()
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if p, ok := peer.FromContext(ctx); ok && (host == "" || net.ParseIP(host).IsUnspecified()) {
+		if peerHost, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			address = net.JoinHostPort(peerHost, port)
+		}
+	}
+
+	node, err := dht.ResolveNode(address, origin.GetWeight(), origin.GetZone())
+	if err != nil {
+		return nil, err
+	}
+	node.ID, node.Name = origin.GetId(), origin.GetName()
+	return node, nil
+}
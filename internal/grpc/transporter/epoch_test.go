@@ -0,0 +1,65 @@
+package transporter
+
+import (
+	"context"
+	"testing"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/internal/services"
+	"github.com/gfxv/go-stash/pkg/dht"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// epochAPI returns a server with the given ring epoch, newer epochs seen
+// on the nodes sending files are sent to the returned channel
+func epochAPI(t *testing.T, version uint64) (*serverAPI, dht.Epoch, chan services.NewerEpoch) {
+	ring, _ := zonedRing(t, "", "")
+	newer := make(chan services.NewerEpoch, 1)
+	dhtService := services.NewDHTService(dht.NewHashRing(), "", dht.Epoch{Version: version}, newer)
+	for _, node := range ring.GetNodes() {
+		assert.NoError(t, dhtService.AddNode(node))
+	}
+	return &serverAPI{dhtService: dhtService}, dhtService.Epoch(), newer
+}
+
+func TestCheckOriginRejectsOlderEpoch(t *testing.T) {
+	s, current, newer := epochAPI(t, 5)
+	origin := &gen.NodeInfo{Address: "127.0.0.1:6001", Epoch: current.Version - 1, EpochDigest: current.Digest + 1}
+
+	err := s.checkOrigin(context.Background(), origin)
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	assert.Len(t, newer, 0)
+
+	// older nodes send epochs without the digest
+	origin.EpochDigest = 0
+	err = s.checkOrigin(context.Background(), origin)
+	assert.Equal(t, codes.Aborted, status.Code(err))
+}
+
+func TestCheckOriginAcceptsSameView(t *testing.T) {
+	s, current, newer := epochAPI(t, 5)
+
+	// nodes with the same nodes accept each other's files regardless of the versions
+	for _, version := range []uint64{0, current.Version - 1, current.Version, current.Version + 1} {
+		origin := &gen.NodeInfo{Address: "127.0.0.1:6001", Epoch: version, EpochDigest: current.Digest}
+		assert.NoError(t, s.checkOrigin(context.Background(), origin))
+	}
+	// concurrent changes are accepted, gossip brings the nodes to the same view
+	origin := &gen.NodeInfo{Address: "127.0.0.1:6001", Epoch: current.Version, EpochDigest: current.Digest + 1}
+	assert.NoError(t, s.checkOrigin(context.Background(), origin))
+	assert.Len(t, newer, 0)
+}
+
+func TestCheckOriginPullsNewerEpoch(t *testing.T) {
+	s, current, newer := epochAPI(t, 5)
+	origin := &gen.NodeInfo{Id: "node-9", Address: "127.0.0.1:6001", Epoch: current.Version + 1, EpochDigest: current.Digest + 1}
+
+	assert.NoError(t, s.checkOrigin(context.Background(), origin))
+	assert.Len(t, newer, 1)
+	seen := <-newer
+	assert.Equal(t, dht.Epoch{Version: current.Version + 1, Digest: current.Digest + 1}, seen.Epoch)
+	assert.Equal(t, "node-9", seen.Node.ID)
+	assert.Equal(t, "127.0.0.1:6001", seen.Node.Addr.String())
+}
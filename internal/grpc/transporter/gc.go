@@ -0,0 +1,42 @@
+package transporter
+
+import (
+	"context"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/pkg/cas"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CollectGarbage removes files which are not referenced by any key
+// from the current node, or only reports them if dry run is requested
+func (s *serverAPI) CollectGarbage(
+	ctx context.Context,
+	gcRequest *gen.CollectGarbageRequest,
+) (*gen.CollectGarbageResponse, error) {
+	opts := cas.GCOpts{
+		GracePeriod: s.gcGracePeriod,
+		DryRun:      gcRequest.GetDryRun(),
+	}
+	if gcRequest.GracePeriod != nil {
+		if err := gcRequest.GetGracePeriod().CheckValid(); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid grace period: %v", err)
+		}
+		opts.GracePeriod = gcRequest.GetGracePeriod().AsDuration()
+	}
+	if opts.GracePeriod < 0 {
+		return nil, status.Error(codes.InvalidArgument, "grace period can't be negative")
+	}
+
+	result, err := s.storageService.CollectGarbage(opts)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "can't collect garbage: %v", err)
+	}
+
+	return &gen.CollectGarbageResponse{
+		Scanned:    uint64(result.Scanned),
+		Removed:    result.Removed,
+		FreedBytes: uint64(result.FreedBytes),
+	}, nil
+}
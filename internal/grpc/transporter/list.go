@@ -0,0 +1,166 @@
+package transporter
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/pkg/dht"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const defaultPageSize = 100
+const maxPageSize = 1000
+
+// ListKeys streams keys with the requested prefix page by page.
+// If cluster listing is requested, pages of all the nodes are merged,
+// so every key is listed once, even if it is stored on several nodes
+func (s *serverAPI) ListKeys(
+	listRequest *gen.ListKeysRequest,
+	stream gen.Transporter_ListKeysServer,
+) error {
+	pageSize := int(listRequest.GetPageSize())
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		return status.Errorf(codes.InvalidArgument, "page size can't be greater than %d", maxPageSize)
+	}
+
+	after, err := decodeContinuationToken(listRequest.GetContinuationToken())
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid continuation token: %v", err)
+	}
+
+	prefix := listRequest.GetPrefix()
+	for {
+		var keys []string
+		var last bool
+		if listRequest.GetCluster() {
+			keys, last, err = s.listClusterPage(stream.Context(), prefix, after, pageSize)
+		} else {
+			keys, last, err = s.listLocalPage(prefix, after, pageSize)
+		}
+		if err != nil {
+			return err
+		}
+
+		response := &gen.ListKeysResponse{Keys: keys}
+		if !last {
+			after = keys[len(keys)-1]
+			response.ContinuationToken = encodeContinuationToken(after)
+		}
+		if err := stream.Send(response); err != nil {
+			return err
+		}
+
+		if last {
+			return nil
+		}
+	}
+}
+
+// listLocalPage returns a page of keys stored on the current node
+// and whether it's the last page
+func (s *serverAPI) listLocalPage(prefix string, after string, pageSize int) ([]string, bool, error) {
+	keys, err := s.storageService.ListKeys(prefix, after, pageSize)
+	if err != nil {
+		return nil, false, status.Errorf(codes.Internal, "can't list keys: %v", err)
+	}
+	return keys, len(keys) < pageSize, nil
+}
+
+// listClusterPage returns a page of keys stored on all the nodes
+// and whether it's the last page.
+//
+// Every node returns its own page after the same key, the smallest
+// pageSize distinct keys of all the pages make up the cluster page.
+func (s *serverAPI) listClusterPage(
+	ctx context.Context,
+	prefix string,
+	after string,
+	pageSize int,
+) ([]string, bool, error) {
+	nodes := make([]*dht.Node, 0)
+	for _, node := range s.dhtService.GetNodes() {
+		nodes = append(nodes, node)
+	}
+
+	type nodePage struct {
+		keys []string
+		err  error
+	}
+	pages := forEachNode(nodes, func(node *dht.Node) nodePage {
+		if s.isSelf(node) {
+			keys, _, err := s.listLocalPage(prefix, after, pageSize)
+			return nodePage{keys: keys, err: err}
+		}
+
+		var keys []string
+		err := callNode(node, func(client gen.TransporterClient) error {
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel() // only the first page is needed
+
+			stream, err := client.ListKeys(ctx, &gen.ListKeysRequest{
+				Prefix:            prefix,
+				PageSize:          uint32(pageSize),
+				ContinuationToken: encodeContinuationToken(after),
+			})
+			if err != nil {
+				return err
+			}
+			response, err := stream.Recv()
+			if err != nil {
+				return err
+			}
+			keys = response.GetKeys()
+			return nil
+		})
+		return nodePage{keys: keys, err: err}
+	})
+
+	last := true
+	unique := make(map[string]bool)
+	for i, page := range pages {
+		if page.err != nil {
+			return nil, false, status.Errorf(codes.Unavailable,
+				"can't list keys on node %s: %v", nodes[i].Addr.String(), page.err)
+		}
+		if len(page.keys) >= pageSize {
+			last = false
+		}
+		for _, key := range page.keys {
+			unique[key] = true
+		}
+	}
+
+	keys := make([]string, 0, len(unique))
+	for key := range unique {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if len(keys) > pageSize {
+		keys = keys[:pageSize]
+		last = false
+	}
+
+	return keys, last, nil
+}
+
+func encodeContinuationToken(after string) string {
+	if after == "" {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(after))
+}
+
+func decodeContinuationToken(token string) (string, error) {
+	after, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", fmt.Errorf("malformed token")
+	}
+	return string(after), nil
+}
@@ -0,0 +1,104 @@
+package transporter
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// listPages returns all the pages streamed by ListKeys
+func listPages(t *testing.T, client gen.TransporterClient, request *gen.ListKeysRequest) []*gen.ListKeysResponse {
+	stream, err := client.ListKeys(context.Background(), request)
+	assert.NoError(t, err)
+
+	pages := make([]*gen.ListKeysResponse, 0)
+	for {
+		page, err := stream.Recv()
+		if err == io.EOF {
+			return pages
+		}
+		if !assert.NoError(t, err) {
+			return pages
+		}
+		pages = append(pages, page)
+	}
+}
+
+func TestListKeysPaging(t *testing.T) {
+	nodes := startTestCluster(t, 1, 0)
+	for _, key := range []string{"a/3", "a/1", "b/1", "a/2", "a/4", "a/5"} {
+		nodes[0].put(t, key, []byte("data of "+key))
+	}
+
+	pages := listPages(t, nodes[0].client, &gen.ListKeysRequest{Prefix: "a/", PageSize: 2})
+	assert.Len(t, pages, 3)
+	assert.Equal(t, []string{"a/1", "a/2"}, pages[0].GetKeys())
+	assert.Equal(t, []string{"a/3", "a/4"}, pages[1].GetKeys())
+	assert.Equal(t, []string{"a/5"}, pages[2].GetKeys())
+	assert.Empty(t, pages[2].GetContinuationToken())
+
+	// listing is resumed right after the page of the token
+	resumed := listPages(t, nodes[0].client, &gen.ListKeysRequest{
+		Prefix:            "a/",
+		PageSize:          3,
+		ContinuationToken: pages[0].GetContinuationToken(),
+	})
+	// a full page can't tell if it's the last one, so an empty page follows it
+	assert.Len(t, resumed, 2)
+	assert.Equal(t, []string{"a/3", "a/4", "a/5"}, resumed[0].GetKeys())
+	assert.Empty(t, resumed[1].GetKeys())
+}
+
+func TestListKeysInvalidRequest(t *testing.T) {
+	nodes := startTestCluster(t, 1, 0)
+
+	for _, request := range []*gen.ListKeysRequest{
+		{PageSize: maxPageSize + 1},
+		{ContinuationToken: "not base64!"},
+	} {
+		stream, err := nodes[0].client.ListKeys(context.Background(), request)
+		assert.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+}
+
+func TestListKeysCluster(t *testing.T) {
+	nodes := startTestCluster(t, 3, 0)
+	nodes[0].put(t, "k1", []byte("one"))
+	nodes[0].put(t, "k4", []byte("four"))
+	nodes[1].put(t, "k2", []byte("two"))
+	nodes[1].put(t, "k4", []byte("four")) // replica
+	nodes[2].put(t, "k3", []byte("three"))
+	nodes[2].put(t, "k5", []byte("five"))
+
+	pages := listPages(t, nodes[0].client, &gen.ListKeysRequest{Cluster: true, PageSize: 2})
+	keys := make([]string, 0)
+	for _, page := range pages {
+		assert.LessOrEqual(t, len(page.GetKeys()), 2)
+		keys = append(keys, page.GetKeys()...)
+	}
+	// every key is listed once in order
+	assert.Equal(t, []string{"k1", "k2", "k3", "k4", "k5"}, keys)
+
+	// without cluster listing only the keys of the node are listed
+	local := listPages(t, nodes[1].client, &gen.ListKeysRequest{PageSize: 10})
+	assert.Len(t, local, 1)
+	assert.Equal(t, []string{"k2", "k4"}, local[0].GetKeys())
+}
+
+func TestListKeysClusterUnavailableNode(t *testing.T) {
+	nodes := startTestCluster(t, 2, 0)
+	nodes[0].put(t, "k1", []byte("one"))
+	nodes[1].server.Stop()
+
+	stream, err := nodes[0].client.ListKeys(context.Background(), &gen.ListKeysRequest{Cluster: true})
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
@@ -0,0 +1,40 @@
+package transporter
+
+import (
+	"sync"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/pkg/dht"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// callNode opens a connection to the node and calls fn
+// with a Transporter client using this connection
+func callNode(node *dht.Node, fn func(client gen.TransporterClient) error) error {
+	conn, err := grpc.NewClient(node.Addr.String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return fn(gen.NewTransporterClient(conn))
+}
+
+// forEachNode calls fn for every node (or a key on a node) concurrently.
+// Results are returned in the same order as nodes.
+func forEachNode[N, T any](nodes []N, fn func(node N) T) []T {
+	results := make([]T, len(nodes))
+
+	var wg sync.WaitGroup
+	wg.Add(len(nodes))
+	for i, node := range nodes {
+		go func() {
+			defer wg.Done()
+			results[i] = fn(node)
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
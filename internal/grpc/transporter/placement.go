@@ -0,0 +1,73 @@
+package transporter
+
+import (
+	"context"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/pkg/cas"
+	"github.com/gfxv/go-stash/pkg/dht"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetPlacementReport lists keys stored on the current node which
+// available copies span fewer zones than the number of copies
+func (s *serverAPI) GetPlacementReport(
+	ctx context.Context,
+	reportRequest *gen.PlacementReportRequest,
+) (*gen.PlacementReport, error) {
+	limit := int(reportRequest.GetLimit())
+	report := &gen.PlacementReport{
+		Copies: uint32(s.replicationFactor + 1),
+		Zones:  uint32(s.dhtService.CountZones()),
+		Keys:   make([]*gen.KeyPlacement, 0),
+	}
+
+	offset := 0
+	for {
+		keys, err := s.storageService.GetKeysByChunks(offset)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "can't get keys: %v", err)
+		}
+
+		for _, key := range keys {
+			report.Scanned++
+			nodes, err := s.dhtService.GetReplicaNodes(key, s.replicationFactor)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "can't get nodes for key '%s': %v", key, err)
+			}
+
+			available := make([]*dht.Node, 0, len(nodes))
+			for _, node := range nodes {
+				if node.Alive {
+					available = append(available, node)
+				}
+			}
+			zones := dht.CountZones(available)
+			if zones >= int(report.Copies) {
+				continue
+			}
+
+			report.UnderDiversified++
+			if limit > 0 && len(report.Keys) >= limit {
+				continue
+			}
+			placement := &gen.KeyPlacement{
+				Key:   key,
+				Nodes: make([]*gen.NodeInfo, 0, len(nodes)),
+				Zones: uint32(zones),
+			}
+			for _, node := range nodes {
+				placement.Nodes = append(placement.Nodes, makeNodeInfo(node))
+			}
+			report.Keys = append(report.Keys, placement)
+		}
+
+		if len(keys) < cas.DB_CHUNK_SIZE {
+			break
+		}
+		offset += cas.DB_CHUNK_SIZE
+	}
+
+	return report, nil
+}
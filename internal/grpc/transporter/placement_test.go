@@ -0,0 +1,110 @@
+package transporter
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/internal/services"
+	"github.com/gfxv/go-stash/pkg/dht"
+	"github.com/stretchr/testify/assert"
+)
+
+// zonedRing returns a DHT of alive nodes placed in the given zones
+func zonedRing(t *testing.T, zones ...string) (*services.DHTService, []*dht.Node) {
+	ring := dht.NewHashRingWithVNodes(16)
+	nodes := make([]*dht.Node, 0, len(zones))
+	for i, zone := range zones {
+		addr, err := net.ResolveTCPAddr("tcp", fmt.Sprintf("10.0.0.%d:5555", i+1))
+		assert.NoError(t, err)
+		node := dht.NewWeightedNode(addr, dht.DEFAULT_WEIGHT)
+		node.Zone = zone
+		node.Alive = true
+		nodes = append(nodes, node)
+	}
+	ring.AddNode(nodes...)
+	return services.NewDHTService(ring, "", dht.Epoch{}, nil), nodes
+}
+
+// putKeys stores count keys on the node and returns them
+func putKeys(t *testing.T, node *testNode, count int) []string {
+	keys := make([]string, 0, count)
+	for i := range count {
+		key := fmt.Sprintf("%d-key", i)
+		node.put(t, key, []byte("data of "+key))
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestPlacementReportUnderSpread(t *testing.T) {
+	node := startTestCluster(t, 1, 1)[0]
+	node.api.dhtService, _ = zonedRing(t, "rack-1", "rack-1")
+	putKeys(t, node, 5)
+
+	// both copies are always in the single zone
+	report, err := node.api.GetPlacementReport(context.Background(), &gen.PlacementReportRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), report.GetCopies())
+	assert.Equal(t, uint32(1), report.GetZones())
+	assert.Equal(t, uint64(5), report.GetScanned())
+	assert.Equal(t, uint64(5), report.GetUnderDiversified())
+	assert.Len(t, report.GetKeys(), 5)
+	for _, key := range report.GetKeys() {
+		assert.Equal(t, uint32(1), key.GetZones())
+		assert.Len(t, key.GetNodes(), 2)
+	}
+}
+
+func TestPlacementReportSpread(t *testing.T) {
+	node := startTestCluster(t, 1, 1)[0]
+	// copies are spread across zones whenever possible
+	node.api.dhtService, _ = zonedRing(t, "rack-1", "rack-1", "rack-2")
+	putKeys(t, node, 20)
+
+	report, err := node.api.GetPlacementReport(context.Background(), &gen.PlacementReportRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), report.GetZones())
+	assert.Equal(t, uint64(20), report.GetScanned())
+	assert.Zero(t, report.GetUnderDiversified())
+	assert.Empty(t, report.GetKeys())
+}
+
+func TestPlacementReportUnavailable(t *testing.T) {
+	node := startTestCluster(t, 1, 1)[0]
+	dhtService, nodes := zonedRing(t, "rack-1", "rack-2", "rack-3")
+	node.api.dhtService = dhtService
+	nodes[2].Alive = false
+	putKeys(t, node, 20)
+
+	report, err := node.api.GetPlacementReport(context.Background(), &gen.PlacementReportRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(3), report.GetZones())
+	assert.Positive(t, report.GetUnderDiversified())
+	assert.Less(t, report.GetUnderDiversified(), report.GetScanned())
+	assert.Len(t, report.GetKeys(), int(report.GetUnderDiversified()))
+
+	// only copies on the unavailable node are missing
+	for _, key := range report.GetKeys() {
+		assert.Equal(t, uint32(1), key.GetZones())
+		addresses := make([]string, 0)
+		for _, info := range key.GetNodes() {
+			addresses = append(addresses, info.GetAddress())
+		}
+		assert.Contains(t, addresses, nodes[2].Addr.String())
+	}
+}
+
+func TestPlacementReportLimit(t *testing.T) {
+	node := startTestCluster(t, 1, 1)[0]
+	node.api.dhtService, _ = zonedRing(t, "rack-1", "rack-1")
+	putKeys(t, node, 5)
+
+	report, err := node.api.GetPlacementReport(context.Background(), &gen.PlacementReportRequest{Limit: 2})
+	assert.NoError(t, err)
+	// all the keys are counted, but only limit keys are listed
+	assert.Equal(t, uint64(5), report.GetUnderDiversified())
+	assert.Len(t, report.GetKeys(), 2)
+}
@@ -0,0 +1,124 @@
+package transporter
+
+import (
+	"context"
+	"errors"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/internal/services"
+	"github.com/gfxv/go-stash/pkg/cas"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var rebaseStates = map[cas.RebaseState]gen.RebaseJob_State{
+	cas.RebasePending:   gen.RebaseJob_PENDING,
+	cas.RebaseRunning:   gen.RebaseJob_RUNNING,
+	cas.RebaseDone:      gen.RebaseJob_DONE,
+	cas.RebaseFailed:    gen.RebaseJob_FAILED,
+	cas.RebaseCancelled: gen.RebaseJob_CANCELLED,
+}
+
+// Rebase starts a rebase job, which is run by the sender in the background.
+// Dry runs only plan the rebase, so the job is returned once it's finished
+func (s *serverAPI) Rebase(ctx context.Context, request *gen.RebaseRequest) (*gen.RebaseJob, error) {
+	job, err := s.rebase.Start(request.GetDryRun(), request.GetResume())
+	if errors.Is(err, services.ErrRebaseRunning) || errors.Is(err, services.ErrRebaseNotResumable) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "can't start rebase: %v", err)
+	}
+	select {
+	case s.notifyRebase <- true:
+	default: // the sender is notified already, it runs the pending job
+	}
+
+	if !job.DryRun {
+		return makeRebaseJob(job), nil
+	}
+	var last *gen.RebaseJob
+	err = s.watchRebase(ctx, job.ID, func(job *gen.RebaseJob) error {
+		last = job
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return last, nil
+}
+
+// WatchRebase streams the rebase job on every change until the job is finished
+func (s *serverAPI) WatchRebase(request *gen.RebaseJobRequest, stream gen.Transporter_WatchRebaseServer) error {
+	return s.watchRebase(stream.Context(), request.GetId(), stream.Send)
+}
+
+// CancelRebase stops the rebase job, keys copied before it stops are removed
+func (s *serverAPI) CancelRebase(ctx context.Context, request *gen.RebaseJobRequest) (*gen.RebaseJob, error) {
+	job, err := s.rebase.Cancel(request.GetId())
+	if errors.Is(err, services.ErrRebaseNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if errors.Is(err, services.ErrRebaseFinished) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "can't cancel rebase: %v", err)
+	}
+	return makeRebaseJob(job), nil
+}
+
+// watchRebase passes the rebase job to send on every change until the job is finished.
+// Changes made while the job is sent are merged into the next one
+func (s *serverAPI) watchRebase(ctx context.Context, id string, send func(*gen.RebaseJob) error) error {
+	for {
+		job, changed, err := s.rebase.Job(id)
+		if errors.Is(err, services.ErrRebaseNotFound) {
+			return status.Error(codes.NotFound, err.Error())
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "can't get rebase job: %v", err)
+		}
+		if err := send(makeRebaseJob(job)); err != nil {
+			return err
+		}
+		if job.Finished() {
+			return nil
+		}
+		// the job is pinned, so a job started after it isn't watched
+		id = job.ID
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+func makeRebaseJob(job cas.RebaseJob) *gen.RebaseJob {
+	plan := make([]*gen.RebaseJob_Target, 0, len(job.Plan))
+	for _, target := range job.Plan {
+		plan = append(plan, &gen.RebaseJob_Target{
+			Address: target.Address,
+			Name:    target.Name,
+			Keys:    uint64(target.Keys),
+			Bytes:   uint64(target.Bytes),
+		})
+	}
+	return &gen.RebaseJob{
+		Id:           job.ID,
+		State:        rebaseStates[job.State],
+		DryRun:       job.DryRun,
+		StartedAt:    makeTimestamp(job.StartedAt),
+		FinishedAt:   makeTimestamp(job.FinishedAt),
+		Plan:         plan,
+		PlannedKeys:  uint64(job.PlannedKeys),
+		PlannedBytes: uint64(job.PlannedBytes),
+		MovedKeys:    uint64(job.MovedKeys),
+		MovedBytes:   uint64(job.MovedBytes),
+		FailedKeys:   uint64(job.FailedKeys),
+		LastKey:      job.LastKey,
+		Error:        job.Error,
+	}
+}
@@ -0,0 +1,39 @@
+package transporter
+
+import (
+	"context"
+
+	gen "github.com/gfxv/go-stash/api"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GetScrubReport returns the report of the last scrubber run on the current node
+func (s *serverAPI) GetScrubReport(ctx context.Context, _ *emptypb.Empty) (*gen.ScrubReport, error) {
+	report, found, err := s.storageService.LastScrubReport()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "can't get scrub report: %v", err)
+	}
+	if !found {
+		return nil, status.Error(codes.NotFound, "scrubber has not finished any run yet")
+	}
+
+	issues := make([]*gen.ScrubIssue, 0, len(report.Issues))
+	for _, issue := range report.Issues {
+		issues = append(issues, &gen.ScrubIssue{
+			Hash:     issue.Hash,
+			Reason:   issue.Reason,
+			Repaired: issue.Repaired,
+		})
+	}
+
+	return &gen.ScrubReport{
+		StartedAt:    timestamppb.New(report.StartedAt),
+		FinishedAt:   timestamppb.New(report.FinishedAt),
+		Scanned:      uint64(report.Scanned),
+		ScannedBytes: uint64(report.ScannedBytes),
+		Issues:       issues,
+	}, nil
+}
@@ -1,45 +1,98 @@
 package transporter
 
 import (
-	"bytes"
+	"cmp"
 	"context"
 	"fmt"
 	gen "github.com/gfxv/go-stash/api"
 	"github.com/gfxv/go-stash/internal/services"
 	"github.com/gfxv/go-stash/pkg/cas"
 	"github.com/gfxv/go-stash/pkg/dht"
+	"github.com/gfxv/go-stash/pkg/gossip"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"io"
-	"net"
+	"math"
+	"slices"
+	"time"
 )
 
+// for more info: https://github.com/grpc/grpc.github.io/issues/371
+const fileChunkSize = 32 * 1024 // 32 KiB
+
 type serverAPI struct {
 	gen.UnimplementedTransporterServer
 	storageService *services.StorageService
 	dhtService     *services.DHTService
 
-	notifyRebase    chan<- bool
-	replicationChan chan<- *cas.KeyHashPair // TODO: add replicationChan to app configuration !!!
+	selfAddr          string
+	selfID            string
+	selfName          string
+	selfWeight        float64
+	selfZone          string
+	replicationFactor int
+	gcGracePeriod     time.Duration
+
+	// gossip holds the lifecycle state of the current node and spreads its changes
+	gossip       *gossip.Gossip
+	decommission *services.DecommissionService
+	rebase       *services.RebaseService
+
+	notifyRebase       chan<- bool
+	notifyDecommission chan<- bool
+	replicationChan    chan<- *cas.KeyHashPair // TODO: add replicationChan to app configuration !!!
+}
+
+// TransporterOpts holds the settings of the Transporter service
+type TransporterOpts struct {
+	Port              int
+	NodeID            string
+	NodeName          string
+	Weight            float64
+	Zone              string
+	ReplicationFactor int
+	GCGracePeriod     time.Duration
+	Gossip            *gossip.Gossip
+	Decommission      *services.DecommissionService
+	Rebase            *services.RebaseService
+
+	NotifyRebase       chan<- bool
+	NotifyDecommission chan<- bool
+	ReplicationChan    chan<- *cas.KeyHashPair
 }
 
 func Register(
 	gRPC *grpc.Server,
 	storageService *services.StorageService,
 	dhtService *services.DHTService,
-	notifyRebase chan<- bool,
-	replicationChan chan<- *cas.KeyHashPair,
+	opts *TransporterOpts,
 ) {
 	gen.RegisterTransporterServer(gRPC, &serverAPI{
-		storageService:  storageService,
-		dhtService:      dhtService,
-		notifyRebase:    notifyRebase,
-		replicationChan: replicationChan,
+		storageService:     storageService,
+		dhtService:         dhtService,
+		selfAddr:           fmt.Sprintf(":%d", opts.Port),
+		selfID:             opts.NodeID,
+		selfName:           opts.NodeName,
+		selfWeight:         opts.Weight,
+		selfZone:           opts.Zone,
+		replicationFactor:  opts.ReplicationFactor,
+		gcGracePeriod:      opts.GCGracePeriod,
+		gossip:             opts.Gossip,
+		decommission:       opts.Decommission,
+		rebase:             opts.Rebase,
+		notifyRebase:       opts.NotifyRebase,
+		notifyDecommission: opts.NotifyDecommission,
+		replicationChan:    opts.ReplicationChan,
 	})
 }
 
+// GetDestination returns the first available node from the preference list
+// of the key, which serves the access of the request: reads are served
+// by active and draining nodes, writes are taken by active and joining nodes.
+// Requests without access get active nodes first, then draining ones
 func (s *serverAPI) GetDestination(
 	ctx context.Context,
 	keyRequest *gen.KeyRequest,
@@ -49,18 +102,39 @@ func (s *serverAPI) GetDestination(
 		return nil, status.Error(codes.InvalidArgument, "key is empty")
 	}
 
-	node, err := s.dhtService.GetNodeForKey(key)
+	var nodes []*dht.Node
+	var err error
+	switch keyRequest.GetAccess() {
+	case gen.KeyRequest_READ:
+		nodes, err = s.dhtService.GetReadNodes(key, s.replicationFactor)
+	case gen.KeyRequest_WRITE:
+		nodes, err = s.dhtService.GetWriteNodes(key, s.replicationFactor)
+	default:
+		// active nodes serve both reads and writes, so they are preferred
+		nodes, err = s.dhtService.GetReadNodes(key, s.replicationFactor)
+		slices.SortStableFunc(nodes, func(a, b *dht.Node) int {
+			return cmp.Compare(activeRank(a), activeRank(b))
+		})
+	}
 	if err != nil {
 		return nil, status.Error(codes.NotFound, err.Error())
 	}
-	if !node.Alive {
-		return nil, status.Error(codes.Internal, fmt.Sprintf("node corresponding for key '%s' is unavailable", key))
+	for _, node := range nodes {
+		if !node.Alive {
+			continue
+		}
+		return makeNodeInfo(node), nil
 	}
 
-	return &gen.NodeInfo{
-		Address: node.Addr.String(),
-		Alive:   node.Alive,
-	}, nil
+	return nil, status.Error(codes.Internal, fmt.Sprintf("nodes corresponding for key '%s' are unavailable", key))
+}
+
+// activeRank orders active nodes before the others
+func activeRank(node *dht.Node) int {
+	if node.State == dht.NodeActive {
+		return 0
+	}
+	return 1
 }
 
 // SendChunks receives a stream of file chunks (or whole file)
@@ -77,25 +151,16 @@ func (s *serverAPI) SendChunks(stream gen.Transporter_SendChunksServer) error {
 	}
 	compressed := req.GetMeta().GetCompressed()
 
-	buffer := bytes.Buffer{}
-	for {
-		req, err := stream.Recv()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return status.Errorf(codes.Unknown, "can't receive chunk: %v", err)
-		}
-
-		chunk := req.GetChunkData()
-		if chunk == nil || len(chunk) == 0 {
-			return status.Errorf(codes.InvalidArgument, "empty chunk")
-		}
-		_, err = buffer.Write(chunk)
-		if err != nil {
-			return status.Errorf(codes.Internal, "can't write chunk data: %v", err)
+	if origin := req.GetMeta().GetOrigin(); origin != nil {
+		if err := s.checkOrigin(stream.Context(), origin); err != nil {
+			return err
 		}
 	}
+	if state := s.selfState(); !state.TakesWrites() {
+		return status.Errorf(codes.FailedPrecondition, "node is %s and takes no writes", state)
+	}
+
+	reader := &chunkReader{stream: stream}
 
 	var contentHash string
 	if compressed {
@@ -104,7 +169,13 @@ func (s *serverAPI) SendChunks(stream gen.Transporter_SendChunksServer) error {
 			return status.Errorf(codes.InvalidArgument, "empty hash")
 		}
 
-		err := s.storageService.SaveCompressed(key, contentHash, buffer.Bytes())
+		err := s.storageService.SaveCompressed(key, contentHash, reader)
+		if reader.err != nil {
+			return reader.err
+		}
+		if status.Code(err) == codes.DataLoss {
+			return err
+		}
 		if err != nil {
 			return status.Errorf(codes.Internal, "can't save compressed file: %v", err)
 		}
@@ -113,11 +184,10 @@ func (s *serverAPI) SendChunks(stream gen.Transporter_SendChunksServer) error {
 		if len(path) == 0 {
 			return status.Errorf(codes.InvalidArgument, "empty path")
 		}
-		file := &cas.File{
-			Path: path,
-			Data: buffer.Bytes(),
+		contentHash, err = s.storageService.SaveRaw(key, path, reader)
+		if reader.err != nil {
+			return reader.err
 		}
-		contentHash, err = s.storageService.SaveRaw(key, file)
 		if err != nil {
 			return status.Errorf(codes.Internal, "can't save raw file: %v", err)
 		}
@@ -133,10 +203,52 @@ func (s *serverAPI) SendChunks(stream gen.Transporter_SendChunksServer) error {
 	}
 
 	return stream.SendAndClose(&gen.StreamStatus{
-		Size: uint32(len(buffer.Bytes())),
+		Size:        uint32(reader.size),
+		ContentHash: contentHash,
 	})
 }
 
+// chunkReader adapts a stream of uploaded chunks to io.Reader,
+// so the data can be stored without buffering the whole file.
+//
+// Errors that occur while receiving chunks are kept in err,
+// so the caller can report them with the appropriate status code.
+type chunkReader struct {
+	stream gen.Transporter_SendChunksServer
+	chunk  []byte
+	size   int
+	err    error
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		req, err := r.stream.Recv()
+		if err == io.EOF {
+			return 0, io.EOF
+		}
+		if err != nil {
+			r.err = status.Errorf(codes.Unknown, "can't receive chunk: %v", err)
+			return 0, r.err
+		}
+
+		chunk := req.GetChunkData()
+		if len(chunk) == 0 {
+			r.err = status.Errorf(codes.InvalidArgument, "empty chunk")
+			return 0, r.err
+		}
+		r.chunk = chunk
+		r.size += len(chunk)
+	}
+
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}
+
 // ReceiveInfo returns hashes that have same key
 func (s *serverAPI) ReceiveInfo(
 	ctx context.Context,
@@ -146,6 +258,9 @@ func (s *serverAPI) ReceiveInfo(
 	if len(key) == 0 {
 		return nil, status.Error(codes.InvalidArgument, "empty key")
 	}
+	if state := s.selfState(); state == dht.NodeJoining {
+		return nil, status.Errorf(codes.FailedPrecondition, "node is %s and serves no reads", state)
+	}
 
 	hashes, err := s.storageService.GetHashesByKey(key)
 	if err != nil {
@@ -162,7 +277,8 @@ func (s *serverAPI) ReceiveInfo(
 }
 
 // ReceiveChunks sends a stream of file chunks
-// (or whole file) based on provided hash
+// (or whole file) based on provided hash.
+// If offset or length is provided, only the requested range is sent
 func (s *serverAPI) ReceiveChunks(
 	chunkRequest *gen.ReceiveChunkRequest,
 	stream gen.Transporter_ReceiveChunksServer,
@@ -171,30 +287,69 @@ func (s *serverAPI) ReceiveChunks(
 	if len(hash) == 0 {
 		return status.Error(codes.InvalidArgument, "empty hash")
 	}
+	if state := s.selfState(); state == dht.NodeJoining {
+		return status.Errorf(codes.FailedPrecondition, "node is %s and serves no reads", state)
+	}
 	needDecompression := chunkRequest.GetNeedDecompression()
 
-	fileContent, err := s.storageService.GetFileDataByHash(hash, needDecompression)
+	offset, length := chunkRequest.GetOffset(), chunkRequest.GetLength()
+	if offset > math.MaxInt64 || length > math.MaxInt64 {
+		return status.Error(codes.InvalidArgument, "offset or length is too large")
+	}
+
+	reader, err := s.storageService.GetFileReaderByHash(hash, needDecompression, int64(offset), int64(length))
 	if err != nil {
 		return status.Errorf(codes.NotFound, "can't get file with hash %s: %v", hash, err)
 	}
+	defer reader.Close()
+
+	buffer := make([]byte, fileChunkSize)
+	for {
+		n, err := io.ReadFull(reader, buffer)
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return status.Errorf(codes.Internal, "can't read file with hash %s: %v", hash, err)
+		}
+
+		chunk := &gen.ReceiveChunkResponse{Data: buffer[:n]}
+		if err := stream.Send(chunk); err != nil {
+			return status.Errorf(codes.Unknown, "can't send chunk: %v", err)
+		}
 
-	// TODO: add byte splitting and streaming file in chunks
-	chunk := &gen.ReceiveChunkResponse{Data: fileContent}
-	if err := stream.Send(chunk); err != nil {
-		return status.Errorf(codes.Unknown, "can't send chunk: %v", err)
+		if err == io.ErrUnexpectedEOF { // last (partial) chunk
+			break
+		}
 	}
 
 	return nil
 }
 
+// Identify returns information about the current node
+func (s *serverAPI) Identify(ctx context.Context, _ *emptypb.Empty) (*gen.NodeInfo, error) {
+	epoch := s.dhtService.Epoch()
+	return &gen.NodeInfo{
+		Address:     s.selfAddr,
+		Alive:       true,
+		Weight:      s.selfWeight,
+		Zone:        s.selfZone,
+		Id:          s.selfID,
+		Name:        s.selfName,
+		State:       gen.NodeInfo_State(s.selfState()),
+		Epoch:       epoch.Version,
+		EpochDigest: epoch.Digest,
+	}, nil
+}
+
 // SyncNodes ...
 func (s *serverAPI) SyncNodes(_ *emptypb.Empty, stream gen.Transporter_SyncNodesServer) error {
+	// the epoch is taken first, so the nodes are at least as new as the epoch
+	epoch := s.dhtService.Epoch()
 	nodes := s.dhtService.GetNodes()
 	for _, node := range nodes {
-		nodeInfo := &gen.NodeInfo{
-			Address: node.Addr.String(),
-			Alive:   node.Alive,
-		}
+		nodeInfo := makeNodeInfo(node)
+		nodeInfo.Epoch, nodeInfo.EpochDigest = epoch.Version, epoch.Digest
 		if err := stream.Send(nodeInfo); err != nil {
 			return err
 		}
@@ -202,51 +357,92 @@ func (s *serverAPI) SyncNodes(_ *emptypb.Empty, stream gen.Transporter_SyncNodes
 	return nil
 }
 
-// Rebase ...
-func (s *serverAPI) Rebase(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
-	// anything other than that ???
-	s.notifyRebase <- true
-	return nil, nil
-}
-
-// AnnounceNewNode ...
+// AnnounceNewNode adds the announced node to the DHT.
+// Nodes are matched by their IDs (or by their addresses, if the ID is not known),
+// so a known node which announces a new address, name, weight or zone is updated,
+// and a node with a new ID replaces the node with the same address.
+// Error with code AlreadyExists is returned if the node is already in the DHT.
 func (s *serverAPI) AnnounceNewNode(
 	ctx context.Context,
 	newNode *gen.NodeInfo,
 ) (*emptypb.Empty, error) {
 
-	// check if node exists
-	if s.dhtService.NodeExists(newNode.GetAddress()) { // node already exists in hash ring
+	// nodes which don't announce weight get the default one
+	node, err := dht.ResolveNode(newNode.GetAddress(), newNode.GetWeight(), newNode.GetZone())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "can't resolve address: %v", err)
+	}
+	node.ID, node.Name = newNode.GetId(), newNode.GetName()
+	node.State = dht.NodeState(newNode.GetState())
+
+	// check if node exists, nodes without ID can't change known nodes
+	if stored := s.dhtService.FindNode(node); stored != nil && (node.ID == "" || stored.SameAs(node)) {
 		return nil, status.Errorf(codes.AlreadyExists, "node already exists in DHT")
 	}
 
-	// add node to the hash ring
-	addr, err := net.ResolveTCPAddr("tcp", newNode.GetAddress())
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "can't resolve address: %v", err)
+	if err := s.dhtService.AddNode(node); err != nil {
+		return nil, status.Errorf(codes.Internal, "can't save nodes: %v", err)
 	}
 
-	s.dhtService.AddNode(dht.NewNode(addr)) // return err ?
+	return &emptypb.Empty{}, nil
+}
 
-	return nil, nil
+// isSelf returns true if the node is the current node.
+// Nodes are matched by their IDs, or by their addresses if the ID is not known yet
+func (s *serverAPI) isSelf(node *dht.Node) bool {
+	if node.ID != "" {
+		return node.ID == s.selfID
+	}
+	return node.Addr.String() == s.selfAddr
+}
+
+// makeNodeInfo converts the node to its representation sent over gRPC
+func makeNodeInfo(node *dht.Node) *gen.NodeInfo {
+	return &gen.NodeInfo{
+		Address: node.Addr.String(),
+		Alive:   node.Alive,
+		Weight:  node.Weight,
+		Zone:    node.Zone,
+		Id:      node.ID,
+		Name:    node.Name,
+		State:   gen.NodeInfo_State(node.State),
+
+		Suspicion: node.Suspicion,
+		LastSeen:  makeTimestamp(node.LastSeen),
+		Flaps:     uint32(node.Flaps),
+	}
+}
+
+// makeTimestamp converts the time to its representation sent over gRPC,
+// zero time is left unset
+func makeTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
 }
 
-// AnnounceRemoveNode ...
+// AnnounceRemoveNode removes the node from the DHT of the current node.
+// The node is matched by its ID, or by its address if the ID is not set.
+// The node is removed from the gossip membership as well, so it's not added
+// back by the members which still know it
 func (s *serverAPI) AnnounceRemoveNode(
 	ctx context.Context,
 	deadNode *gen.NodeInfo,
 ) (*emptypb.Empty, error) {
-
-	if !s.dhtService.NodeExists(deadNode.GetAddress()) {
+	key := deadNode.GetId()
+	if key == "" {
+		key = deadNode.GetAddress()
+	}
+	node := s.findNode(key)
+	if node == nil {
 		return nil, status.Errorf(codes.NotFound, "node does not exist in DHT")
 	}
 
-	addr, err := net.ResolveTCPAddr("tcp", deadNode.GetAddress())
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "can't resolve address: %v", err)
+	s.gossip.Remove(gossip.Member{ID: node.ID, Address: node.Addr.String()})
+	if err := s.dhtService.RemoveNode(node); err != nil {
+		return nil, status.Errorf(codes.Internal, "can't save nodes: %v", err)
 	}
 
-	s.dhtService.RemoveNode(dht.NewNode(addr)) // return err ?
-
 	return nil, nil
 }
@@ -0,0 +1,125 @@
+package transporter
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/internal/services"
+	"github.com/gfxv/go-stash/pkg/cas"
+	"github.com/gfxv/go-stash/pkg/dht"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// testNode is a node of a test cluster serving the Transporter service
+type testNode struct {
+	node    *dht.Node
+	storage *cas.Storage
+	api     *serverAPI
+	server  *grpc.Server
+	client  gen.TransporterClient
+}
+
+// startTestCluster starts count nodes listening on local ports,
+// every node has its own storage and knows all the nodes
+func startTestCluster(t *testing.T, count int, replicationFactor int) []*testNode {
+	nodes := make([]*testNode, 0, count)
+	for i := range count {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		node := dht.NewNode(listener.Addr())
+		node.ID = fmt.Sprintf("node-%d", i)
+		node.Name = node.ID
+		node.Alive = true
+
+		storage, err := cas.NewDefaultStorage(cas.StorageOpts{
+			BaseDir:       t.TempDir(),
+			HashAlgorithm: cas.SHA256,
+			Pack:          cas.ZLibPack,
+			Unpack:        cas.ZLibUnpack,
+			PackWriter:    cas.ZLibPackWriter,
+			UnpackReader:  cas.ZLibUnpackReader,
+			UnpackFrame:   cas.ZLibUnpackFrame,
+		})
+		assert.NoError(t, err)
+
+		api := &serverAPI{
+			storageService:    services.NewStorageService(storage),
+			selfAddr:          listener.Addr().String(),
+			selfID:            node.ID,
+			replicationFactor: replicationFactor,
+		}
+		server := grpc.NewServer()
+		gen.RegisterTransporterServer(server, api)
+		go server.Serve(listener)
+		t.Cleanup(server.Stop)
+
+		conn, err := grpc.NewClient(node.Addr.String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		assert.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+
+		nodes = append(nodes, &testNode{
+			node:    node,
+			storage: storage,
+			api:     api,
+			server:  server,
+			client:  gen.NewTransporterClient(conn),
+		})
+	}
+
+	for _, n := range nodes {
+		ring := dht.NewHashRing()
+		for _, other := range nodes {
+			copied := *other.node
+			ring.AddNode(&copied)
+		}
+		n.api.dhtService = services.NewDHTService(ring, "", dht.Epoch{}, nil)
+	}
+	return nodes
+}
+
+// put stores the data under the key on the node and returns its hash
+func (n *testNode) put(t *testing.T, key string, data []byte) string {
+	hash, err := n.storage.WriteFromRawData(cas.PrepareRawFile(key, data))
+	assert.NoError(t, err)
+	assert.NoError(t, n.storage.AddNewPath(key, hash))
+	return hash
+}
+
+func TestAnnounceNewNode(t *testing.T) {
+	nodes := startTestCluster(t, 2, 1)
+	s := nodes[0].api
+	known := nodes[1].node
+
+	// known node is matched by its ID
+	announced := &gen.NodeInfo{Address: known.Addr.String(), Weight: known.Weight, Id: known.ID, Name: known.Name}
+	_, err := s.AnnounceNewNode(context.Background(), announced)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	_, err = s.AnnounceNewNode(context.Background(), &gen.NodeInfo{Address: known.Addr.String()})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	// known node with a new address is updated
+	announced.Address = "127.0.0.1:6101"
+	reply, err := s.AnnounceNewNode(context.Background(), announced)
+	assert.NoError(t, err)
+	assert.NotNil(t, reply)
+	assert.Len(t, s.dhtService.GetNodes(), 2)
+	assert.Equal(t, "127.0.0.1:6101", s.dhtService.FindNode(known).Addr.String())
+
+	// new node is added, even if its address was used by another node
+	reply, err = s.AnnounceNewNode(context.Background(), &gen.NodeInfo{Address: "127.0.0.1:6102", Id: "node-2"})
+	assert.NoError(t, err)
+	assert.NotNil(t, reply)
+	assert.Len(t, s.dhtService.GetNodes(), 3)
+	_, err = s.AnnounceNewNode(context.Background(), &gen.NodeInfo{Address: "127.0.0.1:6101", Id: "node-3"})
+	assert.NoError(t, err)
+	assert.Len(t, s.dhtService.GetNodes(), 3)
+	assert.Nil(t, s.dhtService.FindNode(known))
+	assert.True(t, s.dhtService.NodeExists("node-3"))
+}
@@ -0,0 +1,48 @@
+package transporter
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/pkg/cas"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Stat returns metadata of the file with provided hash.
+// Missing file is not an error, the response just has Exists set to false
+func (s *serverAPI) Stat(
+	ctx context.Context,
+	statRequest *gen.StatRequest,
+) (*gen.StatResponse, error) {
+	hash := statRequest.GetHash()
+	if _, _, err := cas.ParseHash(hash); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid hash: %v", err)
+	}
+
+	info, err := s.storageService.Stat(hash)
+	if errors.Is(err, os.ErrNotExist) {
+		return &gen.StatResponse{Hash: hash, Exists: false}, nil
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "can't stat file with hash %s: %v", hash, err)
+	}
+
+	response := &gen.StatResponse{
+		Hash:           hash,
+		Exists:         true,
+		CompressedSize: uint64(info.PackedSize),
+		FilePath:       info.Path,
+		Keys:           info.Keys,
+		CreatedAt:      timestamppb.New(info.CreatedAt),
+	}
+	if info.RawSize != cas.UNKNOWN_SIZE {
+		response.UncompressedSize = proto.Uint64(uint64(info.RawSize))
+	}
+
+	return response, nil
+}
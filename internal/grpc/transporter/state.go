@@ -0,0 +1,89 @@
+package transporter
+
+import (
+	"context"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/pkg/dht"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SetNodeState moves the node to another lifecycle state. Requests for other
+// nodes are forwarded to them, since every node spreads its state itself
+func (s *serverAPI) SetNodeState(
+	ctx context.Context,
+	stateRequest *gen.SetNodeStateRequest,
+) (*gen.NodeInfo, error) {
+	state := dht.NodeState(stateRequest.GetState())
+	if _, err := dht.ParseNodeState(state.String()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	target := stateRequest.GetNode()
+	if target == "" || target == s.selfID || target == s.selfAddr {
+		return s.setSelfState(ctx, state)
+	}
+
+	node := s.findNode(target)
+	if node == nil {
+		return nil, status.Errorf(codes.NotFound, "node '%s' does not exist in DHT", target)
+	}
+	if s.isSelf(node) {
+		return s.setSelfState(ctx, state)
+	}
+
+	var nodeInfo *gen.NodeInfo
+	err := callNode(node, func(client gen.TransporterClient) error {
+		var err error
+		nodeInfo, err = client.SetNodeState(ctx, &gen.SetNodeStateRequest{State: stateRequest.GetState()})
+		return err
+	})
+	if _, ok := status.FromError(err); !ok {
+		return nil, status.Errorf(codes.Unavailable, "can't reach node %s: %v", node, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nodeInfo, nil
+}
+
+// setSelfState moves the current node to the state. The state is spread
+// to other nodes by gossip, and they apply it to their DHT
+func (s *serverAPI) setSelfState(ctx context.Context, state dht.NodeState) (*gen.NodeInfo, error) {
+	if err := s.selfState().CanMoveTo(state); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err := s.dhtService.SaveSelfState(state); err != nil {
+		return nil, status.Errorf(codes.Internal, "can't save node state: %v", err)
+	}
+	s.gossip.SetLifecycle(state.String())
+
+	for _, node := range s.dhtService.GetNodes() {
+		if !s.isSelf(node) {
+			continue
+		}
+		if _, _, err := s.dhtService.SetNodeState(node, state); err != nil {
+			return nil, status.Errorf(codes.Internal, "can't save nodes: %v", err)
+		}
+	}
+	return s.Identify(ctx, nil)
+}
+
+// selfState returns the lifecycle state of the current node
+func (s *serverAPI) selfState() dht.NodeState {
+	// lifecycles are set only from dht node states, so they are always known
+	state, _ := dht.ParseNodeState(s.gossip.Self().Lifecycle)
+	return state
+}
+
+// findNode returns the node of the DHT with the given ID or address,
+// or nil if there is no such node
+func (s *serverAPI) findNode(key string) *dht.Node {
+	for _, node := range s.dhtService.GetNodes() {
+		if node.ID == key || node.Addr.String() == key {
+			return node
+		}
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package sender
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/gfxv/go-stash/internal/services"
 	"github.com/gfxv/go-stash/pkg/cas"
@@ -11,33 +12,63 @@ import (
 	"log/slog"
 	"net"
 	"os"
-	"sync"
+	"slices"
 	"time"
 
 	gen "github.com/gfxv/go-stash/api"
 	"github.com/gfxv/go-stash/pkg/dht"
+	"github.com/gfxv/go-stash/pkg/gossip"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
-const workerRatio = 3
-const minWorkerCount = 1
-const maxWorkerCount = 8
-
 // for more info: https://github.com/grpc/grpc.github.io/issues/371
 const fileChunkSize = 32 * 1024 // 32 KiB
 
+// ErrNotAcknowledged is returned when a node doesn't confirm
+// that it stored the sent file under the same hash
+var ErrNotAcknowledged = errors.New("file is not acknowledged")
+
 type SenderOpts struct {
 	Port              int
+	ID                string
+	Name              string
+	Weight            float64
+	Zone              string
 	SyncNode          string
 	AnnounceNew       bool
 	CheckInterval     time.Duration
 	ReplicationFactor int
+	GCInterval        time.Duration
+	GCGracePeriod     time.Duration
+	ScrubInterval     time.Duration
+	ScrubRate         int64
+	MigrateHashes     bool
+
+	// RememberedNodes are the nodes loaded from the saved membership,
+	// which are not in the nodes list of the config. They are removed
+	// from the DHT if the sync node doesn't know them anymore.
+	RememberedNodes []*dht.Node
+
+	// Gossip detects failures of the nodes and spreads changes of the membership
+	Gossip *gossip.Gossip
+	// Decommission keeps track of the decommission of the current node
+	Decommission *services.DecommissionService
+	// Rebase keeps track of the rebase jobs of the current node
+	Rebase *services.RebaseService
 
 	Logger *slog.Logger
 
-	NotifyRebase    <-chan bool
-	ReplicationChan <-chan *cas.KeyHashPair
+	NotifyRebase       <-chan bool
+	NotifyDecommission <-chan bool
+	ReplicationChan    <-chan *cas.KeyHashPair
+	MemberChanges      <-chan gossip.Member
+	// NewerEpochs receives the nodes which have seen newer ring epochs,
+	// their nodes are pulled by the current node
+	NewerEpochs <-chan services.NewerEpoch
+	// Decommissioned is notified once the decommission is done,
+	// so the current node can be shut down
+	Decommissioned chan<- bool
 }
 
 type Client struct {
@@ -46,6 +77,9 @@ type Client struct {
 
 	storageService *services.StorageService
 	dhtService     *services.DHTService
+
+	// rekeyed is notified when a known node is re-keyed (see addNode)
+	rekeyed chan struct{}
 }
 
 func NewClient(
@@ -59,6 +93,8 @@ func NewClient(
 
 		storageService: storageService,
 		dhtService:     dhtService,
+
+		rekeyed: make(chan struct{}, 1),
 	}
 }
 
@@ -82,7 +118,9 @@ func (c *Client) Serve(notifyReady chan<- bool) error {
 		if err != nil {
 			return err
 		}
-		if err := c.AnnounceNewNode(dht.NewNode(addr)); err != nil {
+		node := dht.NewWeightedNode(addr, c.opts.Weight)
+		node.ID, node.Name, node.Zone = c.opts.ID, c.opts.Name, c.opts.Zone
+		if err := c.AnnounceNewNode(node); err != nil {
 			c.logger.Error("error occurred while announcing new node", slog.Any("error", err.Error()))
 			return err
 		}
@@ -91,22 +129,53 @@ func (c *Client) Serve(notifyReady chan<- bool) error {
 	notifyReady <- true
 
 	go func() {
-		c.healthcheckLoop()
+		for member := range c.opts.MemberChanges {
+			if err := c.handleMemberChange(member); err != nil {
+				c.logger.Error("error occurred while handling membership change", slog.Any("error", err.Error()))
+			}
+		}
 	}()
 
 	go func() {
-		for range c.opts.NotifyRebase {
-			if err := c.handleRebaseSignal(); err != nil {
-				c.logger.Error("error occurred while rebasing", slog.Any("error", err.Error()))
-			}
-		}
+		c.gossipLoop()
+	}()
+
+	go func() {
+		c.pullLoop()
+	}()
+
+	if c.opts.GCInterval > 0 {
+		go func() {
+			c.gcLoop()
+		}()
+	}
+
+	if c.opts.ScrubInterval > 0 {
+		go func() {
+			c.scrubLoop()
+		}()
+	}
+
+	if c.opts.MigrateHashes {
+		go func() {
+			c.migrateHashes()
+		}()
+	}
+
+	go func() {
+		c.rebaseLoop()
+	}()
+
+	go func() {
+		c.decommissionLoop()
 	}()
 
 	go func() {
 		for keyHashPair := range c.opts.ReplicationChan {
 			fmt.Println("received replication signal")
 			fmt.Println("key hash pair: ", keyHashPair)
-			if err := c.handleReplication(keyHashPair); err != nil {
+			err := c.retryStale(func() error { return c.handleReplication(keyHashPair) })
+			if err != nil {
 				c.logger.Error("error occurred while replication", slog.Any("error", err.Error()))
 			}
 		}
@@ -143,6 +212,11 @@ func (c *Client) newNodeRequest(node, targetNode *dht.Node) error {
 	nodeInfo := &gen.NodeInfo{
 		Address: node.Addr.String(),
 		Alive:   false,
+		Weight:  node.Weight,
+		Zone:    node.Zone,
+		Id:      node.ID,
+		Name:    node.Name,
+		State:   gen.NodeInfo_State(node.State),
 	}
 
 	_, err = client.AnnounceNewNode(ctx, nodeInfo)
@@ -153,72 +227,137 @@ func (c *Client) newNodeRequest(node, targetNode *dht.Node) error {
 	return nil
 }
 
-func (c *Client) handleRebaseSignal() error {
-	offset := 0
-	for {
-		keys, err := c.storageService.GetKeysByChunks(offset)
-		if err != nil {
-			return err
-		}
+// rebaseKeys copies the keys which should not be stored on the current node
+// to their nodes and removes the acknowledged ones. Returns the keys which
+// failed to be copied. An error is returned only if the ring epoch changed
+// since the start of the rebase, the local storage failed or the rebase
+// was cancelled, in which case the keys copied before are still removed.
+func (c *Client) rebaseKeys(ctx context.Context, keys []string, epoch dht.Epoch) ([]string, error) {
+	rebaseInfo, err := c.checkForRebase(keys)
+	if err != nil {
+		return nil, err
+	}
 
-		rebaseInfo, err := c.checkForRebase(keys)
-		if err != nil {
-			return err
-		}
+	acknowledged, copyErr := c.copyStorage(ctx, rebaseInfo)
+	if errors.Is(copyErr, ErrStaleEpoch) {
+		return nil, copyErr
+	}
 
-		if err := c.copyStorage(rebaseInfo); err != nil {
-			return err
-		}
+	if current := c.dhtService.Epoch(); !current.Same(epoch) {
+		return nil, fmt.Errorf("%w: ring epoch changed from %s to %s during rebase", ErrStaleEpoch, epoch, current)
+	}
+	if err := c.removeKeys(acknowledged); err != nil {
+		return nil, err
+	}
+	if copyErr != nil {
+		return nil, copyErr
+	}
 
-		if err = c.removeKeys(rebaseInfo); err != nil {
-			return err
+	failed := make([]string, 0)
+	for key := range rebaseInfo {
+		if _, ok := acknowledged[key]; !ok {
+			failed = append(failed, key)
 		}
+	}
+	return failed, nil
+}
 
-		if len(keys) < cas.DB_CHUNK_SIZE {
-			break
+// copyStorage copies the keys to their nodes and returns the keys which
+// all the nodes acknowledged. Keys which failed to be copied are skipped,
+// the copying is stopped only if the ring epoch of the current node is stale
+// or the rebase is cancelled, the keys acknowledged so far are returned then.
+func (c *Client) copyStorage(
+	ctx context.Context,
+	rebaseInfo map[string][]*dht.Node,
+) (map[string][]*dht.Node, error) {
+	acknowledged := make(map[string][]*dht.Node)
+	for key, nodes := range rebaseInfo {
+		if err := ctx.Err(); err != nil {
+			return acknowledged, err
+		}
+		sent, err := c.copyKey(key, nodes)
+		if errors.Is(err, ErrStaleEpoch) {
+			return nil, err
+		}
+		if err != nil {
+			c.logger.Warn("key is kept for retry", slog.String("key", key), slog.Any("error", err.Error()))
+			continue
 		}
-		offset += cas.DB_CHUNK_SIZE
+		acknowledged[key] = nodes
+		c.opts.Rebase.KeyMoved(sent)
 	}
-	return nil
+	return acknowledged, nil
 }
 
-func (c *Client) copyStorage(rebaseInfo map[string]*dht.Node) error {
-	for key, node := range rebaseInfo {
+// copyKey sends all the files of the key to every node.
+// Returns the number of sent bytes.
+func (c *Client) copyKey(key string, nodes []*dht.Node) (int64, error) {
+	for _, node := range nodes {
 		if err := c.rebaseHashesByKeyAndNode(key, node); err != nil {
-			return err
+			return 0, fmt.Errorf("node %s: %w", node, err)
 		}
 	}
-	return nil
+	size, err := c.keySize(key)
+	if err != nil {
+		return 0, err
+	}
+	return size * int64(len(nodes)), nil
 }
 
-func (c *Client) checkForRebase(keys []string) (map[string]*dht.Node, error) {
-	rebaseInfo := make(map[string]*dht.Node)
-	selfAddr := fmt.Sprintf(":%d", c.opts.Port)
+// checkForRebase returns keys which should not be stored on the current node
+// mapped to the nodes they should be copied to. A key stays on the node
+// while the node is in the preference list of the key (stores the key or its replica),
+// otherwise it's copied to the nodes of the key which take writes (see dht.NodeState).
+// Keys which nodes are not all available are kept until the next rebase,
+// so a single failed node doesn't abort the whole rebase.
+func (c *Client) checkForRebase(keys []string) (map[string][]*dht.Node, error) {
+	rebaseInfo := make(map[string][]*dht.Node)
 
 	for _, key := range keys {
-		node, err := c.dhtService.GetNodeForKey(key)
+		owners, err := c.dhtService.GetReplicaNodes(key, c.opts.ReplicationFactor)
 		if err != nil {
 			return nil, err
 		}
-		if !node.Alive {
-			return nil, fmt.Errorf("node %v is not alive", node)
-		}
 
 		c.logger.Debug("Checking key for rebase",
 			slog.String("key", key),
-			slog.String("self address", selfAddr),
-			slog.String("node address", node.Addr.String()),
+			slog.String("self id", c.opts.ID),
+			slog.String("node address", owners[0].Addr.String()),
 		)
 
-		if selfAddr == node.Addr.String() {
+		if slices.ContainsFunc(owners, c.isSelf) {
 			continue
 		}
-		rebaseInfo[key] = node
+		nodes, err := c.dhtService.GetWriteNodes(key, c.opts.ReplicationFactor)
+		if err != nil {
+			return nil, err
+		}
+		if i := slices.IndexFunc(nodes, func(node *dht.Node) bool { return !node.Alive }); i >= 0 {
+			c.logger.Warn("key is kept until the next rebase, node is not alive",
+				slog.String("key", key), slog.String("node", nodes[i].String()))
+			continue
+		}
+		rebaseInfo[key] = nodes
 	}
 
 	return rebaseInfo, nil
 }
 
+// isSelf returns true if the node is the current node
+func (c *Client) isSelf(node *dht.Node) bool {
+	if node.ID != "" {
+		return node.ID == c.opts.ID
+	}
+	return node.Addr.String() == fmt.Sprintf(":%d", c.opts.Port)
+}
+
+// selfState returns the lifecycle state of the current node
+func (c *Client) selfState() dht.NodeState {
+	// lifecycles are set only from dht node states, so they are always known
+	state, _ := dht.ParseNodeState(c.opts.Gossip.Self().Lifecycle)
+	return state
+}
+
 func (c *Client) rebaseHashesByKeyAndNode(key string, node *dht.Node) error {
 	conn, err := grpc.NewClient(node.Addr.String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
@@ -234,7 +373,7 @@ func (c *Client) rebaseHashesByKeyAndNode(key string, node *dht.Node) error {
 	}
 
 	for _, hash := range hashes {
-		if err := c.sendFile(client, key, hash); err != nil {
+		if err := c.sendFile(client, node, key, hash); err != nil {
 			return err
 		}
 	}
@@ -242,22 +381,19 @@ func (c *Client) rebaseHashesByKeyAndNode(key string, node *dht.Node) error {
 	return nil
 }
 
+// handleReplication sends the file to the nodes of the key which take writes
 func (c *Client) handleReplication(keyHashPair *cas.KeyHashPair) error {
-	getNextKey := nextKeyGenerator(keyHashPair.Key)
-	usedNodes := make(map[string]bool)
-	for range c.opts.ReplicationFactor {
-		key := getNextKey()
-		node, err := c.dhtService.GetNodeForKey(key)
-		if err != nil {
-			return err
-		}
-		if _, ok := usedNodes[node.Addr.String()]; ok {
+	nodes, err := c.dhtService.GetWriteNodes(keyHashPair.Key, c.opts.ReplicationFactor)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range nodes {
+		if c.isSelf(node) {
 			continue
 		}
 
-		usedNodes[node.Addr.String()] = true
-
-		fmt.Println("key:", key)
+		fmt.Println("key:", keyHashPair.Key)
 		fmt.Println("node:", node.Addr, node.Alive)
 
 		// костыль?
@@ -269,7 +405,7 @@ func (c *Client) handleReplication(keyHashPair *cas.KeyHashPair) error {
 			defer conn.Close()
 
 			client := gen.NewTransporterClient(conn)
-			if err = c.sendFile(client, key, keyHashPair.Hash); err != nil {
+			if err = c.sendFile(client, node, keyHashPair.Key, keyHashPair.Hash); err != nil {
 				return err
 			}
 
@@ -283,7 +419,18 @@ func (c *Client) handleReplication(keyHashPair *cas.KeyHashPair) error {
 	return nil
 }
 
+// sendFile sends the file stored under the key to the node. If the node rejects
+// the file because the ring epoch of the current node is stale, the nodes
+// of the node are pulled and ErrStaleEpoch is returned.
 func (c *Client) sendFile(
+	client gen.TransporterClient,
+	node *dht.Node,
+	key, hash string,
+) error {
+	return c.checkStaleEpoch(node, c.streamFile(client, key, hash))
+}
+
+func (c *Client) streamFile(
 	client gen.TransporterClient,
 	key, hash string,
 ) error {
@@ -302,18 +449,22 @@ func (c *Client) sendFile(
 		return err
 	}
 
-	initRequest := makeSendChunksInitRequestBody(key, hash)
+	initRequest := makeSendChunksInitRequestBody(key, hash, c.originInfo())
 	if err = stream.Send(initRequest); err != nil {
-		return err
+		return streamStatus(stream, err)
 	}
 
 	if err = streamFileByChunks(file, stream); err != nil {
-		return err
+		return streamStatus(stream, err)
 	}
 
-	if _, err = stream.CloseAndRecv(); err != nil {
+	reply, err := stream.CloseAndRecv()
+	if err != nil {
 		return err
 	}
+	if reply.GetContentHash() != hash {
+		return fmt.Errorf("%w: stored hash is '%s' instead of %s", ErrNotAcknowledged, reply.GetContentHash(), hash)
+	}
 
 	return nil
 }
@@ -347,7 +498,19 @@ func streamFileByChunks(
 	return nil
 }
 
-func makeSendChunksInitRequestBody(key, hash string) *gen.Chunk {
+// streamStatus returns the status of the stream if sending failed
+// because the server closed the stream (e.g. the file was rejected)
+func streamStatus(stream grpc.ClientStreamingClient[gen.Chunk, gen.StreamStatus], err error) error {
+	if err != io.EOF {
+		return err
+	}
+	if _, err := stream.CloseAndRecv(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+func makeSendChunksInitRequestBody(key, hash string, origin *gen.NodeInfo) *gen.Chunk {
 	return &gen.Chunk{
 		Data: &gen.Chunk_Meta{
 			Meta: &gen.Chunk_FileMetadata{
@@ -356,13 +519,23 @@ func makeSendChunksInitRequestBody(key, hash string) *gen.Chunk {
 				FilePath:    nil,
 				Compressed:  true,
 				Replicate:   false,
+				Origin:      origin,
 			},
 		},
 	}
 }
 
-func (c *Client) removeKeys(info map[string]*dht.Node) error {
+// removeKeys removes the copied keys from the current node, the keys must be
+// acknowledged by all of their nodes. Keys which are still read from the current
+// node are kept, since the nodes they were copied to are joining and don't serve
+// reads yet. They are removed by the next rebase after the nodes become active.
+func (c *Client) removeKeys(info map[string][]*dht.Node) error {
 	for key := range info {
+		readers, err := c.dhtService.GetReadNodes(key, c.opts.ReplicationFactor)
+		if err == nil && slices.ContainsFunc(readers, c.isSelf) {
+			c.logger.Debug("key is kept until joining nodes become active", slog.String("key", key))
+			continue
+		}
 		if err := c.storageService.RemoveByKey(key); err != nil {
 			return err
 		}
@@ -370,11 +543,52 @@ func (c *Client) removeKeys(info map[string]*dht.Node) error {
 	return nil
 }
 
+// LoadNodesFromSync adds the nodes known to the sync node to the DHT
+// and moves the ring epoch forward to the epoch of the sync node
 func (c *Client) LoadNodesFromSync(syncNode *dht.Node) error {
-	conn, err := grpc.NewClient(syncNode.Addr.String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	nodes, epoch, err := c.syncNodes(syncNode)
 	if err != nil {
 		return err
 	}
+
+	for _, node := range nodes {
+		if err := c.addNode(node); err != nil {
+			return err
+		}
+	}
+	if err := c.forgetNodes(nodes); err != nil {
+		return err
+	}
+	return c.dhtService.AdoptEpoch(epoch)
+}
+
+// addNode adds the node to the DHT. Nodes are placed by their keys (see dht.Node.Key),
+// so if the node changes the key of a known node (e.g. a node from the config,
+// which is known by its address, is identified), the keys of the node are placed
+// differently and a rebase is requested (see rebaseLoop).
+func (c *Client) addNode(node *dht.Node) error {
+	stored := c.dhtService.FindNode(node)
+	rekeyed := stored != nil && node.ID != "" && stored.Key() != node.Key()
+	if err := c.dhtService.AddNode(node); err != nil {
+		return err
+	}
+	if rekeyed {
+		c.logger.Info("node is re-keyed, rebase is requested",
+			slog.String("node", node.String()), slog.String("previous key", stored.Key()))
+		select {
+		case c.rekeyed <- struct{}{}:
+		default: // already requested
+		}
+	}
+	return nil
+}
+
+// syncNodes returns the nodes known to the node along with its ring epoch
+func (c *Client) syncNodes(syncNode *dht.Node) ([]*dht.Node, dht.Epoch, error) {
+	conn, err := grpc.NewClient(syncNode.Addr.String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, dht.Epoch{}, err
+	}
 	defer conn.Close()
 
 	client := gen.NewTransporterClient(conn)
@@ -383,100 +597,176 @@ func (c *Client) LoadNodesFromSync(syncNode *dht.Node) error {
 
 	stream, err := client.SyncNodes(ctx, &emptypb.Empty{})
 	if err != nil {
-		return err
+		return nil, dht.Epoch{}, err
 	}
 
-	nodes := make([]string, 0)
+	nodes := make([]*dht.Node, 0)
+	epoch := dht.Epoch{}
 	for {
 		nodeInfo, err := stream.Recv()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return err
+			return nil, dht.Epoch{}, err
 		}
 
-		nodes = append(nodes, nodeInfo.Address)
+		node, err := dht.ResolveNode(nodeInfo.GetAddress(), nodeInfo.GetWeight(), nodeInfo.GetZone())
+		if err != nil {
+			return nil, dht.Epoch{}, err
+		}
+		node.ID, node.Name = nodeInfo.GetId(), nodeInfo.GetName()
+		node.State = dht.NodeState(nodeInfo.GetState())
+		nodes = append(nodes, node)
+		epoch = dht.Epoch{Version: nodeInfo.GetEpoch(), Digest: nodeInfo.GetEpochDigest()}
 	}
-
-	return c.dhtService.LoadNodesFromList(nodes)
+	return nodes, epoch, nil
 }
 
-func (c *Client) healthcheckLoop() {
-	for range time.Tick(c.opts.CheckInterval) {
-		nodes := c.dhtService.GetNodes()
-		if len(nodes) == 0 {
+// forgetNodes removes the remembered nodes which are not in the synced nodes,
+// since they have left the cluster while the current node was down.
+// The current node is never removed.
+func (c *Client) forgetNodes(synced []*dht.Node) error {
+	for _, node := range c.opts.RememberedNodes {
+		if node.ID != "" && node.ID == c.opts.ID {
+			continue
+		}
+		if slices.ContainsFunc(synced, func(s *dht.Node) bool { return sameNode(node, s) }) {
 			continue
 		}
-		c.logger.Debug("starting HC dispatcher")
-		_ = c.checkHealthDispatcher(nodes) // returns channel
-		c.logger.Debug("done health checking")
+		c.logger.Info("forgetting node unknown to sync node", slog.String("node", node.String()))
+		c.opts.Gossip.Remove(gossip.Member{ID: node.ID, Address: node.Addr.String()})
+		if err := c.dhtService.RemoveNode(node); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func (c *Client) checkHealthDispatcher(nodes map[int]*dht.Node) <-chan *dht.Node {
-	jobs := make(chan *dht.Node, len(nodes))
-	result := make(chan *dht.Node, len(nodes))
-	for _, node := range nodes {
-		jobs <- node
+// sameNode returns true if the nodes have the same ID or the same address
+func sameNode(a, b *dht.Node) bool {
+	if a.ID != "" && a.ID == b.ID {
+		return true
 	}
-	close(jobs)
-
-	workerCount := calcWorkerCount(len(nodes))
-
-	var wg sync.WaitGroup
-	wg.Add(workerCount)
+	return a.Addr.String() == b.Addr.String()
+}
 
-	for i := 0; i < workerCount; i++ {
-		go c.checkHealthWorker(&wg, jobs, result)
+// gossipLoop runs the gossip protocol period every check interval.
+// Nodes added to the DHT in other ways (e.g. from the config or via AnnounceNewNode)
+// join the gossip membership before every period.
+func (c *Client) gossipLoop() {
+	for range time.Tick(c.opts.CheckInterval) {
+		members := make([]gossip.Member, 0)
+		for _, node := range c.dhtService.GetNodes() {
+			members = append(members, gossip.Member{
+				ID:      node.ID,
+				Name:    node.Name,
+				Address: node.Addr.String(),
+				Weight:  node.Weight,
+				Zone:    node.Zone,
+				// the nodes spread their lifecycles themselves, which override this one
+				Lifecycle: node.State.String(),
+			})
+		}
+		c.opts.Gossip.Join(members...)
+		c.opts.Gossip.Probe(context.Background())
+		c.updateHealth()
 	}
-
-	wg.Wait()
-	close(result)
-	return result
 }
 
-func (c *Client) checkHealthWorker(wg *sync.WaitGroup, jobs <-chan *dht.Node, result chan<- *dht.Node) {
-	defer wg.Done()
-
-	for node := range jobs {
-		err := c.makeHealthCheckRequest(node)
-		node.Alive = err == nil
-		result <- node
+// updateHealth copies the states of the failure detectors of the gossip members
+// to the nodes of the DHT, the nodes are replaced with their updated copies
+func (c *Client) updateHealth() {
+	for _, health := range c.opts.Gossip.Health() {
+		addr, err := net.ResolveTCPAddr("tcp", health.Address)
+		if err != nil {
+			continue
+		}
+		node := dht.NewNode(addr)
+		node.ID = health.ID
+		c.dhtService.SetNodeHealth(node, health.Suspicion, health.LastSeen, health.Flaps)
 	}
 }
 
-func (c *Client) makeHealthCheckRequest(node *dht.Node) error {
-	conn, err := grpc.NewClient(node.Addr.String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+// handleMemberChange applies the change of the gossip membership to the DHT.
+//
+// New members are added to the DHT, and members known only by their address are
+// replaced with the identified ones (see dht.Placement.AddNode). Dead members
+// are marked as not alive, but they are kept in the DHT, so their keys are not
+// moved because of temporary failures. Suspected members are still alive.
+// Lifecycle states spread by the members are applied to their nodes.
+func (c *Client) handleMemberChange(member gossip.Member) error {
+	node, err := dht.ResolveNode(member.Address, member.Weight, member.Zone)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
-
-	c.logger.Debug("requesting node health status", slog.String("address", node.Addr.String()))
-
-	client := gen.NewHealthCheckerClient(conn)
-	_, err = client.Healthcheck(context.Background(), &emptypb.Empty{})
+	node.ID, node.Name = member.ID, member.Name
+	node.Alive = member.State != gossip.StateDead
+	node.State, err = dht.ParseNodeState(member.Lifecycle)
 	if err != nil {
 		return err
 	}
+
+	stored := c.dhtService.FindNode(node)
+	// nodes which left the cluster (e.g. decommissioned or removed) are not added back
+	if stored == nil && (member.State == gossip.StateDead || !node.State.OwnsKeys() || c.opts.Gossip.IsRemoved(member)) {
+		return nil
+	}
+	identified := stored != nil && node.ID != "" && (stored.ID != node.ID || stored.Addr.String() != node.Addr.String())
+	if stored == nil || identified {
+		if err := c.addNode(node); err != nil {
+			return err
+		}
+		stored = c.dhtService.FindNode(node)
+	}
+	if stored != nil && stored.Alive != node.Alive {
+		c.dhtService.SetNodeAlive(stored, node.Alive)
+	}
+	// members which are not contacted yet have no lifecycle, their states are kept
+	if stored != nil && member.Lifecycle != "" {
+		previous, ok, err := c.dhtService.SetNodeState(stored, node.State)
+		if ok && previous != node.State {
+			c.logger.Info("node state changed",
+				slog.String("node", stored.String()),
+				slog.String("from", previous.String()), slog.String("to", node.State.String()))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	c.logger.Debug("membership changed",
+		slog.String("node", node.String()), slog.String("state", member.State.String()))
 	return nil
 }
 
-func calcWorkerCount(nodesCount int) int {
-	workerCount := nodesCount / workerRatio
-	if workerCount > maxWorkerCount {
-		return maxWorkerCount
-	} else if workerCount < minWorkerCount {
-		return minWorkerCount
+func (c *Client) gcLoop() {
+	opts := cas.GCOpts{GracePeriod: c.opts.GCGracePeriod}
+	for range time.Tick(c.opts.GCInterval) {
+		result, err := c.storageService.CollectGarbage(opts)
+		if err != nil {
+			c.logger.Error("error occurred while collecting garbage", slog.Any("error", err.Error()))
+			continue
+		}
+		c.logger.Debug("done collecting garbage",
+			slog.Int("scanned", result.Scanned),
+			slog.Int("removed", len(result.Removed)),
+			slog.Int64("freed", result.FreedBytes),
+		)
 	}
-	return workerCount
 }
 
-func nextKeyGenerator(key string) func() string {
-	nextKey := key
-	return func() string {
-		nextKey = nextKey + "_replica"
-		return nextKey
+func (c *Client) migrateHashes() {
+	result, err := c.storageService.MigrateHashes(cas.MigrateOpts{})
+	if err != nil {
+		c.logger.Error("error occurred while migrating hashes", slog.Any("error", err.Error()))
+		return
+	}
+	for _, hash := range result.Failed {
+		c.logger.Warn("can't migrate corrupted file", slog.String("hash", hash))
 	}
+	c.logger.Info("done migrating hashes",
+		slog.Int("scanned", result.Scanned),
+		slog.Int("migrated", len(result.Migrated)),
+	)
 }
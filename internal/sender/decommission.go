@@ -0,0 +1,225 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/internal/services"
+	"github.com/gfxv/go-stash/pkg/cas"
+	"github.com/gfxv/go-stash/pkg/dht"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// DECOMMISSION_SPREAD_PERIODS is the number of gossip protocol periods
+// a decommissioned node waits for its final state to spread before shutting down
+const DECOMMISSION_SPREAD_PERIODS = 5
+
+// decommissionLoop runs the decommissions requested via the Decommission RPC.
+// A decommission which was running when the node stopped is resumed right away
+func (c *Client) decommissionLoop() {
+	if c.opts.Decommission.Progress().State == services.DecommissionRunning {
+		c.logger.Info("resuming decommission", slog.String("after", c.opts.Decommission.Progress().LastKey))
+		c.runDecommission()
+	}
+	for range c.opts.NotifyDecommission {
+		c.runDecommission()
+	}
+}
+
+func (c *Client) runDecommission() {
+	if err := c.decommission(); err != nil {
+		c.logger.Error("error occurred while decommissioning", slog.Any("error", err.Error()))
+		if err := c.opts.Decommission.Fail(err); err != nil {
+			c.logger.Error("can't save decommission progress", slog.Any("error", err.Error()))
+		}
+		return
+	}
+	if err := c.opts.Decommission.Finish(); err != nil {
+		c.logger.Error("can't save decommission progress", slog.Any("error", err.Error()))
+	}
+	c.logger.Info("node is decommissioned, shutting down")
+	c.opts.Decommissioned <- true
+}
+
+// decommission moves all the keys stored on the current node to the nodes
+// which take writes of the keys, then the node leaves the cluster.
+// Keys are moved in lexicographical order starting after the last moved key,
+// so an interrupted decommission is resumed where it stopped.
+func (c *Client) decommission() error {
+	after := c.opts.Decommission.Progress().LastKey
+	for {
+		keys, err := c.storageService.ListKeys("", after, cas.DB_CHUNK_SIZE)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			verified, sent, err := c.moveKey(key)
+			if err != nil {
+				return fmt.Errorf("can't move key '%s': %w", key, err)
+			}
+			if err := c.opts.Decommission.KeyMoved(key, verified, sent); err != nil {
+				return err
+			}
+			after = key
+		}
+
+		if len(keys) < cas.DB_CHUNK_SIZE {
+			break
+		}
+	}
+	return c.leave()
+}
+
+// moveKey copies all the files of the key to the nodes which take writes of the key.
+// Returns the number of verified files and the number of sent bytes.
+// The key is kept on the current node, since the node stops serving it anyway.
+func (c *Client) moveKey(key string) (int, int64, error) {
+	nodes, err := c.dhtService.GetWriteNodes(key, c.opts.ReplicationFactor)
+	if err != nil {
+		return 0, 0, err
+	}
+	nodes = slices.DeleteFunc(nodes, c.isSelf)
+	if len(nodes) == 0 {
+		return 0, 0, fmt.Errorf("no nodes to move the key to")
+	}
+	if i := slices.IndexFunc(nodes, func(node *dht.Node) bool { return !node.Alive }); i >= 0 {
+		return 0, 0, fmt.Errorf("node %s is not alive", nodes[i])
+	}
+
+	hashes, err := c.storageService.GetHashesByKey(key)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	verified, sent := 0, int64(0)
+	for _, node := range nodes {
+		err := func() error {
+			conn, err := grpc.NewClient(node.Addr.String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			client := gen.NewTransporterClient(conn)
+			for _, hash := range hashes {
+				n, err := c.transferFile(client, node, key, hash)
+				if err != nil {
+					return err
+				}
+				verified++
+				sent += n
+			}
+			return nil
+		}()
+		if err != nil {
+			return 0, 0, fmt.Errorf("node %s: %w", node, err)
+		}
+	}
+	return verified, sent, nil
+}
+
+// transferFile sends the file to the node, unless the node already stores it
+// under the key, and verifies the file on the node afterwards.
+// Returns the number of sent bytes.
+func (c *Client) transferFile(client gen.TransporterClient, node *dht.Node, key, hash string) (int64, error) {
+	info, err := c.storageService.Stat(hash)
+	if err != nil {
+		return 0, err
+	}
+
+	if ok, err := verifyFile(client, key, info); err != nil || ok {
+		return 0, err
+	}
+	if err := c.sendFile(client, node, key, hash); err != nil {
+		return 0, err
+	}
+	ok, err := verifyFile(client, key, info)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("file %s is not stored after the transfer", hash)
+	}
+	return info.PackedSize, nil
+}
+
+// verifyFile returns true if the node stores the file under the key
+// and the size of the file is the same as the size of the local file
+func verifyFile(client gen.TransporterClient, key string, info *cas.BlobInfo) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	stat, err := client.Stat(ctx, &gen.StatRequest{Hash: info.Hash})
+	if err != nil {
+		return false, err
+	}
+	return stat.GetExists() &&
+		stat.GetCompressedSize() == uint64(info.PackedSize) &&
+		slices.Contains(stat.GetKeys(), key), nil
+}
+
+// leave moves the current node to the leaving state, announces its removal
+// to other nodes and moves it to the dead state. Other nodes which can't be
+// reached still learn that the node is dead by gossip.
+func (c *Client) leave() error {
+	if err := c.setSelfState(dht.NodeLeaving); err != nil {
+		return err
+	}
+
+	self := &gen.NodeInfo{Id: c.opts.ID, Name: c.opts.Name}
+	for _, node := range c.dhtService.GetNodes() {
+		if c.isSelf(node) {
+			continue
+		}
+		if err := c.removeNodeRequest(self, node); err != nil {
+			c.logger.Warn("can't announce removal of the node",
+				slog.String("target", node.String()), slog.Any("error", err.Error()))
+		}
+	}
+
+	if err := c.setSelfState(dht.NodeDead); err != nil {
+		return err
+	}
+	time.Sleep(DECOMMISSION_SPREAD_PERIODS * c.opts.CheckInterval)
+	return nil
+}
+
+func (c *Client) removeNodeRequest(node *gen.NodeInfo, targetNode *dht.Node) error {
+	conn, err := grpc.NewClient(targetNode.Addr.String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	client := gen.NewTransporterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	_, err = client.AnnounceRemoveNode(ctx, node)
+	return err
+}
+
+// setSelfState moves the current node to the state: the state is saved,
+// spread to other nodes by gossip and applied to the current node in the DHT
+func (c *Client) setSelfState(state dht.NodeState) error {
+	if err := c.dhtService.SaveSelfState(state); err != nil {
+		return err
+	}
+	c.opts.Gossip.SetLifecycle(state.String())
+
+	for _, node := range c.dhtService.GetNodes() {
+		if !c.isSelf(node) {
+			continue
+		}
+		if _, _, err := c.dhtService.SetNodeState(node, state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
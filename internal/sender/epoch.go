@@ -0,0 +1,104 @@
+package sender
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/pkg/dht"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrStaleEpoch is returned when the ring epoch of the current node changes
+// during an operation or a node rejects a file sent with an older epoch
+var ErrStaleEpoch = errors.New("ring epoch is stale")
+
+// pullLoop pulls the nodes of the nodes which have seen newer ring epochs
+// (see services.DHTService.ObserveEpoch)
+func (c *Client) pullLoop() {
+	for newer := range c.opts.NewerEpochs {
+		// the epoch may be adopted while the report was queued
+		if !newer.Epoch.Newer(c.dhtService.Epoch()) {
+			continue
+		}
+		c.logger.Info("newer ring epoch seen, pulling nodes",
+			slog.String("node", newer.Node.String()),
+			slog.String("epoch", newer.Epoch.String()), slog.String("current", c.dhtService.Epoch().String()))
+		if err := c.pullMembership(newer.Node); err != nil {
+			c.logger.Error("error occurred while pulling nodes", slog.Any("error", err.Error()))
+		}
+	}
+}
+
+// pullMembership adds the nodes known to the node which are unknown to the current
+// node, then moves the ring epoch forward to the epoch of the node.
+// Known nodes are kept as they are, since their lifecycle states are spread
+// by gossip, which orders changes of the states by incarnations.
+func (c *Client) pullMembership(node *dht.Node) error {
+	nodes, epoch, err := c.syncNodes(node)
+	if err != nil {
+		return err
+	}
+
+	for _, pulled := range nodes {
+		if c.dhtService.FindNode(pulled) != nil {
+			continue
+		}
+		// nodes which left the cluster are not added back
+		if !pulled.State.OwnsKeys() {
+			continue
+		}
+		if c.isSelf(pulled) {
+			pulled.State, pulled.Alive = c.selfState(), true
+		}
+		c.logger.Info("pulled unknown node", slog.String("node", pulled.String()))
+		if err := c.addNode(pulled); err != nil {
+			return err
+		}
+	}
+	return c.dhtService.AdoptEpoch(epoch)
+}
+
+// checkStaleEpoch returns ErrStaleEpoch if the node rejected a file because
+// the ring epoch of the current node is older than the epoch of the node.
+// The nodes of the node are pulled before returning, so the operation
+// can be retried with the new ring. Other errors are returned as they are.
+func (c *Client) checkStaleEpoch(node *dht.Node, err error) error {
+	if status.Code(err) != codes.Aborted {
+		return err
+	}
+	if pullErr := c.pullMembership(node); pullErr != nil {
+		c.logger.Error("error occurred while pulling nodes", slog.Any("error", pullErr.Error()))
+	}
+	return fmt.Errorf("%w: %v", ErrStaleEpoch, err)
+}
+
+// retryStale calls fn once again if it failed because of a stale ring epoch,
+// by then the current node has the new ring
+func (c *Client) retryStale(fn func() error) error {
+	err := fn()
+	if errors.Is(err, ErrStaleEpoch) {
+		c.logger.Warn("retrying with the new ring", slog.Any("error", err.Error()))
+		return fn()
+	}
+	return err
+}
+
+// originInfo returns the current node as the origin of the files it sends,
+// with the current ring epoch
+func (c *Client) originInfo() *gen.NodeInfo {
+	epoch := c.dhtService.Epoch()
+	return &gen.NodeInfo{
+		Address:     fmt.Sprintf(":%d", c.opts.Port),
+		Alive:       true,
+		Weight:      c.opts.Weight,
+		Zone:        c.opts.Zone,
+		Id:          c.opts.ID,
+		Name:        c.opts.Name,
+		State:       gen.NodeInfo_State(c.selfState()),
+		Epoch:       epoch.Version,
+		EpochDigest: epoch.Digest,
+	}
+}
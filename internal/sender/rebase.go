@@ -0,0 +1,230 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gfxv/go-stash/internal/services"
+	"github.com/gfxv/go-stash/pkg/cas"
+)
+
+// REBASE_RESUME_PERIODS is the number of gossip protocol periods a restarted node
+// waits before resuming an interrupted rebase job, so other nodes are heard from
+// first and their keys are not kept as if the nodes were not alive
+const REBASE_RESUME_PERIODS = 5
+
+// rebaseLoop runs the rebase jobs requested via the Rebase RPC.
+// A job which was running when the node stopped is resumed once
+// other nodes are heard from (see REBASE_RESUME_PERIODS).
+// A rebase is also started when a known node is re-keyed (see addNode),
+// after waiting the same number of periods, so the other nodes are identified
+// as well and a single rebase moves all the remapped keys
+func (c *Client) rebaseLoop() {
+	if job, _, err := c.opts.Rebase.Job(""); err == nil && !job.Finished() {
+		c.logger.Info("resuming rebase", slog.String("job", job.ID), slog.String("after", job.LastKey))
+		time.Sleep(REBASE_RESUME_PERIODS * c.opts.CheckInterval)
+		c.runRebase()
+	}
+	for {
+		select {
+		case <-c.opts.NotifyRebase:
+		case <-c.rekeyed:
+			time.Sleep(REBASE_RESUME_PERIODS * c.opts.CheckInterval)
+			if !c.startRekeyRebase() {
+				continue
+			}
+		}
+		c.runRebase()
+	}
+}
+
+// startRekeyRebase creates a rebase job for the keys remapped by re-keyed nodes.
+// It returns false if there is no job to run
+func (c *Client) startRekeyRebase() bool {
+	job, err := c.opts.Rebase.Start(false, false)
+	if errors.Is(err, services.ErrRebaseRunning) {
+		// the pending job moves the remapped keys as well
+		return true
+	}
+	if err != nil {
+		c.logger.Error("can't start rebase of re-keyed nodes", slog.Any("error", err.Error()))
+		return false
+	}
+	c.logger.Info("rebase of re-keyed nodes requested", slog.String("job", job.ID))
+	return true
+}
+
+// runRebase runs the pending or interrupted rebase job, if there is one
+func (c *Client) runRebase() {
+	ctx, job, err := c.opts.Rebase.Begin()
+	if errors.Is(err, services.ErrRebaseNotFound) {
+		return
+	}
+	if err != nil {
+		c.logger.Error("can't start rebase", slog.Any("error", err.Error()))
+		return
+	}
+	c.logger.Info("rebase started",
+		slog.String("job", job.ID), slog.Bool("dry run", job.DryRun), slog.String("after", job.LastKey))
+
+	err = c.retryStale(func() error { return c.rebase(ctx) })
+	switch {
+	case err == nil:
+		c.logger.Info("rebase finished", slog.String("job", job.ID))
+	case errors.Is(err, context.Canceled):
+		c.logger.Info("rebase cancelled", slog.String("job", job.ID))
+	default:
+		c.logger.Error("error occurred while rebasing", slog.String("job", job.ID), slog.Any("error", err.Error()))
+	}
+	if err := c.opts.Rebase.Finish(err); err != nil {
+		c.logger.Error("can't save rebase job", slog.String("job", job.ID), slog.Any("error", err.Error()))
+	}
+}
+
+// rebase plans the running job, unless it's resumed after its plan was made,
+// then copies the keys which should not be stored on the current node
+// to their nodes and removes them once every node acknowledged every file of the key.
+// Keys are rebased in lexicographical order, the checkpoint is saved after
+// every chunk of keys, so an interrupted job continues where it stopped.
+// Keys which failed to be copied are kept on the current node and retried after
+// the other keys, the ones which fail again are kept until the next rebase.
+// If the ring epoch changes during the rebase, the rebase is stopped before
+// removing keys, since they may be copied to wrong nodes.
+func (c *Client) rebase(ctx context.Context) error {
+	job, _, err := c.opts.Rebase.Job("")
+	if err != nil {
+		return err
+	}
+	epoch := c.dhtService.Epoch()
+
+	if job.LastKey == "" {
+		plan, keys, err := c.planRebase(ctx)
+		if err != nil {
+			return err
+		}
+		if err := c.opts.Rebase.SetPlan(plan, keys); err != nil {
+			return err
+		}
+		c.logger.Info("rebase planned", slog.String("job", job.ID), slog.Int("keys", keys))
+	}
+	if job.DryRun {
+		return nil
+	}
+
+	failed := make([]string, 0)
+	// keys are listed after the last listed key, since removed keys shift offsets
+	after := job.LastKey
+	for {
+		keys, err := c.storageService.ListKeys("", after, cas.DB_CHUNK_SIZE)
+		if err != nil {
+			return err
+		}
+
+		keptKeys, err := c.rebaseKeys(ctx, keys, epoch)
+		if err != nil {
+			return err
+		}
+		failed = append(failed, keptKeys...)
+
+		if len(keys) == 0 {
+			break
+		}
+		after = keys[len(keys)-1]
+		if err := c.opts.Rebase.Checkpoint(after); err != nil {
+			return err
+		}
+		if len(keys) < cas.DB_CHUNK_SIZE {
+			break
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+	c.logger.Info("retrying keys which failed to be rebased", slog.Int("count", len(failed)))
+	failed, err = c.rebaseKeys(ctx, failed, epoch)
+	if err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		c.opts.Rebase.KeysFailed(len(failed))
+		c.logger.Warn("keys are kept until the next rebase, they are not acknowledged by their nodes",
+			slog.Int("count", len(failed)))
+	}
+	return nil
+}
+
+// planRebase returns the number of keys and bytes which should be copied
+// to every node, along with the number of distinct keys to move.
+// Keys are checked the same way they are checked by the rebase (see checkForRebase).
+func (c *Client) planRebase(ctx context.Context) ([]cas.RebaseTarget, int, error) {
+	targets := make(map[string]*cas.RebaseTarget)
+	planned := 0
+	after := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		keys, err := c.storageService.ListKeys("", after, cas.DB_CHUNK_SIZE)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		rebaseInfo, err := c.checkForRebase(keys)
+		if err != nil {
+			return nil, 0, err
+		}
+		for key, nodes := range rebaseInfo {
+			size, err := c.keySize(key)
+			if err != nil {
+				return nil, 0, err
+			}
+			planned++
+			for _, node := range nodes {
+				address := node.Addr.String()
+				target, ok := targets[address]
+				if !ok {
+					target = &cas.RebaseTarget{Address: address, Name: node.Name}
+					targets[address] = target
+				}
+				target.Keys++
+				target.Bytes += size
+			}
+		}
+
+		if len(keys) < cas.DB_CHUNK_SIZE {
+			break
+		}
+		after = keys[len(keys)-1]
+	}
+
+	plan := make([]cas.RebaseTarget, 0, len(targets))
+	for _, target := range targets {
+		plan = append(plan, *target)
+	}
+	slices.SortFunc(plan, func(a, b cas.RebaseTarget) int {
+		return strings.Compare(a.Address, b.Address)
+	})
+	return plan, planned, nil
+}
+
+// keySize returns the number of bytes sent to a node to copy the key
+func (c *Client) keySize(key string) (int64, error) {
+	hashes, err := c.storageService.GetHashesByKey(key)
+	if err != nil {
+		return 0, err
+	}
+	size := int64(0)
+	for _, hash := range hashes {
+		info, err := c.storageService.Stat(hash)
+		if err != nil {
+			return 0, err
+		}
+		size += info.PackedSize
+	}
+	return size, nil
+}
@@ -0,0 +1,237 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/internal/services"
+	"github.com/gfxv/go-stash/pkg/cas"
+	"github.com/gfxv/go-stash/pkg/dht"
+	"github.com/gfxv/go-stash/pkg/gossip"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// fakeNode is a node which keeps the received files in memory
+type fakeNode struct {
+	gen.UnimplementedTransporterServer
+	node   *dht.Node
+	client gen.TransporterClient
+
+	mu sync.Mutex
+	// reply returns the content hash replied for the received file
+	reply func(hash string) string
+	// files maps hashes of the received files to their keys
+	files map[string][]string
+}
+
+// startFakeNode starts a node listening on a local port, the node replies
+// with the hashes of the received files
+func startFakeNode(t *testing.T, id string) *fakeNode {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	f := &fakeNode{
+		node:  dht.NewNode(listener.Addr()),
+		reply: func(hash string) string { return hash },
+		files: make(map[string][]string),
+	}
+	f.node.ID = id
+	f.node.Alive = true
+
+	server := grpc.NewServer()
+	gen.RegisterTransporterServer(server, f)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(f.node.Addr.String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	f.client = gen.NewTransporterClient(conn)
+	return f
+}
+
+// setReply changes how the node replies to the received files
+func (f *fakeNode) setReply(reply func(hash string) string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.reply = reply
+}
+
+// keys returns the keys of the received files
+func (f *fakeNode) keys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	keys := make([]string, 0)
+	for _, fileKeys := range f.files {
+		keys = append(keys, fileKeys...)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func (f *fakeNode) SendChunks(stream grpc.ClientStreamingServer[gen.Chunk, gen.StreamStatus]) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	key, hash := first.GetMeta().GetKey(), first.GetMeta().GetContentHash()
+	for {
+		if _, err := stream.Recv(); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !slices.Contains(f.files[hash], key) {
+		f.files[hash] = append(f.files[hash], key)
+	}
+	return stream.SendAndClose(&gen.StreamStatus{ContentHash: f.reply(hash)})
+}
+
+// testClient returns a client of a node which is not in the DHT of the given nodes,
+// so all of its keys are rebased to them. A rebase job is running.
+func testClient(t *testing.T, nodes ...*dht.Node) (*Client, *cas.Storage) {
+	storage, err := cas.NewDefaultStorage(cas.StorageOpts{
+		BaseDir:       t.TempDir(),
+		HashAlgorithm: cas.SHA256,
+		Pack:          cas.ZLibPack,
+		Unpack:        cas.ZLibUnpack,
+		PackWriter:    cas.ZLibPackWriter,
+		UnpackReader:  cas.ZLibUnpackReader,
+		UnpackFrame:   cas.ZLibUnpackFrame,
+	})
+	assert.NoError(t, err)
+	storageService := services.NewStorageService(storage)
+
+	rebase, err := services.NewRebaseService(storageService)
+	assert.NoError(t, err)
+	_, err = rebase.Start(false, false)
+	assert.NoError(t, err)
+	_, _, err = rebase.Begin()
+	assert.NoError(t, err)
+
+	ring := dht.NewHashRingWithVNodes(16)
+	ring.AddNode(nodes...)
+	self := gossip.Member{ID: "self", Lifecycle: dht.NodeActive.String()}
+	opts := &SenderOpts{
+		ID:     self.ID,
+		Gossip: gossip.New(self, nil, gossip.Config{}),
+		Rebase: rebase,
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	return NewClient(opts, storageService, services.NewDHTService(ring, "", dht.Epoch{}, nil)), storage
+}
+
+// putKeys stores count keys and returns them
+func putKeys(t *testing.T, storage *cas.Storage, count int) []string {
+	keys := make([]string, 0, count)
+	for i := range count {
+		key := fmt.Sprintf("key-%d", i)
+		hash, err := storage.WriteFromRawData(cas.PrepareRawFile(key, []byte("data of "+key)))
+		assert.NoError(t, err)
+		assert.NoError(t, storage.AddNewPath(key, hash))
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// storedKeys returns the keys stored on the node of the client
+func storedKeys(t *testing.T, c *Client) []string {
+	keys, err := c.storageService.ListKeys("", "", 1000)
+	assert.NoError(t, err)
+	slices.Sort(keys)
+	return keys
+}
+
+func TestStreamFileAcknowledged(t *testing.T) {
+	f := startFakeNode(t, "node-0")
+	c, storage := testClient(t, f.node)
+	key := putKeys(t, storage, 1)[0]
+	hashes, err := c.storageService.GetHashesByKey(key)
+	assert.NoError(t, err)
+	hash := hashes[0]
+
+	assert.NoError(t, c.streamFile(f.client, key, hash))
+
+	f.setReply(func(string) string { return "sha256:other" })
+	assert.ErrorIs(t, c.streamFile(f.client, key, hash), ErrNotAcknowledged)
+
+	// the file is sent again later, if the node doesn't reply with its hash
+	f.setReply(func(string) string { return "" })
+	assert.ErrorIs(t, c.streamFile(f.client, key, hash), ErrNotAcknowledged)
+}
+
+func TestRebaseKeysRetry(t *testing.T) {
+	first, second, failing := startFakeNode(t, "node-0"), startFakeNode(t, "node-1"), startFakeNode(t, "node-2")
+	failing.setReply(func(string) string { return "" })
+	c, storage := testClient(t, first.node, second.node, failing.node)
+	keys := putKeys(t, storage, 30)
+
+	owned := make(map[string][]string)
+	for _, key := range keys {
+		nodes, err := c.dhtService.GetWriteNodes(key, c.opts.ReplicationFactor)
+		assert.NoError(t, err)
+		owned[nodes[0].ID] = append(owned[nodes[0].ID], key)
+	}
+	assert.Len(t, owned, 3)
+
+	failed, err := c.rebaseKeys(context.Background(), keys, c.dhtService.Epoch())
+	assert.NoError(t, err)
+	slices.Sort(failed)
+	assert.Equal(t, owned["node-2"], failed)
+	// only the acknowledged keys are removed
+	assert.Equal(t, owned["node-2"], storedKeys(t, c))
+	assert.Equal(t, owned["node-0"], first.keys())
+	assert.Equal(t, owned["node-1"], second.keys())
+
+	// failed keys are copied by the next rebase
+	failing.setReply(func(hash string) string { return hash })
+	failed, err = c.rebaseKeys(context.Background(), failed, c.dhtService.Epoch())
+	assert.NoError(t, err)
+	assert.Empty(t, failed)
+	assert.Empty(t, storedKeys(t, c))
+	assert.Equal(t, owned["node-2"], failing.keys())
+}
+
+func TestRebaseKeysStaleEpoch(t *testing.T) {
+	f := startFakeNode(t, "node-0")
+	c, storage := testClient(t, f.node)
+	keys := putKeys(t, storage, 5)
+	epoch := c.dhtService.Epoch()
+
+	// the ring changed since the start of the rebase, copied keys are kept
+	assert.NoError(t, c.dhtService.AddNode(startFakeNode(t, "node-1").node))
+	_, err := c.rebaseKeys(context.Background(), keys, epoch)
+	assert.ErrorIs(t, err, ErrStaleEpoch)
+	assert.Equal(t, keys, storedKeys(t, c))
+}
+
+func TestCopyStorageCancelled(t *testing.T) {
+	f := startFakeNode(t, "node-0")
+	c, storage := testClient(t, f.node)
+	keys := putKeys(t, storage, 5)
+	info, err := c.checkForRebase(keys)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	acknowledged, err := c.copyStorage(ctx, info)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, acknowledged)
+	assert.Empty(t, f.keys())
+}
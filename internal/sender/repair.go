@@ -0,0 +1,136 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"time"
+
+	gen "github.com/gfxv/go-stash/api"
+	"github.com/gfxv/go-stash/pkg/cas"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func (c *Client) scrubLoop() {
+	opts := cas.ScrubOpts{
+		BytesPerSecond: c.opts.ScrubRate,
+		Repair:         c.fetchHealthyCopy,
+	}
+	for range time.Tick(c.opts.ScrubInterval) {
+		report, err := c.storageService.Scrub(opts)
+		if err != nil {
+			c.logger.Error("error occurred while scrubbing", slog.Any("error", err.Error()))
+			continue
+		}
+		for _, issue := range report.Issues {
+			c.logger.Warn("corrupted file found",
+				slog.String("hash", issue.Hash),
+				slog.String("reason", issue.Reason),
+				slog.Bool("repaired", issue.Repaired),
+			)
+		}
+		c.logger.Debug("done scrubbing", slog.Int("scanned", report.Scanned))
+	}
+}
+
+// fetchHealthyCopy requests the packed file with the given hash from the
+// replicas of the keys referencing it, which serve reads. Returns a reader of the first copy found
+func (c *Client) fetchHealthyCopy(hash string) (io.ReadCloser, error) {
+	keys, err := c.storageService.GetKeysByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	tried := make(map[string]bool)
+	for _, key := range keys {
+		nodes, err := c.dhtService.GetReadNodes(key, c.opts.ReplicationFactor)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, node := range nodes {
+			addr := node.Addr.String()
+			if c.isSelf(node) || tried[addr] || !node.Alive {
+				continue
+			}
+			tried[addr] = true
+
+			reader, err := receiveFile(addr, hash)
+			if err != nil {
+				c.logger.Debug("can't receive file from replica",
+					slog.String("address", addr), slog.Any("error", err.Error()))
+				continue
+			}
+			return reader, nil
+		}
+	}
+
+	return nil, errors.New("no replica has a copy of the file")
+}
+
+// receiveFile opens a stream of the packed file with the given hash from
+// the node. The first chunk is received right away, so a missing file
+// is reported by receiveFile itself
+func receiveFile(addr string, hash string) (io.ReadCloser, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := gen.NewTransporterClient(conn).ReceiveChunks(ctx, &gen.ReceiveChunkRequest{
+		Hash:              hash,
+		NeedDecompression: false,
+	})
+	if err != nil {
+		cancel()
+		conn.Close()
+		return nil, err
+	}
+
+	reader := &receivedFileReader{stream: stream, cancel: cancel, conn: conn}
+	if reader.chunk, err = reader.recv(); err != nil && err != io.EOF {
+		reader.Close()
+		return nil, err
+	}
+	reader.err = err
+	return reader, nil
+}
+
+// receivedFileReader adapts a stream of received chunks to io.ReadCloser
+type receivedFileReader struct {
+	stream gen.Transporter_ReceiveChunksClient
+	cancel context.CancelFunc
+	conn   *grpc.ClientConn
+
+	chunk []byte
+	err   error
+}
+
+func (r *receivedFileReader) recv() ([]byte, error) {
+	response, err := r.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return response.GetData(), nil
+}
+
+func (r *receivedFileReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.chunk, r.err = r.recv()
+	}
+
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}
+
+func (r *receivedFileReader) Close() error {
+	r.cancel()
+	return r.conn.Close()
+}
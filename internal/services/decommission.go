@@ -0,0 +1,158 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DECOMMISSION_FILE is the name of the file storing the progress of the decommission
+const DECOMMISSION_FILE = "decommission.json"
+
+var (
+	// ErrDecommissionRunning is returned when a decommission is started while it's running
+	ErrDecommissionRunning = errors.New("decommission is already running")
+	// ErrDecommissionDone is returned when a decommission is started after it's done
+	ErrDecommissionDone = errors.New("decommission is already done")
+)
+
+// DecommissionState is the state of the decommission of the current node
+type DecommissionState string
+
+const (
+	DecommissionNotStarted DecommissionState = ""
+	DecommissionRunning    DecommissionState = "running"
+	DecommissionFailed     DecommissionState = "failed"
+	DecommissionDone       DecommissionState = "done"
+)
+
+// DecommissionProgress is the progress of the decommission of the current node
+type DecommissionProgress struct {
+	State      DecommissionState `json:"state"`
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt time.Time         `json:"finished_at"`
+	// LastKey is the last moved key. Keys are moved in lexicographical order,
+	// so a resumed decommission continues right after it
+	LastKey   string `json:"last_key"`
+	MovedKeys int    `json:"moved_keys"`
+	// VerifiedFiles is the number of files found on the new nodes after the transfer
+	VerifiedFiles int    `json:"verified_files"`
+	SentBytes     int64  `json:"sent_bytes"`
+	Error         string `json:"error,omitempty"`
+}
+
+// DecommissionService keeps track of the decommission of the current node.
+//
+// The progress is saved to DECOMMISSION_FILE after every moved key,
+// so the decommission is resumed after a failure or a restart.
+type DecommissionService struct {
+	mu       sync.Mutex
+	dir      string
+	progress DecommissionProgress
+}
+
+// NewDecommissionService creates a new instance of DecommissionService
+// with the progress saved in dir. If there is no saved progress,
+// the decommission is not started.
+func NewDecommissionService(dir string) (*DecommissionService, error) {
+	const op = "services.decommission.NewDecommissionService"
+
+	s := &DecommissionService{dir: dir}
+	data, err := os.ReadFile(filepath.Join(dir, DECOMMISSION_FILE))
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := json.Unmarshal(data, &s.progress); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return s, nil
+}
+
+// Progress returns a copy of the current progress
+func (s *DecommissionService) Progress() DecommissionProgress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.progress
+}
+
+// Start marks the decommission as running. A failed decommission is resumed
+// with its progress kept. An error is returned if the decommission
+// is already running or done.
+func (s *DecommissionService) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch s.progress.State {
+	case DecommissionRunning:
+		return ErrDecommissionRunning
+	case DecommissionDone:
+		return ErrDecommissionDone
+	case DecommissionNotStarted:
+		s.progress.StartedAt = time.Now()
+	}
+	s.progress.State = DecommissionRunning
+	s.progress.FinishedAt = time.Time{}
+	s.progress.Error = ""
+	return s.save()
+}
+
+// KeyMoved records the key as moved along with the number of verified files
+// and the number of bytes sent to the new nodes of the key
+func (s *DecommissionService) KeyMoved(key string, verifiedFiles int, sentBytes int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.progress.LastKey = key
+	s.progress.MovedKeys++
+	s.progress.VerifiedFiles += verifiedFiles
+	s.progress.SentBytes += sentBytes
+	return s.save()
+}
+
+// Fail marks the decommission as failed with the given reason
+func (s *DecommissionService) Fail(reason error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.progress.State = DecommissionFailed
+	s.progress.FinishedAt = time.Now()
+	s.progress.Error = reason.Error()
+	return s.save()
+}
+
+// Finish marks the decommission as done
+func (s *DecommissionService) Finish() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.progress.State = DecommissionDone
+	s.progress.FinishedAt = time.Now()
+	return s.save()
+}
+
+// save replaces the saved progress atomically, the lock must be held
+func (s *DecommissionService) save() error {
+	const op = "services.decommission.save"
+
+	data, err := json.MarshalIndent(s.progress, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	path := filepath.Join(s.dir, DECOMMISSION_FILE)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
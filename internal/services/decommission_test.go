@@ -0,0 +1,68 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecommissionNotStarted(t *testing.T) {
+	s, err := NewDecommissionService(t.TempDir())
+	assert.NoError(t, err)
+	assert.Equal(t, DecommissionNotStarted, s.Progress().State)
+}
+
+func TestDecommissionResumeAfterFail(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewDecommissionService(dir)
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Start())
+	startedAt := s.Progress().StartedAt
+	assert.NoError(t, s.KeyMoved("key-1", 2, 100))
+	assert.NoError(t, s.KeyMoved("key-2", 1, 50))
+	assert.NoError(t, s.Fail(errors.New("node is unreachable")))
+
+	// the progress is kept after a restart
+	s, err = NewDecommissionService(dir)
+	assert.NoError(t, err)
+	progress := s.Progress()
+	assert.Equal(t, DecommissionFailed, progress.State)
+	assert.Equal(t, "node is unreachable", progress.Error)
+	assert.False(t, progress.FinishedAt.IsZero())
+
+	// the failed decommission is resumed where it stopped
+	assert.NoError(t, s.Start())
+	progress = s.Progress()
+	assert.Equal(t, DecommissionRunning, progress.State)
+	assert.Equal(t, "key-2", progress.LastKey)
+	assert.Equal(t, 2, progress.MovedKeys)
+	assert.Equal(t, 3, progress.VerifiedFiles)
+	assert.Equal(t, int64(150), progress.SentBytes)
+	assert.True(t, startedAt.Equal(progress.StartedAt))
+	assert.True(t, progress.FinishedAt.IsZero())
+	assert.Empty(t, progress.Error)
+}
+
+func TestDecommissionStartErrors(t *testing.T) {
+	s, err := NewDecommissionService(t.TempDir())
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Start())
+	assert.ErrorIs(t, s.Start(), ErrDecommissionRunning)
+
+	assert.NoError(t, s.Finish())
+	assert.ErrorIs(t, s.Start(), ErrDecommissionDone)
+	assert.Equal(t, DecommissionDone, s.Progress().State)
+}
+
+func TestDecommissionCorruptProgress(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, DECOMMISSION_FILE), []byte("{\"state\": "), 0644))
+
+	_, err := NewDecommissionService(dir)
+	assert.Error(t, err)
+}
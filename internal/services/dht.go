@@ -1,19 +1,58 @@
 package services
 
 import (
+	"slices"
+	"sync"
+	"time"
+
 	"github.com/gfxv/go-stash/pkg/dht"
-	"net"
 )
 
-// DHTService struct encapsulates a hash ring, which is responsible for managing
-// the distribution of nodes within the DHT.
+// DHTService struct encapsulates a placement strategy (e.g. a hash ring), which is
+// responsible for managing the distribution of keys between nodes within the DHT.
 type DHTService struct {
-	ring *dht.HashRing
+	ring dht.Placement
+
+	// membersDir is the directory where the nodes of the ring are saved
+	// (see dht.SaveMembers), empty if the nodes are kept in memory only
+	membersDir string
+	// saveMu orders saves of the nodes, so the last save has the latest nodes
+	saveMu sync.Mutex
+
+	// epochMu guards epoch
+	epochMu sync.Mutex
+	// epoch is the ring epoch, its version grows with every change
+	// of the digest of the nodes of the ring (see refreshEpoch)
+	epoch dht.Epoch
+	// newerEpochs receives the nodes which have seen a newer ring epoch,
+	// so the current node pulls their nodes
+	newerEpochs chan<- NewerEpoch
+}
+
+// NewerEpoch is a ring epoch of another node which is newer than
+// the ring epoch of the current node
+type NewerEpoch struct {
+	Node  *dht.Node
+	Epoch dht.Epoch
 }
 
 // NewDHTService creates a new instance of DHTService.
-func NewDHTService(ring *dht.HashRing) *DHTService {
-	return &DHTService{ring: ring}
+//
+// If membersDir is not empty, the nodes of the ring and the ring epoch are saved
+// to it after every change, so they can be loaded after a restart with
+// dht.LoadMembers and dht.LoadRingEpoch. If the nodes of the ring differ from
+// the ones of the saved epoch (e.g. the config changed their weights), the version
+// of the epoch grows. Newer epochs seen on other nodes are sent to newerEpochs
+// (see ObserveEpoch), which can be nil.
+func NewDHTService(ring dht.Placement, membersDir string, epoch dht.Epoch, newerEpochs chan<- NewerEpoch) *DHTService {
+	s := &DHTService{ring: ring, membersDir: membersDir, epoch: epoch, newerEpochs: newerEpochs}
+	if digest := dht.MembershipDigest(ring.GetNodes()); digest != epoch.Digest {
+		if epoch.Digest != 0 { // saved without the digest otherwise
+			s.epoch.Version++
+		}
+		s.epoch.Digest = digest
+	}
+	return s
 }
 
 // GetNodesAddr retrieves the addresses of all nodes in the DHT ring.
@@ -28,40 +67,112 @@ func (s *DHTService) GetNodesAddr() []string {
 	return nodes
 }
 
-// LoadNodesFromList adds a list of nodes to the DHT ring from a slice of address strings.
-//
-// This method takes a slice of node addresses as strings, resolves each address
-// to a TCP address, and adds the corresponding nodes to the DHT hash ring.
-// If any address fails to resolve, the method returns an error immediately,
-// and no nodes are added to the ring for that entry.
-func (s *DHTService) LoadNodesFromList(nodes []string) error {
-	for _, node := range nodes {
-		addr, err := net.ResolveTCPAddr("tcp", node)
-		if err != nil {
-			return err
-		}
-		// AddNode safe for concurrent modification
-		s.ring.AddNode(dht.NewNode(addr))
-	}
-	return nil
-}
-
 // AddNode adds a single node to the DHT ring.
 //
 // This method takes a pointer to a `dht.Node` and adds it to the DHT hash ring.
 // The addition is safe for concurrent modifications, allowing multiple goroutines
-// to add nodes without causing race conditions.
-func (s *DHTService) AddNode(node *dht.Node) {
+// to add nodes without causing race conditions. The node is kept in the ring
+// even if saving the nodes fails, in which case an error is returned.
+func (s *DHTService) AddNode(node *dht.Node) error {
 	s.ring.AddNode(node)
+	s.refreshEpoch()
+	return s.SaveMembers()
 }
 
 // RemoveNode removes a specified node from the DHT ring.
 //
 // This method takes a pointer to a `dht.Node` and removes it from the DHT hash ring.
 // The removal is safe for concurrent modifications, allowing multiple goroutines
-// to remove nodes without causing race conditions.
-func (s *DHTService) RemoveNode(node *dht.Node) {
+// to remove nodes without causing race conditions. The node is removed from the ring
+// even if saving the nodes fails, in which case an error is returned.
+func (s *DHTService) RemoveNode(node *dht.Node) error {
 	s.ring.RemoveNode(node)
+	s.refreshEpoch()
+	return s.SaveMembers()
+}
+
+// SaveMembers saves the current nodes of the DHT ring and the ring epoch, so they
+// are known after a restart. It does nothing if the service was created without membersDir.
+func (s *DHTService) SaveMembers() error {
+	if s.membersDir == "" {
+		return nil
+	}
+
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	nodes := make([]*dht.Node, 0)
+	for _, node := range s.ring.GetNodes() {
+		nodes = append(nodes, node)
+	}
+	if err := dht.SaveMembers(s.membersDir, nodes); err != nil {
+		return err
+	}
+	return dht.SaveRingEpoch(s.membersDir, s.Epoch())
+}
+
+// Epoch returns the ring epoch of the current node.
+//
+// The version of the epoch grows with every change of the nodes of the DHT ring
+// which changes their placement, so nodes with older epochs may place keys on wrong nodes.
+func (s *DHTService) Epoch() dht.Epoch {
+	s.epochMu.Lock()
+	defer s.epochMu.Unlock()
+
+	return s.epoch
+}
+
+// refreshEpoch updates the digest of the ring epoch after a change of the nodes.
+// The version grows only if the digest is changed, so changes which don't change
+// placement (e.g. adding a node which is already added) keep the epoch.
+func (s *DHTService) refreshEpoch() {
+	s.epochMu.Lock()
+	defer s.epochMu.Unlock()
+
+	digest := dht.MembershipDigest(s.ring.GetNodes())
+	if digest == s.epoch.Digest {
+		return
+	}
+	s.epoch.Version++
+	s.epoch.Digest = digest
+}
+
+// AdoptEpoch moves the version of the ring epoch forward to the version of
+// the given epoch after the nodes of a node with this epoch are pulled.
+// Lower versions are ignored.
+func (s *DHTService) AdoptEpoch(epoch dht.Epoch) error {
+	s.epochMu.Lock()
+	if epoch.Version <= s.epoch.Version {
+		s.epochMu.Unlock()
+		return nil
+	}
+	s.epoch.Version = epoch.Version
+	s.epochMu.Unlock()
+
+	return s.SaveMembers()
+}
+
+// ObserveEpoch reports the ring epoch seen on the node. If the epoch is newer than
+// the ring epoch of the current node (see dht.Epoch.Newer), the node is sent to pull
+// its nodes. Nothing is sent if the previous reports are not handled yet, since
+// the epoch is seen again with the next message of the node.
+func (s *DHTService) ObserveEpoch(node *dht.Node, epoch dht.Epoch) {
+	if s.newerEpochs == nil || !epoch.Newer(s.Epoch()) {
+		return
+	}
+	select {
+	case s.newerEpochs <- NewerEpoch{Node: node, Epoch: epoch}:
+	default:
+	}
+}
+
+// FindNode retrieves the node of the DHT ring which is the same as the given one.
+//
+// Nodes are matched by their IDs (see dht.Node.Key) first, and by their
+// addresses otherwise, the same way as nodes are added to the ring (see dht.MatchNode).
+// If there is no such node in the DHT ring, the method returns nil.
+func (s *DHTService) FindNode(node *dht.Node) *dht.Node {
+	return dht.MatchNode(s.ring.GetNodes(), node)
 }
 
 // GetNodeForKey retrieves the node responsible for a given key in the DHT ring.
@@ -73,6 +184,109 @@ func (s *DHTService) GetNodeForKey(key string) (*dht.Node, error) {
 	return s.ring.GetNodeForKey(key)
 }
 
+// GetReplicaNodes retrieves the node responsible for a given key followed by
+// the nodes storing its replicas.
+//
+// The nodes are the preference list of the key (see dht.Placement.GetNodesForKey),
+// so the result contains replicationFactor + 1 distinct nodes, unless there are
+// fewer nodes in the DHT. If the hash ring is empty, the method will return an error.
+func (s *DHTService) GetReplicaNodes(key string, replicationFactor int) ([]*dht.Node, error) {
+	return s.ring.GetNodesForKey(key, replicationFactor+1)
+}
+
+// GetReadNodes retrieves the preference list of a given key placed only
+// on the nodes serving reads (see dht.NodeState.ServesReads).
+//
+// Joining nodes are skipped until they receive the data of their keys, so reads
+// go to the nodes which stored the key before. If there are no such nodes,
+// the method will return an error.
+func (s *DHTService) GetReadNodes(key string, replicationFactor int) ([]*dht.Node, error) {
+	return s.ring.FilterNodesForKey(key, replicationFactor+1, dht.ServesReads)
+}
+
+// GetWriteNodes retrieves the preference list of a given key placed only
+// on the nodes taking writes (see dht.NodeState.TakesWrites).
+//
+// Draining nodes are skipped, so new writes of their keys go to the next nodes.
+// If there are no such nodes, the method will return an error.
+func (s *DHTService) GetWriteNodes(key string, replicationFactor int) ([]*dht.Node, error) {
+	return s.ring.FilterNodesForKey(key, replicationFactor+1, dht.TakesWrites)
+}
+
+// GetStoringNodes retrieves the nodes which may store copies of a given key.
+//
+// These are the nodes of the preference list of the key (see GetReplicaNodes),
+// followed by the nodes serving reads of the key which are not on the list,
+// since they keep the key until joining nodes become active.
+// If the hash ring is empty, the method will return an error.
+func (s *DHTService) GetStoringNodes(key string, replicationFactor int) ([]*dht.Node, error) {
+	nodes, err := s.GetReplicaNodes(key, replicationFactor)
+	if err != nil {
+		return nil, err
+	}
+	readers, err := s.GetReadNodes(key, replicationFactor)
+	if err != nil {
+		return nodes, nil
+	}
+	for _, reader := range readers {
+		if !slices.Contains(nodes, reader) {
+			nodes = append(nodes, reader)
+		}
+	}
+	return nodes, nil
+}
+
+// SetNodeState changes the lifecycle state of the given node of the DHT ring
+// and returns the previous state of the node.
+//
+// Nodes are matched by their IDs or addresses. The previous state is read
+// along with the change, under the lock of the placement, and the ring epoch
+// changes only if the state is changed. The state is changed even if saving
+// the nodes fails, in which case an error is returned. If there is no such
+// node in the DHT ring, the method returns false.
+func (s *DHTService) SetNodeState(node *dht.Node, state dht.NodeState) (dht.NodeState, bool, error) {
+	stored := s.FindNode(node)
+	if stored == nil {
+		return 0, false, nil
+	}
+	previous, ok := s.ring.SetNodeState(stored.Key(), state)
+	if !ok {
+		return 0, false, nil
+	}
+	if previous == state {
+		return previous, true, nil
+	}
+	s.refreshEpoch()
+	return previous, true, s.SaveMembers()
+}
+
+// SetNodeAlive marks the given node of the DHT ring as alive or not.
+//
+// Nodes are matched by their IDs or addresses. Liveness is not saved, so the ring
+// epoch is not changed. If there is no such node in the DHT ring, the method returns false.
+func (s *DHTService) SetNodeAlive(node *dht.Node, alive bool) bool {
+	return s.ring.SetNodeAlive(node.Key(), alive)
+}
+
+// SetNodeHealth sets the state of the failure detector of the given node
+// of the DHT ring (see dht.Node.Suspicion).
+//
+// Nodes are matched by their IDs or addresses. The health is not saved, so the ring
+// epoch is not changed. If there is no such node in the DHT ring, the method returns false.
+func (s *DHTService) SetNodeHealth(node *dht.Node, suspicion float64, lastSeen time.Time, flaps int) bool {
+	return s.ring.SetNodeHealth(node.Key(), suspicion, lastSeen, flaps)
+}
+
+// SaveSelfState saves the lifecycle state of the current node, so it's kept
+// after a restart (see dht.LoadNodeState). It does nothing if the service
+// was created without membersDir.
+func (s *DHTService) SaveSelfState(state dht.NodeState) error {
+	if s.membersDir == "" {
+		return nil
+	}
+	return dht.SaveNodeState(s.membersDir, state)
+}
+
 // NodeExists checks if a node responsible for a given key exists in the DHT ring.
 //
 // This method takes a string key as input and returns a boolean indicating whether
@@ -90,3 +304,14 @@ func (s *DHTService) NodeExists(key string) bool {
 func (s *DHTService) GetNodes() map[int]*dht.Node {
 	return s.ring.GetNodes()
 }
+
+// CountZones returns the number of distinct zones of the nodes in the DHT ring.
+//
+// Every node without zone is counted as a separate zone (see dht.CountZones).
+func (s *DHTService) CountZones() int {
+	nodes := make([]*dht.Node, 0)
+	for _, node := range s.ring.GetNodes() {
+		nodes = append(nodes, node)
+	}
+	return dht.CountZones(nodes)
+}
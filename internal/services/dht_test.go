@@ -0,0 +1,126 @@
+package services
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/gfxv/go-stash/pkg/dht"
+	"github.com/stretchr/testify/assert"
+)
+
+// testNodes returns count nodes with distinct IDs and addresses
+func testNodes(t *testing.T, count int) []*dht.Node {
+	nodes := make([]*dht.Node, 0, count)
+	for i := range count {
+		addr, err := net.ResolveTCPAddr("tcp", fmt.Sprintf("10.0.0.%d:5555", i+1))
+		assert.NoError(t, err)
+		node := dht.NewNode(addr)
+		node.ID = fmt.Sprintf("node-%d", i)
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+func TestEpochKeptWithoutChanges(t *testing.T) {
+	nodes := testNodes(t, 3)
+	ring := dht.NewHashRing()
+	ring.AddNode(nodes...)
+	s := NewDHTService(ring, t.TempDir(), dht.Epoch{}, nil)
+	epoch := s.Epoch()
+
+	// adding nodes which are already added doesn't change placement
+	for _, node := range nodes {
+		copied := *node
+		assert.NoError(t, s.AddNode(&copied))
+	}
+	_, ok, err := s.SetNodeState(nodes[0], dht.NodeActive)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.True(t, s.SetNodeAlive(nodes[0], true))
+	assert.Equal(t, epoch, s.Epoch())
+
+	_, ok, err = s.SetNodeState(nodes[0], dht.NodeDraining)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, epoch.Version+1, s.Epoch().Version)
+	assert.NotEqual(t, epoch.Digest, s.Epoch().Digest)
+
+	// the nodes are back to the same view, but the change is kept in the version
+	_, _, err = s.SetNodeState(nodes[0], dht.NodeActive)
+	assert.NoError(t, err)
+	assert.Equal(t, epoch.Version+2, s.Epoch().Version)
+	assert.True(t, s.Epoch().Same(epoch))
+
+	assert.NoError(t, s.RemoveNode(nodes[2]))
+	assert.Equal(t, epoch.Version+3, s.Epoch().Version)
+}
+
+func TestEpochAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	nodes := testNodes(t, 3)
+	ring := dht.NewHashRing()
+	ring.AddNode(nodes...)
+	s := NewDHTService(ring, dir, dht.Epoch{Version: 4}, nil)
+	assert.NoError(t, s.SaveMembers())
+	saved := s.Epoch()
+	// epochs saved without the digest keep their version
+	assert.Equal(t, uint64(4), saved.Version)
+
+	// the same nodes keep the saved epoch
+	epoch, err := dht.LoadRingEpoch(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, saved, epoch)
+	ring = dht.NewHashRing()
+	ring.AddNode(nodes...)
+	assert.Equal(t, saved, NewDHTService(ring, dir, epoch, nil).Epoch())
+
+	// nodes changed while the node was down (e.g. in the config) move the epoch forward
+	ring = dht.NewHashRing()
+	ring.AddNode(nodes[:2]...)
+	s = NewDHTService(ring, dir, epoch, nil)
+	assert.Equal(t, saved.Version+1, s.Epoch().Version)
+	assert.False(t, s.Epoch().Same(saved))
+}
+
+func TestAdoptEpoch(t *testing.T) {
+	dir := t.TempDir()
+	ring := dht.NewHashRing()
+	ring.AddNode(testNodes(t, 2)...)
+	s := NewDHTService(ring, dir, dht.Epoch{}, nil)
+	assert.NoError(t, s.SaveMembers())
+	digest := s.Epoch().Digest
+
+	assert.NoError(t, s.AdoptEpoch(dht.Epoch{Version: 7, Digest: 1}))
+	assert.Equal(t, dht.Epoch{Version: 7, Digest: digest}, s.Epoch())
+	// lower versions are ignored
+	assert.NoError(t, s.AdoptEpoch(dht.Epoch{Version: 3, Digest: 1}))
+	assert.Equal(t, dht.Epoch{Version: 7, Digest: digest}, s.Epoch())
+
+	epoch, err := dht.LoadRingEpoch(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, s.Epoch(), epoch)
+}
+
+func TestObserveEpoch(t *testing.T) {
+	ring := dht.NewHashRing()
+	nodes := testNodes(t, 2)
+	ring.AddNode(nodes...)
+	newer := make(chan NewerEpoch, 1)
+	s := NewDHTService(ring, "", dht.Epoch{Version: 5}, newer)
+	current := s.Epoch()
+
+	// the same view and older epochs are not pulled
+	s.ObserveEpoch(nodes[1], dht.Epoch{Version: 9, Digest: current.Digest})
+	s.ObserveEpoch(nodes[1], dht.Epoch{Version: 4, Digest: 1})
+	s.ObserveEpoch(nodes[1], dht.Epoch{Version: 5, Digest: 1})
+	assert.Len(t, newer, 0)
+
+	s.ObserveEpoch(nodes[1], dht.Epoch{Version: 6, Digest: 1})
+	assert.Equal(t, NewerEpoch{Node: nodes[1], Epoch: dht.Epoch{Version: 6, Digest: 1}}, <-newer)
+
+	// reports are dropped until the queued ones are handled
+	s.ObserveEpoch(nodes[1], dht.Epoch{Version: 6, Digest: 1})
+	s.ObserveEpoch(nodes[0], dht.Epoch{Version: 7, Digest: 1})
+	assert.Len(t, newer, 1)
+}
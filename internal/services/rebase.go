@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gfxv/go-stash/pkg/cas"
+)
+
+var (
+	// ErrRebaseRunning is returned when a rebase is started while another one is running
+	ErrRebaseRunning = errors.New("rebase is already running")
+	// ErrRebaseNotFound is returned when there is no rebase job with the given ID
+	ErrRebaseNotFound = errors.New("rebase job is not found")
+	// ErrRebaseFinished is returned when a finished rebase job is cancelled
+	ErrRebaseFinished = errors.New("rebase job is already finished")
+	// ErrRebaseNotResumable is returned when the last rebase job can't be resumed,
+	// only failed and cancelled jobs which move keys are resumed
+	ErrRebaseNotResumable = errors.New("rebase job can't be resumed")
+)
+
+// RebaseService keeps track of the rebase jobs of the current node.
+//
+// Only one job runs at a time. The checkpoint of the job is saved to the database
+// (see cas.RebaseJob), so a job interrupted by a restart is resumed, and a failed
+// or cancelled job can be resumed on request. Watchers are notified about every
+// change of the job, including the progress which is not saved yet.
+type RebaseService struct {
+	mu      sync.Mutex
+	storage *StorageService
+	// job is the last rebase job, nil if there were none
+	job *cas.RebaseJob
+	// cancel cancels the context of the running job
+	cancel context.CancelFunc
+	// changed is closed and replaced on every change of the job
+	changed chan struct{}
+}
+
+// NewRebaseService creates a new instance of RebaseService
+// with the last rebase job loaded from the storage
+func NewRebaseService(storage *StorageService) (*RebaseService, error) {
+	const op = "services.rebase.NewRebaseService"
+
+	job, found, err := storage.GetRebaseJob("")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	s := &RebaseService{storage: storage, changed: make(chan struct{})}
+	if found {
+		s.job = job
+	}
+	return s, nil
+}
+
+// Start creates a pending rebase job, which is run by the sender.
+// If resume is set, the last failed or cancelled job is continued from its
+// checkpoint instead. An error is returned if the last job is not finished yet.
+func (s *RebaseService) Start(dryRun, resume bool) (cas.RebaseJob, error) {
+	const op = "services.rebase.Start"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.job != nil && !s.job.Finished() {
+		return cas.RebaseJob{}, ErrRebaseRunning
+	}
+
+	if resume {
+		if s.job == nil || s.job.DryRun || s.job.State == cas.RebaseDone {
+			return cas.RebaseJob{}, ErrRebaseNotResumable
+		}
+		s.job.State = cas.RebasePending
+		s.job.FinishedAt = time.Time{}
+		s.job.Error = ""
+	} else {
+		id, err := newJobID()
+		if err != nil {
+			return cas.RebaseJob{}, fmt.Errorf("%s: %w", op, err)
+		}
+		s.job = &cas.RebaseJob{ID: id, State: cas.RebasePending, DryRun: dryRun, Plan: make([]cas.RebaseTarget, 0)}
+	}
+
+	if err := s.save(); err != nil {
+		return cas.RebaseJob{}, err
+	}
+	return s.copyJob(), nil
+}
+
+// Begin marks the last job as running and returns it along with the context
+// which is cancelled by Cancel. Jobs which were running when the node stopped
+// are resumed this way. ErrRebaseNotFound is returned if there is no pending
+// or running job.
+func (s *RebaseService) Begin() (context.Context, cas.RebaseJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.job == nil || s.job.Finished() {
+		return nil, cas.RebaseJob{}, ErrRebaseNotFound
+	}
+	if s.job.StartedAt.IsZero() {
+		s.job.StartedAt = time.Now()
+	}
+	s.job.State = cas.RebaseRunning
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := s.save(); err != nil {
+		cancel()
+		return nil, cas.RebaseJob{}, err
+	}
+	s.cancel = cancel
+	return ctx, s.copyJob(), nil
+}
+
+// SetPlan saves the plan of the running job along with the number of distinct
+// keys to move. Planned bytes are the sum over the targets of the plan.
+func (s *RebaseService) SetPlan(plan []cas.RebaseTarget, keys int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.job.Plan = plan
+	s.job.PlannedKeys, s.job.PlannedBytes = keys, 0
+	for _, target := range plan {
+		s.job.PlannedBytes += target.Bytes
+	}
+	return s.save()
+}
+
+// KeyMoved records the key as moved along with the number of bytes sent
+// to its nodes. The progress is saved with the next checkpoint.
+func (s *RebaseService) KeyMoved(sentBytes int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.job.MovedKeys++
+	s.job.MovedBytes += sentBytes
+	s.notify()
+}
+
+// KeysFailed records the number of keys which are kept on the current node,
+// since their nodes didn't acknowledge them
+func (s *RebaseService) KeysFailed(count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.job.FailedKeys += count
+	s.notify()
+}
+
+// Checkpoint saves the progress of the running job, a resumed job
+// continues right after the given key
+func (s *RebaseService) Checkpoint(lastKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.job.LastKey = lastKey
+	return s.save()
+}
+
+// Finish marks the running job as done. If the job stopped with an error,
+// it's marked as cancelled if it was cancelled, otherwise as failed.
+func (s *RebaseService) Finish(reason error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case reason == nil:
+		s.job.State = cas.RebaseDone
+	case errors.Is(reason, context.Canceled):
+		s.job.State = cas.RebaseCancelled
+	default:
+		s.job.State = cas.RebaseFailed
+		s.job.Error = reason.Error()
+	}
+	s.job.FinishedAt = time.Now()
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	return s.save()
+}
+
+// Cancel stops the job with the given ID, or the last job if the ID is empty.
+// A pending job is cancelled right away, a running one is cancelled
+// once it stops (see Finish). Returns the job after the cancellation.
+func (s *RebaseService) Cancel(id string) (cas.RebaseJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.job == nil || (id != "" && id != s.job.ID) {
+		return cas.RebaseJob{}, ErrRebaseNotFound
+	}
+	if s.job.Finished() {
+		return cas.RebaseJob{}, ErrRebaseFinished
+	}
+
+	if s.job.State == cas.RebasePending {
+		s.job.State = cas.RebaseCancelled
+		s.job.FinishedAt = time.Now()
+		if err := s.save(); err != nil {
+			return cas.RebaseJob{}, err
+		}
+	}
+	if s.cancel != nil {
+		s.cancel()
+	}
+	return s.copyJob(), nil
+}
+
+// Job returns the job with the given ID, or the last job if the ID is empty,
+// along with a channel which is closed on the next change of the last job.
+// Jobs other than the last one are loaded from the storage, they don't change.
+func (s *RebaseService) Job(id string) (cas.RebaseJob, <-chan struct{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.job != nil && (id == "" || id == s.job.ID) {
+		return s.copyJob(), s.changed, nil
+	}
+
+	job, found, err := s.storage.GetRebaseJob(id)
+	if err != nil {
+		return cas.RebaseJob{}, nil, err
+	}
+	if !found {
+		return cas.RebaseJob{}, nil, ErrRebaseNotFound
+	}
+	return *job, s.changed, nil
+}
+
+// save saves the checkpoint of the job and notifies the watchers,
+// the lock must be held
+func (s *RebaseService) save() error {
+	s.notify()
+	return s.storage.SaveRebaseJob(s.job)
+}
+
+// notify wakes up the watchers of the job, the lock must be held
+func (s *RebaseService) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// copyJob returns a copy of the job, which is safe to use without the lock,
+// the lock must be held
+func (s *RebaseService) copyJob() cas.RebaseJob {
+	job := *s.job
+	job.Plan = slices.Clone(s.job.Plan)
+	return job
+}
+
+// newJobID generates a random ID of a rebase job
+func newJobID() (string, error) {
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id[:]), nil
+}
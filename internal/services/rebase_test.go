@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gfxv/go-stash/pkg/cas"
+	"github.com/stretchr/testify/assert"
+)
+
+// testStorage returns a storage in a temporary directory
+func testStorage(t *testing.T) *StorageService {
+	storage, err := cas.NewDefaultStorage(cas.StorageOpts{
+		BaseDir:       t.TempDir(),
+		HashAlgorithm: cas.SHA256,
+		Pack:          cas.ZLibPack,
+		Unpack:        cas.ZLibUnpack,
+		PackWriter:    cas.ZLibPackWriter,
+		UnpackReader:  cas.ZLibUnpackReader,
+		UnpackFrame:   cas.ZLibUnpackFrame,
+	})
+	assert.NoError(t, err)
+	return NewStorageService(storage)
+}
+
+// finishedJob runs a new job until it's finished with the given reason
+func finishedJob(t *testing.T, s *RebaseService, dryRun bool, reason error) cas.RebaseJob {
+	_, err := s.Start(dryRun, false)
+	assert.NoError(t, err)
+	_, _, err = s.Begin()
+	assert.NoError(t, err)
+	assert.NoError(t, s.Finish(reason))
+	job, _, err := s.Job("")
+	assert.NoError(t, err)
+	return job
+}
+
+func TestRebaseStartRunning(t *testing.T) {
+	s, err := NewRebaseService(testStorage(t))
+	assert.NoError(t, err)
+
+	job, err := s.Start(false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, cas.RebasePending, job.State)
+	_, err = s.Start(false, false)
+	assert.ErrorIs(t, err, ErrRebaseRunning)
+
+	_, _, err = s.Begin()
+	assert.NoError(t, err)
+	_, err = s.Start(true, false)
+	assert.ErrorIs(t, err, ErrRebaseRunning)
+	_, err = s.Start(false, true)
+	assert.ErrorIs(t, err, ErrRebaseRunning)
+
+	assert.NoError(t, s.Finish(nil))
+	next, err := s.Start(false, false)
+	assert.NoError(t, err)
+	assert.NotEqual(t, job.ID, next.ID)
+}
+
+func TestRebaseResume(t *testing.T) {
+	storage := testStorage(t)
+	s, err := NewRebaseService(storage)
+	assert.NoError(t, err)
+
+	_, err = s.Start(false, true)
+	assert.ErrorIs(t, err, ErrRebaseNotResumable)
+
+	finishedJob(t, s, true, errors.New("node is unreachable"))
+	_, err = s.Start(false, true)
+	assert.ErrorIs(t, err, ErrRebaseNotResumable)
+
+	finishedJob(t, s, false, nil)
+	_, err = s.Start(false, true)
+	assert.ErrorIs(t, err, ErrRebaseNotResumable)
+
+	_, err = s.Start(false, false)
+	assert.NoError(t, err)
+	_, _, err = s.Begin()
+	assert.NoError(t, err)
+	s.KeyMoved(100)
+	assert.NoError(t, s.Checkpoint("key-1"))
+	assert.NoError(t, s.Finish(errors.New("node is unreachable")))
+	failed, _, err := s.Job("")
+	assert.NoError(t, err)
+
+	// the failed job is resumed from its checkpoint after a restart
+	s, err = NewRebaseService(storage)
+	assert.NoError(t, err)
+	job, err := s.Start(false, true)
+	assert.NoError(t, err)
+	assert.Equal(t, failed.ID, job.ID)
+	assert.Equal(t, cas.RebasePending, job.State)
+	assert.Equal(t, "key-1", job.LastKey)
+	assert.Equal(t, 1, job.MovedKeys)
+	assert.Empty(t, job.Error)
+	assert.True(t, job.FinishedAt.IsZero())
+}
+
+func TestRebaseCancelPending(t *testing.T) {
+	s, err := NewRebaseService(testStorage(t))
+	assert.NoError(t, err)
+
+	_, err = s.Cancel("")
+	assert.ErrorIs(t, err, ErrRebaseNotFound)
+
+	job, err := s.Start(false, false)
+	assert.NoError(t, err)
+	_, err = s.Cancel("other")
+	assert.ErrorIs(t, err, ErrRebaseNotFound)
+
+	job, err = s.Cancel(job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, cas.RebaseCancelled, job.State)
+	assert.False(t, job.FinishedAt.IsZero())
+
+	// the cancelled job is not run
+	_, _, err = s.Begin()
+	assert.ErrorIs(t, err, ErrRebaseNotFound)
+	_, err = s.Cancel("")
+	assert.ErrorIs(t, err, ErrRebaseFinished)
+}
+
+func TestRebaseCancelRunning(t *testing.T) {
+	s, err := NewRebaseService(testStorage(t))
+	assert.NoError(t, err)
+	_, err = s.Start(false, false)
+	assert.NoError(t, err)
+	ctx, _, err := s.Begin()
+	assert.NoError(t, err)
+
+	// the running job is cancelled once it stops
+	job, err := s.Cancel("")
+	assert.NoError(t, err)
+	assert.Equal(t, cas.RebaseRunning, job.State)
+	<-ctx.Done()
+
+	assert.NoError(t, s.Finish(fmt.Errorf("copying keys: %w", ctx.Err())))
+	job, _, err = s.Job("")
+	assert.NoError(t, err)
+	assert.Equal(t, cas.RebaseCancelled, job.State)
+	assert.Empty(t, job.Error)
+}
+
+func TestRebaseFinish(t *testing.T) {
+	s, err := NewRebaseService(testStorage(t))
+	assert.NoError(t, err)
+
+	job := finishedJob(t, s, false, nil)
+	assert.Equal(t, cas.RebaseDone, job.State)
+	assert.False(t, job.FinishedAt.IsZero())
+
+	job = finishedJob(t, s, false, context.Canceled)
+	assert.Equal(t, cas.RebaseCancelled, job.State)
+	assert.Empty(t, job.Error)
+
+	job = finishedJob(t, s, false, errors.New("node is unreachable"))
+	assert.Equal(t, cas.RebaseFailed, job.State)
+	assert.Equal(t, "node is unreachable", job.Error)
+}
+
+func TestRebaseJobChanged(t *testing.T) {
+	s, err := NewRebaseService(testStorage(t))
+	assert.NoError(t, err)
+	_, _, err = s.Job("")
+	assert.ErrorIs(t, err, ErrRebaseNotFound)
+
+	first := finishedJob(t, s, false, nil)
+	_, err = s.Start(false, false)
+	assert.NoError(t, err)
+	_, _, err = s.Begin()
+	assert.NoError(t, err)
+
+	_, changed, err := s.Job("")
+	assert.NoError(t, err)
+	select {
+	case <-changed:
+		t.Fatal("job is not changed yet")
+	default:
+	}
+
+	// progress which is not saved yet wakes up the watchers as well
+	s.KeyMoved(100)
+	<-changed
+	job, changed, err := s.Job("")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, job.MovedKeys)
+	assert.Equal(t, int64(100), job.MovedBytes)
+
+	s.KeysFailed(2)
+	<-changed
+
+	// jobs other than the last one are loaded from the storage
+	job, _, err = s.Job(first.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID, job.ID)
+	assert.Equal(t, cas.RebaseDone, job.State)
+	_, _, err = s.Job("unknown")
+	assert.ErrorIs(t, err, ErrRebaseNotFound)
+}
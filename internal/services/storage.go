@@ -1,9 +1,11 @@
 package services
 
 import (
+	"errors"
 	"github.com/gfxv/go-stash/pkg/cas"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"io"
 )
 
 type StorageService struct {
@@ -17,18 +19,18 @@ func NewStorageService(storage *cas.Storage) *StorageService {
 // SaveCompressed stores compressed data in the storage and associates it
 // with the specified key and content hash.
 //
-// This method prepares the necessary parent directories for the storage path
-// derived from the provided content hash. Then it writes the compressed data
-// to the determined path in the storage. After successfully writing the
-// data, it also records the key and its associated content hash in the database.
+// This method streams the compressed data from the provided reader to the
+// storage path derived from the content hash, without buffering it in memory.
+// The data is stored only if its unpacked content matches the content hash,
+// otherwise an error with codes.DataLoss is returned.
+// After successfully writing the data, it also records the key and its
+// associated content hash in the database.
 // Returns nil if the operation is successful; otherwise, it returns an error indicating the cause of failure
-func (s *StorageService) SaveCompressed(key string, contentHash string, data []byte) error {
-	contentPath := s.storage.MakePathFromHash(contentHash)
-	err := s.storage.PrepareParentFolders(contentPath)
-	if err != nil {
-		return status.Errorf(codes.Internal, "can't prepare parent folders: %v", err)
+func (s *StorageService) SaveCompressed(key string, contentHash string, data io.Reader) error {
+	err := s.storage.WriteCompressedVerified(contentHash, data)
+	if errors.Is(err, cas.ErrCorrupted) {
+		return status.Errorf(codes.DataLoss, "received file doesn't match its hash: %v", err)
 	}
-	err = s.storage.Write(contentPath, data)
 	if err != nil {
 		return status.Errorf(codes.Internal, "can't store file file to storage: %v", err)
 	}
@@ -44,15 +46,14 @@ func (s *StorageService) SaveCompressed(key string, contentHash string, data []b
 
 // SaveRaw stores raw data in the storage and associates it with the specified key.
 //
-// This method prepares the raw file data by adding a special header
-// Then writes data to the storage, obtaining a content hash in the process.
-// After successfully storing the data, it records the key and its
-// associated content hash in the database.
+// This method prepends a special header (see cas.PrepareRawFile) to the data
+// read from the provided reader, then streams it to the storage, obtaining
+// a content hash in the process. After successfully storing the data, it records
+// the key and its associated content hash in the database.
 // Returns the content hash of the stored data if successful;
 // otherwise, it returns an error indicating the cause of the failure
-func (s *StorageService) SaveRaw(key string, file *cas.File) (string, error) {
-	data := cas.PrepareRawFile(file.Path, file.Data)
-	contentHash, err := s.storage.WriteFromRawData(data)
+func (s *StorageService) SaveRaw(key string, path string, data io.Reader) (string, error) {
+	contentHash, err := s.storage.WriteFromReader(cas.RawFileReader(path, data))
 	if err != nil {
 		return "", err
 	}
@@ -77,25 +78,21 @@ func (s *StorageService) GetHashesByKey(key string) ([]string, error) {
 	return hashes, nil
 }
 
-// GetFileDataByHash retrieves the file data associated with the specified hash.
+// GetFileReaderByHash opens the file associated with the specified hash for streaming.
 //
-// This method fetches the data stored under the given hash. It can
-// optionally decompress the data if the `needDecompression` flag is set to true.
-// If the data retrieval is successful, it returns the data, either
-// in compressed or decompressed form, depending
-// on the flag's value. If an error occurs during retrieval or decompression,
-// it returns an error indicating the cause of the failure.
-func (s *StorageService) GetFileDataByHash(hash string, needDecompression bool) ([]byte, error) {
-	compressed, err := s.storage.GetByHash(hash)
-	if err != nil {
-		return nil, err
-	}
-
-	if !needDecompression {
-		return compressed, nil
-	}
-
-	return s.storage.Unpack(compressed)
+// This method opens the data stored under the given hash without loading it
+// into memory. It can optionally decompress the data on the fly if the
+// `needDecompression` flag is set to true. Only `length` bytes starting
+// from `offset` are read, zero `length` means reading up to the end of the file.
+// If the file can't be opened, it returns an error indicating the cause of the failure.
+// The caller is responsible for closing the returned reader.
+func (s *StorageService) GetFileReaderByHash(
+	hash string,
+	needDecompression bool,
+	offset int64,
+	length int64,
+) (io.ReadCloser, error) {
+	return s.storage.OpenRange(hash, offset, length, needDecompression)
 }
 
 // GetKeysByChunks retrieves a slice of distinct keys from the storage in chunks.
@@ -109,6 +106,26 @@ func (s *StorageService) GetKeysByChunks(offset int) ([]string, error) {
 	return s.storage.GetKeysByChunks(offset)
 }
 
+// Stat retrieves metadata of the file associated with the specified hash.
+//
+// This method doesn't read the content of the file. If the file does not
+// exist, the returned error wraps os.ErrNotExist.
+//
+// See cas.Storage's method for more details
+func (s *StorageService) Stat(hash string) (*cas.BlobInfo, error) {
+	return s.storage.Stat(hash)
+}
+
+// ListKeys retrieves a page of distinct keys starting with the specified prefix.
+//
+// This method returns at most `limit` keys greater than `after` in lexicographical
+// order. The last key of a page should be passed as `after` to get the next one.
+// If an error occurs during the retrieval process, it returns
+// an error indicating the cause of the failure.
+func (s *StorageService) ListKeys(prefix string, after string, limit int) ([]string, error) {
+	return s.storage.GetKeysAfter(prefix, after, limit)
+}
+
 // MakePathFromHash generates a path based on the provided hash.
 //
 // This method utilizes the storage's logic to create a path that
@@ -122,8 +139,71 @@ func (s *StorageService) MakePathFromHash(hash string) string {
 // RemoveByKey deletes all data associated with the specified key.
 //
 // This method invokes the underlying storage's mechanism to remove
-// all files and metadata linked to the given key. If an error occurs
+// all the references of the given key. Files are removed later by the garbage
+// collector, once no other key references them. If an error occurs
 // during the removal process, it returns an error indicating the reason for the failure.
 func (s *StorageService) RemoveByKey(key string) error {
 	return s.storage.RemoveByKey(key)
 }
+
+// Unlink deletes the reference from the key to the file with the specified hash.
+//
+// If the key doesn't reference the hash, the returned error wraps os.ErrNotExist.
+//
+// See cas.Storage's method for more details
+func (s *StorageService) Unlink(key string, hash string) error {
+	return s.storage.Unlink(key, hash)
+}
+
+// Scrub checks the integrity of all the stored files,
+// quarantines and repairs the corrupted ones.
+//
+// See cas.Storage's method for more details
+func (s *StorageService) Scrub(opts cas.ScrubOpts) (*cas.ScrubReport, error) {
+	return s.storage.Scrub(opts)
+}
+
+// LastScrubReport returns the report of the last finished scrubber run.
+// Returns false if the scrubber has never finished a run.
+func (s *StorageService) LastScrubReport() (*cas.ScrubReport, bool, error) {
+	return s.storage.LastScrubReport()
+}
+
+// GetKeysByHash retrieves keys referencing the file with the specified hash.
+func (s *StorageService) GetKeysByHash(hash string) ([]string, error) {
+	return s.storage.GetKeysByHash(hash)
+}
+
+// CollectGarbage removes stored files which are not referenced by any key.
+//
+// See cas.Storage's method for more details
+func (s *StorageService) CollectGarbage(opts cas.GCOpts) (*cas.GCResult, error) {
+	return s.storage.CollectGarbage(opts)
+}
+
+// MigrateHashes rehashes the stored files with the hash algorithm of the storage.
+//
+// See cas.Storage's method for more details
+func (s *StorageService) MigrateHashes(opts cas.MigrateOpts) (*cas.MigrateResult, error) {
+	return s.storage.MigrateHashes(opts)
+}
+
+// RemoveByHash deletes the file associated with the specified hash.
+//
+// This method invokes the underlying storage's mechanism to remove the
+// file and all key-hash records pointing to it. If the file does not exist,
+// the returned error wraps os.ErrNotExist.
+func (s *StorageService) RemoveByHash(hash string) error {
+	return s.storage.RemoveByHash(hash)
+}
+
+// SaveRebaseJob saves the checkpoint of the rebase job (see cas.RebaseJob)
+func (s *StorageService) SaveRebaseJob(job *cas.RebaseJob) error {
+	return s.storage.SaveRebaseJob(job)
+}
+
+// GetRebaseJob returns the last checkpoint of the rebase job with the given ID,
+// or of the last rebase job if the ID is empty. Returns false if there is no such job.
+func (s *StorageService) GetRebaseJob(id string) (*cas.RebaseJob, bool, error) {
+	return s.storage.GetRebaseJob(id)
+}
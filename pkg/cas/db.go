@@ -7,6 +7,7 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 const DB_PATH = "meta.db"
@@ -44,32 +45,139 @@ func NewDB(root string) (*DB, error) {
 	return db, err
 }
 
-func (db *DB) init() (err error) {
+func (db *DB) init() error {
 	const op = "cas.db.init"
 
-	stmt, err := db.database.Prepare("create table if not exists keys (" +
-		"id integer primary key autoincrement," +
-		"key text not null," +
-		"hash text not null unique" +
-		")",
-	)
-	defer func(stmt *sql.Stmt) {
-		if tmpErr := stmt.Close(); tmpErr != nil {
-			err = fmt.Errorf("%s: %w", op, tmpErr)
+	statements := []string{
+		"create table if not exists keys (" +
+			"id integer primary key autoincrement," +
+			"key text not null," +
+			"hash text not null unique" +
+			")",
+		"create index if not exists keys_key_idx on keys (key)",
+		"create table if not exists blobs (" +
+			"hash text primary key," +
+			"packed_size integer not null," +
+			"raw_size integer not null," +
+			"path text not null," +
+			"created_at integer not null" +
+			")",
+		"create index if not exists keys_hash_idx on keys (hash)",
+		"create table if not exists scrub_runs (" +
+			"id integer primary key autoincrement," +
+			"started_at integer not null," +
+			"finished_at integer not null," +
+			"scanned integer not null," +
+			"scanned_bytes integer not null" +
+			")",
+		"create table if not exists scrub_issues (" +
+			"run_id integer not null," +
+			"hash text not null," +
+			"reason text not null," +
+			"repaired integer not null," +
+			"primary key (run_id, hash)" +
+			")",
+		"create table if not exists frames (" +
+			"hash text not null," +
+			"raw_offset integer not null," +
+			"packed_offset integer not null," +
+			"primary key (hash, raw_offset)" +
+			")",
+		"create table if not exists rebase_jobs (" +
+			"seq integer primary key autoincrement," +
+			"id text not null unique," +
+			"state text not null," +
+			"dry_run integer not null," +
+			"started_at integer not null," +
+			"finished_at integer not null," +
+			"planned_keys integer not null," +
+			"planned_bytes integer not null," +
+			"last_key text not null," +
+			"moved_keys integer not null," +
+			"moved_bytes integer not null," +
+			"failed_keys integer not null," +
+			"error text not null" +
+			")",
+		"create table if not exists rebase_targets (" +
+			"job_id text not null," +
+			"address text not null," +
+			"name text not null," +
+			"keys integer not null," +
+			"bytes integer not null," +
+			"primary key (job_id, address)" +
+			")",
+	}
+	for _, stmt := range statements {
+		if _, err := db.database.Exec(stmt); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
 		}
-	}(stmt)
-	_, err = stmt.Exec()
-	if err != nil {
-		err = fmt.Errorf("%s: %w", op, err)
 	}
-	return
+	if err := db.migrate(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// migrations upgrade the schema created by init. Migration i moves the
+// database from version i to version i+1, the current version is kept
+// in the `user_version` pragma. New migrations must only be appended.
+var migrations = [][]string{
+	// 1: allow many keys to point to the same hash
+	{
+		"create table keys_v1 (" +
+			"id integer primary key autoincrement," +
+			"key text not null," +
+			"hash text not null," +
+			"unique (key, hash)" +
+			")",
+		"insert or ignore into keys_v1 (id, key, hash) select id, key, hash from keys",
+		"drop table keys",
+		"alter table keys_v1 rename to keys",
+		"create index if not exists keys_key_idx on keys (key)",
+		"create index if not exists keys_hash_idx on keys (hash)",
+	},
+}
+
+// migrate applies all the migrations the database hasn't seen yet.
+// Every migration is applied in its own transaction.
+func (db *DB) migrate() error {
+	const op = "cas.db.migrate"
+
+	var version int
+	if err := db.database.QueryRow("pragma user_version").Scan(&version); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	for ; version < len(migrations); version++ {
+		tx, err := db.database.Begin()
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		for _, stmt := range migrations[version] {
+			if _, err = tx.Exec(stmt); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("%s: migration %d: %w", op, version+1, err)
+			}
+		}
+		// pragma doesn't support placeholders
+		if _, err = tx.Exec(fmt.Sprintf("pragma user_version = %d", version+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if err = tx.Commit(); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return nil
 }
 
 // Add inserts key-hash records into the database.
 //
 // This method takes a key and a slice of hash strings and adds them to the
 // `keys` table in the database. It validates that the key and hashes are
-// not empty and constructs an SQL insert statement for the operation. If
+// not empty and constructs an SQL insert statement for the operation.
+// Already existing records are ignored, so adding the same pair twice
+// doesn't increase the reference count of the hash. If
 // any of the inputs are invalid or if an error occurs during the database
 // operations, an error is returned.
 func (db *DB) Add(key string, hashes []string) error {
@@ -83,7 +191,7 @@ func (db *DB) Add(key string, hashes []string) error {
 		return fmt.Errorf("%s: %w", op, errors.New("empty hash list"))
 	}
 
-	stmtStr := "insert into keys (key, hash) values"
+	stmtStr := "insert or ignore into keys (key, hash) values"
 	var vals []interface{}
 	for _, h := range hashes {
 		if len(h) == 0 {
@@ -172,6 +280,60 @@ func (db *DB) GetKeysByChunks(offset int) ([]string, error) {
 	return keys, nil
 }
 
+// GetKeysAfter retrieves a page of distinct keys starting with the given prefix.
+//
+// This method uses keyset pagination: it returns at most limit keys, which are
+// greater than after, in lexicographical order. To get the next page, pass
+// the last key of the current one as after. Unlike GetKeysByChunks it doesn't
+// need to skip already listed rows, so its speed doesn't depend on the page number.
+// If an error occurs during the query or while scanning the results, an error is returned.
+func (db *DB) GetKeysAfter(prefix string, after string, limit int) ([]string, error) {
+	const op = "cas.db.GetKeysAfter"
+
+	query := "select distinct key from keys where key > ? and key >= ?"
+	args := []interface{}{after, prefix}
+	if upper, ok := prefixUpperBound(prefix); ok {
+		query += " and key < ?"
+		args = append(args, upper)
+	}
+	query += " order by key limit ?"
+	args = append(args, limit)
+
+	rows, err := db.database.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	keys := make([]string, 0)
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return keys, nil
+}
+
+// prefixUpperBound returns the smallest string which is greater than
+// all strings starting with prefix. Returns false if there is no such string
+// (prefix is empty or consists only of 0xff bytes).
+func prefixUpperBound(prefix string) (string, bool) {
+	upper := []byte(prefix)
+	for i := len(upper) - 1; i >= 0; i-- {
+		if upper[i] < 0xff {
+			upper[i]++
+			return string(upper[:i+1]), true
+		}
+	}
+	return "", false
+}
+
 // RemoveByKey deletes all records associated with a given key from the database.
 //
 // This method takes a key as input and executes a delete operation on the
@@ -181,17 +343,473 @@ func (db *DB) GetKeysByChunks(offset int) ([]string, error) {
 func (db *DB) RemoveByKey(key string) error {
 	const op = "cas.db.Remove"
 
-	stmt, err := db.database.Prepare("delete from keys where key = ?")
-	defer func(stmt *sql.Stmt) {
-		if tmpErr := stmt.Close(); tmpErr != nil {
-			err = fmt.Errorf("%s: %w", op, tmpErr)
+	if _, err := db.database.Exec("delete from keys where key = ?", key); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// RemoveLink deletes a single key-hash record from the database.
+//
+// Returns false if there was no such record.
+func (db *DB) RemoveLink(key string, hash string) (bool, error) {
+	const op = "cas.db.RemoveLink"
+
+	result, err := db.database.Exec("delete from keys where key = ? and hash = ?", key, hash)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return affected > 0, nil
+}
+
+// RefCount returns the number of keys referencing the given hash.
+func (db *DB) RefCount(hash string) (int, error) {
+	const op = "cas.db.RefCount"
+
+	var count int
+	if err := db.database.QueryRow("select count(*) from keys where hash = ?", hash).Scan(&count); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return count, nil
+}
+
+// GetReferencedHashes returns the set of all hashes referenced by at least one key.
+func (db *DB) GetReferencedHashes() (map[string]struct{}, error) {
+	const op = "cas.db.GetReferencedHashes"
+
+	rows, err := db.database.Query("select distinct hash from keys")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	hashes := make(map[string]struct{})
+	for rows.Next() {
+		var hash string
+		if err = rows.Scan(&hash); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
 		}
-	}(stmt)
+		hashes[hash] = struct{}{}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return hashes, nil
+}
+
+// RemoveByHash deletes all records associated with a given hash from the database.
+//
+// This method takes a hash as input and executes a delete operation on the
+// `keys` table, removing all entries that match the specified hash. If an
+// error occurs during the execution of the SQL statement, an error is returned.
+func (db *DB) RemoveByHash(hash string) error {
+	const op = "cas.db.RemoveByHash"
+
+	if _, err := db.database.Exec("delete from keys where hash = ?", hash); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// SetFrames replaces the frame index of the file with the given hash.
+//
+// This method removes all the frames previously saved for the hash and
+// inserts the provided ones in a single transaction. If an error occurs
+// during the transaction, it's rolled back and an error is returned.
+func (db *DB) SetFrames(hash string, frames []Frame) error {
+	const op = "cas.db.SetFrames"
+
+	tx, err := db.database.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback() // no-op after commit
+
+	if _, err = tx.Exec("delete from frames where hash = ?", hash); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	stmt, err := tx.Prepare("insert into frames (hash, raw_offset, packed_offset) values (?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	for _, f := range frames {
+		if _, err = stmt.Exec(hash, f.RawOffset, f.PackedOffset); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// GetFrame finds the last frame of the file with the given hash,
+// which starts at or before the provided offset of the raw data.
+//
+// Returns false if the file has no frame index.
+func (db *DB) GetFrame(hash string, offset int64) (Frame, bool, error) {
+	const op = "cas.db.GetFrame"
+
+	var frame Frame
+	err := db.database.QueryRow(
+		"select raw_offset, packed_offset from frames "+
+			"where hash = ? and raw_offset <= ? order by raw_offset desc limit 1",
+		hash, offset,
+	).Scan(&frame.RawOffset, &frame.PackedOffset)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Frame{}, false, nil
+	}
+	if err != nil {
+		return Frame{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+	return frame, true, nil
+}
+
+// RemoveFrames deletes the frame index of the file with the given hash.
+func (db *DB) RemoveFrames(hash string) error {
+	const op = "cas.db.RemoveFrames"
+
+	if _, err := db.database.Exec("delete from frames where hash = ?", hash); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// AddBlob saves metadata of a stored file.
+//
+// If metadata for the same hash already exists, it's updated,
+// but the original creation time is kept.
+func (db *DB) AddBlob(info *BlobInfo) error {
+	const op = "cas.db.AddBlob"
+
+	_, err := db.database.Exec(
+		"insert into blobs (hash, packed_size, raw_size, path, created_at) values (?, ?, ?, ?, ?) "+
+			"on conflict (hash) do update set "+
+			"packed_size = excluded.packed_size, raw_size = excluded.raw_size, path = excluded.path",
+		info.Hash, info.PackedSize, info.RawSize, info.Path, info.CreatedAt.UnixMilli(),
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// GetBlob retrieves metadata of a stored file.
+//
+// Returns false if there is no metadata for the given hash.
+func (db *DB) GetBlob(hash string) (*BlobInfo, bool, error) {
+	const op = "cas.db.GetBlob"
+
+	info := &BlobInfo{Hash: hash}
+	var createdAt int64
+	err := db.database.QueryRow(
+		"select packed_size, raw_size, path, created_at from blobs where hash = ?", hash,
+	).Scan(&info.PackedSize, &info.RawSize, &info.Path, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	info.CreatedAt = time.UnixMilli(createdAt)
+	return info, true, nil
+}
+
+// RemoveBlob deletes metadata of a stored file.
+func (db *DB) RemoveBlob(hash string) error {
+	const op = "cas.db.RemoveBlob"
+
+	if _, err := db.database.Exec("delete from blobs where hash = ?", hash); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// GetKeysByHash retrieves keys associated with a given hash from the database.
+func (db *DB) GetKeysByHash(hash string) ([]string, error) {
+	const op = "cas.db.GetKeysByHash"
+
+	rows, err := db.database.Query("select distinct key from keys where hash = ? order by key", hash)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	keys := make([]string, 0)
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return keys, nil
+}
+
+// MoveHash replaces hash `from` with hash `to` in all key-hash records.
+//
+// If withFrames is true, the frame index of `from` is copied to `to` as well.
+// Records which already exist for `to` are kept. Everything is done in
+// a single transaction, so keys never lose their references halfway.
+func (db *DB) MoveHash(from string, to string, withFrames bool) error {
+	const op = "cas.db.MoveHash"
+
+	tx, err := db.database.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback() // no-op after commit
+
+	statements := []string{
+		"insert or ignore into keys (key, hash) select key, ? from keys where hash = ?",
+		"delete from keys where hash = ?",
+	}
+	args := [][]interface{}{{to, from}, {from}}
+	if withFrames {
+		statements = append(statements,
+			"insert or ignore into frames (hash, raw_offset, packed_offset) "+
+				"select ?, raw_offset, packed_offset from frames where hash = ?",
+		)
+		args = append(args, []interface{}{to, from})
+	}
+	for i, stmt := range statements {
+		if _, err = tx.Exec(stmt, args[i]...); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// SCRUB_HISTORY_SIZE is the number of scrubber reports kept in the database
+const SCRUB_HISTORY_SIZE = 10
+
+// AddScrubReport saves the report of a scrubber run.
+//
+// Only the last SCRUB_HISTORY_SIZE reports are kept, older ones are removed
+// in the same transaction.
+func (db *DB) AddScrubReport(report *ScrubReport) error {
+	const op = "cas.db.AddScrubReport"
+
+	tx, err := db.database.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback() // no-op after commit
+
+	result, err := tx.Exec(
+		"insert into scrub_runs (started_at, finished_at, scanned, scanned_bytes) values (?, ?, ?, ?)",
+		report.StartedAt.UnixMilli(), report.FinishedAt.UnixMilli(), report.Scanned, report.ScannedBytes,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	runID, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, issue := range report.Issues {
+		_, err = tx.Exec(
+			"insert or replace into scrub_issues (run_id, hash, reason, repaired) values (?, ?, ?, ?)",
+			runID, issue.Hash, issue.Reason, issue.Repaired,
+		)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	oldest := runID - SCRUB_HISTORY_SIZE
+	if _, err = tx.Exec("delete from scrub_runs where id <= ?", oldest); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err = tx.Exec("delete from scrub_issues where run_id <= ?", oldest); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
 
-	_, err = stmt.Exec(key)
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// GetLastScrubReport retrieves the report of the last scrubber run.
+//
+// Returns false if there are no reports.
+func (db *DB) GetLastScrubReport() (*ScrubReport, bool, error) {
+	const op = "cas.db.GetLastScrubReport"
+
+	var runID, startedAt, finishedAt int64
+	report := &ScrubReport{Issues: make([]ScrubIssue, 0)}
+	err := db.database.QueryRow(
+		"select id, started_at, finished_at, scanned, scanned_bytes from scrub_runs order by id desc limit 1",
+	).Scan(&runID, &startedAt, &finishedAt, &report.Scanned, &report.ScannedBytes)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+	report.StartedAt = time.UnixMilli(startedAt)
+	report.FinishedAt = time.UnixMilli(finishedAt)
+
+	rows, err := db.database.Query(
+		"select hash, reason, repaired from scrub_issues where run_id = ? order by hash", runID,
+	)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var issue ScrubIssue
+		if err = rows.Scan(&issue.Hash, &issue.Reason, &issue.Repaired); err != nil {
+			return nil, false, fmt.Errorf("%s: %w", op, err)
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return report, true, nil
+}
+
+// REBASE_HISTORY_SIZE is the number of rebase jobs kept in the database
+const REBASE_HISTORY_SIZE = 10
+
+// SaveRebaseJob saves the checkpoint of the rebase job, replacing
+// the previous checkpoint of the same job along with its plan.
+//
+// Only the last REBASE_HISTORY_SIZE jobs are kept, older ones are removed
+// in the same transaction.
+func (db *DB) SaveRebaseJob(job *RebaseJob) error {
+	const op = "cas.db.SaveRebaseJob"
+
+	tx, err := db.database.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback() // no-op after commit
+
+	_, err = tx.Exec(
+		"insert into rebase_jobs (id, state, dry_run, started_at, finished_at, planned_keys, planned_bytes,"+
+			" last_key, moved_keys, moved_bytes, failed_keys, error) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"+
+			" on conflict (id) do update set state = excluded.state, dry_run = excluded.dry_run,"+
+			" started_at = excluded.started_at, finished_at = excluded.finished_at,"+
+			" planned_keys = excluded.planned_keys, planned_bytes = excluded.planned_bytes,"+
+			" last_key = excluded.last_key, moved_keys = excluded.moved_keys, moved_bytes = excluded.moved_bytes,"+
+			" failed_keys = excluded.failed_keys, error = excluded.error",
+		job.ID, string(job.State), job.DryRun, job.StartedAt.UnixMilli(), job.FinishedAt.UnixMilli(),
+		job.PlannedKeys, job.PlannedBytes, job.LastKey, job.MovedKeys, job.MovedBytes, job.FailedKeys, job.Error,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err = tx.Exec("delete from rebase_targets where job_id = ?", job.ID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for _, target := range job.Plan {
+		_, err = tx.Exec(
+			"insert or replace into rebase_targets (job_id, address, name, keys, bytes) values (?, ?, ?, ?, ?)",
+			job.ID, target.Address, target.Name, target.Keys, target.Bytes,
+		)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	_, err = tx.Exec(
+		"delete from rebase_targets where job_id in "+
+			"(select id from rebase_jobs order by seq desc limit -1 offset ?)", REBASE_HISTORY_SIZE,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	_, err = tx.Exec(
+		"delete from rebase_jobs where seq in "+
+			"(select seq from rebase_jobs order by seq desc limit -1 offset ?)", REBASE_HISTORY_SIZE,
+	)
 	if err != nil {
-		err = fmt.Errorf("%s: %w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
 	return nil
 }
+
+// GetRebaseJob retrieves the checkpoint of the rebase job with the given ID,
+// or of the last rebase job if the ID is empty.
+//
+// Returns false if there is no such job.
+func (db *DB) GetRebaseJob(id string) (*RebaseJob, bool, error) {
+	const op = "cas.db.GetRebaseJob"
+
+	query := "select id, state, dry_run, started_at, finished_at, planned_keys, planned_bytes," +
+		" last_key, moved_keys, moved_bytes, failed_keys, error from rebase_jobs"
+	args := make([]any, 0)
+	if id == "" {
+		query += " order by seq desc limit 1"
+	} else {
+		query += " where id = ?"
+		args = append(args, id)
+	}
+
+	var state string
+	var startedAt, finishedAt int64
+	job := &RebaseJob{Plan: make([]RebaseTarget, 0)}
+	err := db.database.QueryRow(query, args...).Scan(
+		&job.ID, &state, &job.DryRun, &startedAt, &finishedAt, &job.PlannedKeys, &job.PlannedBytes,
+		&job.LastKey, &job.MovedKeys, &job.MovedBytes, &job.FailedKeys, &job.Error,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+	job.State = RebaseState(state)
+	job.StartedAt = time.UnixMilli(startedAt)
+	job.FinishedAt = time.UnixMilli(finishedAt)
+
+	rows, err := db.database.Query(
+		"select address, name, keys, bytes from rebase_targets where job_id = ? order by address", job.ID,
+	)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var target RebaseTarget
+		if err = rows.Scan(&target.Address, &target.Name, &target.Keys, &target.Bytes); err != nil {
+			return nil, false, fmt.Errorf("%s: %w", op, err)
+		}
+		job.Plan = append(job.Plan, target)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return job, true, nil
+}
@@ -1,10 +1,14 @@
 package cas
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/gfxv/go-stash/internal/utils"
 	"github.com/stretchr/testify/assert"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestDB_Add(t *testing.T) {
@@ -60,3 +64,172 @@ func TestDB_Add(t *testing.T) {
 		})
 	}
 }
+
+func TestDB_GetKeysAfter(t *testing.T) {
+	const dbPath = "mock"
+	utils.CreateParent(dbPath)
+	defer utils.CleanUp(dbPath)
+
+	db, err := NewDB(dbPath)
+	assert.NoError(t, err)
+
+	keys := []string{"a/1", "a/2", "a/3", "ab", "b/1", "a/1"}
+	for i, key := range keys {
+		err = db.Add(key, []string{fmt.Sprintf("hash%d", i)})
+		assert.NoError(t, err)
+	}
+
+	tests := []struct {
+		name     string
+		prefix   string
+		after    string
+		limit    int
+		expected []string
+	}{
+		{name: "All Keys", prefix: "", after: "", limit: 10, expected: []string{"a/1", "a/2", "a/3", "ab", "b/1"}},
+		{name: "First Page", prefix: "", after: "", limit: 2, expected: []string{"a/1", "a/2"}},
+		{name: "Next Page", prefix: "", after: "a/2", limit: 2, expected: []string{"a/3", "ab"}},
+		{name: "Prefix", prefix: "a/", after: "", limit: 10, expected: []string{"a/1", "a/2", "a/3"}},
+		{name: "Prefix Next Page", prefix: "a/", after: "a/1", limit: 10, expected: []string{"a/2", "a/3"}},
+		{name: "No Keys", prefix: "c", after: "", limit: 10, expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := db.GetKeysAfter(tt.prefix, tt.after, tt.limit)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPrefixUpperBound(t *testing.T) {
+	upper, ok := prefixUpperBound("ab")
+	assert.True(t, ok)
+	assert.Equal(t, "ac", upper)
+
+	upper, ok = prefixUpperBound("a\xff")
+	assert.True(t, ok)
+	assert.Equal(t, "b", upper)
+
+	_, ok = prefixUpperBound("")
+	assert.False(t, ok)
+}
+
+func TestDB_SharedHash(t *testing.T) {
+	const dbPath = "mock"
+	utils.CreateParent(dbPath)
+	defer utils.CleanUp(dbPath)
+
+	db, err := NewDB(dbPath)
+	assert.NoError(t, err)
+
+	assert.NoError(t, db.Add("key1", []string{"some_hash"}))
+	assert.NoError(t, db.Add("key2", []string{"some_hash"}))
+	// adding the same pair again doesn't create another reference
+	assert.NoError(t, db.Add("key2", []string{"some_hash"}))
+
+	refs, err := db.RefCount("some_hash")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, refs)
+
+	assert.NoError(t, db.RemoveByKey("key1"))
+	refs, err = db.RefCount("some_hash")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, refs)
+}
+
+func TestDB_Migrate(t *testing.T) {
+	const dbPath = "mock"
+	utils.CreateParent(dbPath)
+	defer utils.CleanUp(dbPath)
+
+	// database created before the keys could share hashes
+	database, err := sql.Open(DB_DRIVER, filepath.Join(dbPath, DB_PATH))
+	assert.NoError(t, err)
+	_, err = database.Exec("create table keys (" +
+		"id integer primary key autoincrement," +
+		"key text not null," +
+		"hash text not null unique" +
+		")")
+	assert.NoError(t, err)
+	_, err = database.Exec("insert into keys (key, hash) values ('key1', 'some_hash')")
+	assert.NoError(t, err)
+	assert.NoError(t, database.Close())
+
+	db, err := NewDB(dbPath)
+	assert.NoError(t, err)
+
+	hashes, err := db.GetByKey("key1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"some_hash"}, hashes)
+	assert.NoError(t, db.Add("key2", []string{"some_hash"}))
+
+	var version int
+	assert.NoError(t, db.database.QueryRow("pragma user_version").Scan(&version))
+	assert.Equal(t, len(migrations), version)
+
+	// reopening doesn't apply migrations again
+	db, err = NewDB(dbPath)
+	assert.NoError(t, err)
+	refs, err := db.RefCount("some_hash")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, refs)
+}
+
+func TestDB_RebaseJob(t *testing.T) {
+	const dbPath = "mock"
+	utils.CreateParent(dbPath)
+	defer utils.CleanUp(dbPath)
+
+	db, err := NewDB(dbPath)
+	assert.NoError(t, err)
+
+	_, found, err := db.GetRebaseJob("")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	job := &RebaseJob{
+		ID:        "job1",
+		State:     RebaseRunning,
+		StartedAt: time.UnixMilli(1000),
+		Plan: []RebaseTarget{
+			{Address: "10.0.0.1:5555", Name: "node1", Keys: 2, Bytes: 200},
+			{Address: "10.0.0.2:5555", Name: "node2", Keys: 1, Bytes: 100},
+		},
+		PlannedKeys:  3,
+		PlannedBytes: 300,
+	}
+	assert.NoError(t, db.SaveRebaseJob(job))
+
+	// the checkpoint replaces the previous one, the plan is kept
+	job.LastKey = "key2"
+	job.MovedKeys = 2
+	job.MovedBytes = 200
+	assert.NoError(t, db.SaveRebaseJob(job))
+
+	saved, found, err := db.GetRebaseJob("job1")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, job.LastKey, saved.LastKey)
+	assert.Equal(t, job.MovedKeys, saved.MovedKeys)
+	assert.Equal(t, job.Plan, saved.Plan)
+	assert.True(t, saved.FinishedAt.IsZero())
+	assert.False(t, saved.Finished())
+
+	for i := 0; i < REBASE_HISTORY_SIZE; i++ {
+		assert.NoError(t, db.SaveRebaseJob(&RebaseJob{ID: fmt.Sprintf("next%d", i), State: RebaseDone}))
+	}
+	last, found, err := db.GetRebaseJob("")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, fmt.Sprintf("next%d", REBASE_HISTORY_SIZE-1), last.ID)
+
+	// the oldest job is removed along with its plan
+	_, found, err = db.GetRebaseJob("job1")
+	assert.NoError(t, err)
+	assert.False(t, found)
+	var targets int
+	assert.NoError(t, db.database.QueryRow("select count(*) from rebase_targets").Scan(&targets))
+	assert.Equal(t, 0, targets)
+}
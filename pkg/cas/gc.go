@@ -0,0 +1,169 @@
+package cas
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// GCOpts holds the settings of a single garbage collection run
+type GCOpts struct {
+	// GracePeriod protects recently written files, which may be not yet
+	// referenced by their keys, from being removed. Only files which
+	// were not modified during the grace period are collected.
+	GracePeriod time.Duration
+	// DryRun makes the collector only report files it would remove
+	DryRun bool
+}
+
+// GCResult describes the outcome of a garbage collection run
+type GCResult struct {
+	// Scanned is the number of stored files checked by the collector
+	Scanned int
+	// Removed contains hashes of the removed files
+	// (or the files which would be removed during dry run)
+	Removed []string
+	// FreedBytes is the disk space taken by the removed files
+	FreedBytes int64
+}
+
+// CollectGarbage removes stored files which are not referenced by any key.
+//
+// This method uses mark-and-sweep approach: at first it collects all the hashes
+// referenced by keys (mark), then it walks through the stored files and
+// removes the ones that are not referenced (sweep). Files modified during
+// the grace period are kept, and the reference count of every file is checked
+// once more right before its removal, so files referenced by the keys added
+// after the mark phase are never removed. If any errors occur during
+// this process, the method returns an error along with the result so far.
+func (s *Storage) CollectGarbage(opts GCOpts) (*GCResult, error) {
+	const op = "cas.storage.CollectGarbage"
+
+	referenced, err := s.db.GetReferencedHashes()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	result := &GCResult{Removed: make([]string, 0)}
+	deadline := time.Now().Add(-opts.GracePeriod)
+
+	err = s.walkBlobs(func(hash string, info os.FileInfo) error {
+		result.Scanned++
+		if _, ok := referenced[hash]; ok {
+			return nil
+		}
+		if info.ModTime().After(deadline) {
+			return nil
+		}
+
+		refs, err := s.db.RefCount(hash)
+		if err != nil {
+			return err
+		}
+		if refs > 0 { // referenced after the mark phase
+			return nil
+		}
+
+		if !opts.DryRun {
+			if err := s.RemoveByHash(hash); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+		result.Removed = append(result.Removed, hash)
+		result.FreedBytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return result, nil
+}
+
+// walkBlobs calls fn for every file stored in the base directory. Files with
+// legacy hashes are stored right in the base directory, the other ones in
+// the directories named after tags of their algorithms. Service files
+// (database, staging and temporary files) are skipped.
+func (s *Storage) walkBlobs(fn func(hash string, info os.FileInfo) error) error {
+	dirs, err := os.ReadDir(s.baseDir)
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range dirs {
+		if !dir.IsDir() || strings.HasPrefix(dir.Name(), ".") {
+			continue
+		}
+		if _, err := GetHashAlgorithm(dir.Name()); err == nil {
+			tagged := filepath.Join(s.baseDir, dir.Name())
+			if err := walkPrefixes(tagged, dir.Name()+HASH_TAG_SEPARATOR, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(dir.Name()) == PREFIX_LENGTH {
+			if err := walkPrefix(s.baseDir, dir.Name(), "", fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// walkPrefixes calls walkPrefix for every prefix directory inside of root
+func walkPrefixes(root string, tag string, fn func(hash string, info os.FileInfo) error) error {
+	prefixes, err := os.ReadDir(root)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, prefix := range prefixes {
+		if !prefix.IsDir() || len(prefix.Name()) != PREFIX_LENGTH || strings.HasPrefix(prefix.Name(), ".") {
+			continue
+		}
+		if err := walkPrefix(root, prefix.Name(), tag, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkPrefix calls fn for every file stored in the prefix directory inside of root.
+// Hashes passed to fn consist of tag, prefix and the name of the file.
+func walkPrefix(root string, prefix string, tag string, fn func(hash string, info os.FileInfo) error) error {
+	entries, err := os.ReadDir(filepath.Join(root, prefix))
+	if errors.Is(err, os.ErrNotExist) { // removed along with its last file
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if temp, _ := filepath.Match(TEMP_PATTERN, entry.Name()); temp {
+			continue
+		}
+
+		info, err := entry.Info()
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := fn(tag+prefix+entry.Name(), info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
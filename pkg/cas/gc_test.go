@@ -0,0 +1,89 @@
+package cas
+
+import (
+	"github.com/gfxv/go-stash/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCollectGarbage(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	// both keys share the same file
+	data := PrepareRawFile("some/path", []byte("some data here"))
+	hash, err := storage.WriteFromRawData(data)
+	assert.NoError(t, err)
+	assert.NoError(t, storage.AddNewPath("key1", hash))
+	assert.NoError(t, storage.AddNewPath("key2", hash))
+
+	refs, err := storage.RefCount(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, refs)
+
+	assert.NoError(t, storage.RemoveByKey("key1"))
+	result, err := storage.CollectGarbage(GCOpts{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Scanned)
+	assert.Empty(t, result.Removed)
+	assert.True(t, storage.Has(storage.MakePathFromHash(hash)))
+
+	assert.NoError(t, storage.RemoveByKey("key2"))
+
+	// dry run only reports the file
+	result, err = storage.CollectGarbage(GCOpts{DryRun: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{hash}, result.Removed)
+	assert.True(t, result.FreedBytes > 0)
+	assert.True(t, storage.Has(storage.MakePathFromHash(hash)))
+
+	result, err = storage.CollectGarbage(GCOpts{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{hash}, result.Removed)
+	assert.False(t, storage.Has(storage.MakePathFromHash(hash)))
+}
+
+func TestCollectGarbageGracePeriod(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	// file is written, but not yet referenced by its key
+	hash, err := storage.WriteFromRawData(PrepareRawFile("some/path", []byte("some data here")))
+	assert.NoError(t, err)
+
+	result, err := storage.CollectGarbage(GCOpts{GracePeriod: time.Hour})
+	assert.NoError(t, err)
+	assert.Empty(t, result.Removed)
+	assert.True(t, storage.Has(storage.MakePathFromHash(hash)))
+}
+
+func TestUnlink(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	hash, err := storage.WriteFromRawData(PrepareRawFile("some/path", []byte("some data here")))
+	assert.NoError(t, err)
+	assert.NoError(t, storage.AddNewPath("key1", hash))
+	assert.NoError(t, storage.AddNewPath("key2", hash))
+
+	assert.NoError(t, storage.Unlink("key1", hash))
+	assert.ErrorIs(t, storage.Unlink("key1", hash), os.ErrNotExist)
+
+	hashes, err := storage.GetHashesByKey("key2")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{hash}, hashes)
+}
@@ -0,0 +1,148 @@
+package cas
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"sync"
+)
+
+// HASH_TAG_SEPARATOR separates the algorithm tag from the digest
+// in self-describing hashes, e.g. "sha256-9f86d08..."
+const HASH_TAG_SEPARATOR = "-"
+
+// ErrUnknownHashAlgorithm is returned for hashes tagged
+// with an algorithm which is not registered
+var ErrUnknownHashAlgorithm = errors.New("unknown hash algorithm")
+
+// ErrInvalidHash is returned for hashes which can't be produced by their algorithm
+var ErrInvalidHash = errors.New("invalid hash")
+
+// HashFunc returns a new hash.Hash used to derive paths from data.
+type HashFunc func() hash.Hash
+
+// HashAlgorithm describes a hash function used to address stored files.
+//
+// Hashes produced by an algorithm are prefixed with its tag (see Sum),
+// so every hash says which algorithm it was made with, and files hashed
+// with different algorithms can live in the same storage.
+type HashAlgorithm struct {
+	// Tag is a short name of the algorithm, it must not contain HASH_TAG_SEPARATOR
+	Tag string
+	New HashFunc
+}
+
+var (
+	SHA1   = HashAlgorithm{Tag: "sha1", New: sha1.New}
+	SHA256 = HashAlgorithm{Tag: "sha256", New: sha256.New}
+	SHA512 = HashAlgorithm{Tag: "sha512", New: sha512.New}
+)
+
+// LegacyHashAlgorithm is the algorithm of untagged hashes,
+// which were produced before hashes became self-describing
+var LegacyHashAlgorithm = SHA1
+
+var hashAlgorithms = struct {
+	sync.RWMutex
+	byTag map[string]HashAlgorithm
+}{
+	byTag: map[string]HashAlgorithm{
+		SHA1.Tag:   SHA1,
+		SHA256.Tag: SHA256,
+		SHA512.Tag: SHA512,
+	},
+}
+
+// RegisterHashAlgorithm makes the algorithm available to the storage,
+// so hashes tagged with it can be used. This allows plugging in
+// algorithms outside of the standard library, e.g. BLAKE3.
+func RegisterHashAlgorithm(algorithm HashAlgorithm) error {
+	const op = "cas.hash.RegisterHashAlgorithm"
+
+	if algorithm.Tag == "" || strings.Contains(algorithm.Tag, HASH_TAG_SEPARATOR) {
+		return fmt.Errorf("%s: invalid tag '%s'", op, algorithm.Tag)
+	}
+	if algorithm.New == nil {
+		return fmt.Errorf("%s: %w", op, errors.New("hash function is not set"))
+	}
+
+	hashAlgorithms.Lock()
+	defer hashAlgorithms.Unlock()
+	if _, ok := hashAlgorithms.byTag[algorithm.Tag]; ok {
+		return fmt.Errorf("%s: algorithm '%s' is already registered", op, algorithm.Tag)
+	}
+	hashAlgorithms.byTag[algorithm.Tag] = algorithm
+	return nil
+}
+
+// GetHashAlgorithm returns the registered algorithm with the given tag.
+func GetHashAlgorithm(tag string) (HashAlgorithm, error) {
+	const op = "cas.hash.GetHashAlgorithm"
+
+	hashAlgorithms.RLock()
+	defer hashAlgorithms.RUnlock()
+	algorithm, ok := hashAlgorithms.byTag[tag]
+	if !ok {
+		return HashAlgorithm{}, fmt.Errorf("%s: %w: '%s'", op, ErrUnknownHashAlgorithm, tag)
+	}
+	return algorithm, nil
+}
+
+// Sum returns the tagged hash of data.
+func (a HashAlgorithm) Sum(data []byte) string {
+	hasher := a.New()
+	hasher.Write(data)
+	return a.Format(hasher.Sum(nil))
+}
+
+// Format returns the tagged hash with the given digest.
+func (a HashAlgorithm) Format(digest []byte) string {
+	return a.Tag + HASH_TAG_SEPARATOR + hex.EncodeToString(digest)
+}
+
+// ParseHash returns the algorithm the hash was produced with and its hex digest.
+//
+// Untagged hashes are treated as produced by LegacyHashAlgorithm.
+// If the hash is tagged with an unknown algorithm, the returned error
+// wraps ErrUnknownHashAlgorithm. If the digest doesn't look like one
+// produced by the algorithm, the returned error wraps ErrInvalidHash.
+func ParseHash(hash string) (HashAlgorithm, string, error) {
+	const op = "cas.hash.ParseHash"
+
+	tag, digest := splitHash(hash)
+	algorithm := LegacyHashAlgorithm
+	if tag != "" {
+		var err error
+		if algorithm, err = GetHashAlgorithm(tag); err != nil {
+			return HashAlgorithm{}, "", fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if len(digest) != 2*algorithm.New().Size() {
+		return HashAlgorithm{}, "", fmt.Errorf("%s: %w: '%s'", op, ErrInvalidHash, hash)
+	}
+	if _, err := hex.DecodeString(digest); err != nil {
+		return HashAlgorithm{}, "", fmt.Errorf("%s: %w: '%s'", op, ErrInvalidHash, hash)
+	}
+	return algorithm, digest, nil
+}
+
+// IsLegacyHash reports whether the hash has no algorithm tag.
+func IsLegacyHash(hash string) bool {
+	tag, _ := splitHash(hash)
+	return tag == ""
+}
+
+// splitHash splits the hash into the algorithm tag and the digest.
+// The tag is empty for legacy hashes.
+func splitHash(hash string) (tag string, digest string) {
+	if i := strings.Index(hash, HASH_TAG_SEPARATOR); i >= 0 {
+		return hash[:i], hash[i+len(HASH_TAG_SEPARATOR):]
+	}
+	return "", hash
+}
@@ -0,0 +1,108 @@
+package cas
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"github.com/gfxv/go-stash/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"hash/fnv"
+	"io"
+	"testing"
+)
+
+func legacyHash(data []byte) string {
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestParseHash(t *testing.T) {
+	data := []byte("some data here")
+
+	tests := []struct {
+		name        string
+		hash        string
+		expectedTag string
+		expectedErr error
+	}{
+		{name: "SHA-256", hash: SHA256.Sum(data), expectedTag: SHA256.Tag},
+		{name: "SHA-512", hash: SHA512.Sum(data), expectedTag: SHA512.Tag},
+		{name: "Tagged SHA-1", hash: SHA1.Sum(data), expectedTag: SHA1.Tag},
+		{name: "Legacy", hash: legacyHash(data), expectedTag: LegacyHashAlgorithm.Tag},
+		{name: "Unknown algorithm", hash: "md5-d41d8cd98f00b204e9800998ecf8427e", expectedErr: ErrUnknownHashAlgorithm},
+		{name: "Wrong length", hash: "sha256-abcdef", expectedErr: ErrInvalidHash},
+		{name: "Not hex", hash: "sha1-" + string(bytes.Repeat([]byte("x"), 40)), expectedErr: ErrInvalidHash},
+		{name: "Empty", hash: "", expectedErr: ErrInvalidHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			algorithm, _, err := ParseHash(tt.hash)
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedTag, algorithm.Tag)
+		})
+	}
+}
+
+func TestRegisterHashAlgorithm(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	fnv128 := HashAlgorithm{Tag: "fnv128", New: fnv.New128a}
+	if _, err := GetHashAlgorithm(fnv128.Tag); err != nil { // registered by previous run
+		assert.NoError(t, RegisterHashAlgorithm(fnv128))
+	}
+	assert.Error(t, RegisterHashAlgorithm(fnv128))
+	assert.Error(t, RegisterHashAlgorithm(HashAlgorithm{Tag: "with-separator", New: fnv.New128a}))
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+	storage.hashAlgorithm = fnv128
+
+	data := PrepareRawFile("some/path", []byte("some data here"))
+	hash, err := storage.WriteFromRawData(data)
+	assert.NoError(t, err)
+	assert.Equal(t, fnv128.Sum(data), hash)
+
+	report, err := storage.Scrub(ScrubOpts{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Scanned)
+	assert.Empty(t, report.Issues)
+}
+
+func TestMixedHashes(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	legacyData := PrepareRawFile("legacy/path", []byte("legacy data"))
+	legacy := legacyHash(legacyData)
+	assert.NoError(t, storage.WriteCompressed(legacy, bytes.NewReader(ZLibPack(legacyData))))
+
+	data := PrepareRawFile("some/path", []byte("some data here"))
+	hash, err := storage.WriteFromRawData(data)
+	assert.NoError(t, err)
+
+	for h, expected := range map[string][]byte{legacy: legacyData, hash: data} {
+		reader, err := storage.OpenByHash(h, true)
+		assert.NoError(t, err)
+		stored, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		assert.NoError(t, reader.Close())
+		assert.Equal(t, expected, stored)
+	}
+
+	report, err := storage.Scrub(ScrubOpts{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Scanned)
+	assert.Empty(t, report.Issues)
+}
@@ -0,0 +1,171 @@
+package cas
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// MigrateOpts holds the settings of a single hash migration run
+type MigrateOpts struct {
+	// DryRun makes the migration only report files it would rehash
+	DryRun bool
+}
+
+// MigrateResult describes the outcome of a hash migration run
+type MigrateResult struct {
+	// Scanned is the number of stored files checked by the migration
+	Scanned int
+	// Migrated maps old hashes of the migrated files to the new ones
+	// (or the files which would be migrated during dry run)
+	Migrated map[string]string
+	// Failed contains hashes of the files which content doesn't match
+	// their hashes, such files are left for the scrubber
+	Failed []string
+}
+
+// MigrateHashes rehashes the stored files with the hash algorithm of the storage.
+//
+// This method walks through the stored files and picks the ones with legacy
+// hashes or hashes produced by other algorithms. Every picked file is unpacked
+// to compute its new hash, and the old hash is verified along the way.
+// The packed file is copied to the path derived from the new hash, all the keys
+// referencing the old hash are moved to the new one, and then the old file is removed.
+// Files with unknown algorithms and corrupted files are skipped.
+// Files stored under the old hashes while the migration is running
+// are picked by the next run. If any errors occur during this process,
+// the method returns an error along with the result so far.
+func (s *Storage) MigrateHashes(opts MigrateOpts) (*MigrateResult, error) {
+	const op = "cas.storage.MigrateHashes"
+
+	// files are collected first, so the walk doesn't see the migrated ones
+	hashes := make([]string, 0)
+	scanned := 0
+	err := s.walkBlobs(func(hash string, _ os.FileInfo) error {
+		scanned++
+		algorithm, _, err := ParseHash(hash)
+		if err != nil {
+			return nil
+		}
+		if IsLegacyHash(hash) || algorithm.Tag != s.hashAlgorithm.Tag {
+			hashes = append(hashes, hash)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	result := &MigrateResult{
+		Scanned:  scanned,
+		Migrated: make(map[string]string),
+		Failed:   make([]string, 0),
+	}
+	for _, hash := range hashes {
+		newHash, raw, err := s.rehash(hash)
+		if errors.Is(err, os.ErrNotExist) { // removed while migrating
+			continue
+		}
+		if errors.Is(err, ErrCorrupted) {
+			result.Failed = append(result.Failed, hash)
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if !opts.DryRun {
+			if err := s.moveBlob(hash, newHash, raw); err != nil {
+				return result, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+		result.Migrated[hash] = newHash
+	}
+
+	return result, nil
+}
+
+// rehash unpacks the file with the given hash and computes its hash with
+// the algorithm of the storage. The returned error wraps ErrCorrupted
+// if the content doesn't match the old hash.
+func (s *Storage) rehash(hash string) (string, *rawInspector, error) {
+	algorithm, digest, err := ParseHash(hash)
+	if err != nil {
+		return "", nil, err
+	}
+
+	file, err := os.Open(s.MakePathFromHash(hash))
+	if err != nil {
+		return "", nil, err
+	}
+	defer file.Close()
+
+	unpacked, err := s.UnpackReader(file)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: can't unpack: %v", ErrCorrupted, err)
+	}
+	defer unpacked.Close()
+
+	oldHasher := algorithm.New()
+	newHasher := s.hashAlgorithm.New()
+	raw := &rawInspector{}
+	if _, err := io.Copy(io.MultiWriter(oldHasher, newHasher, raw), unpacked); err != nil {
+		return "", nil, fmt.Errorf("%w: can't unpack: %v", ErrCorrupted, err)
+	}
+
+	if hex.EncodeToString(oldHasher.Sum(nil)) != digest {
+		return "", nil, fmt.Errorf("%w: content doesn't match %s", ErrCorrupted, hash)
+	}
+	return s.hashAlgorithm.Format(newHasher.Sum(nil)), raw, nil
+}
+
+// moveBlob stores a copy of the file under the new hash, moves all the references
+// to it and removes the old file. If a file with the new hash already exists,
+// it's kept along with its frame index.
+func (s *Storage) moveBlob(hash string, newHash string, raw *rawInspector) error {
+	oldPath, newPath := s.MakePathFromHash(hash), s.MakePathFromHash(newHash)
+	if err := s.PrepareParentFolders(newPath); err != nil {
+		return err
+	}
+
+	copied := false
+	if !s.Has(newPath) {
+		if err := copyToPath(oldPath, newPath, filepath.Join(s.baseDir, STAGING_DIR)); err != nil {
+			return err
+		}
+		copied = true
+	}
+
+	if err := s.saveBlobInfo(newHash, raw.size, raw.Path()); err != nil {
+		return err
+	}
+	if err := s.db.MoveHash(hash, newHash, copied); err != nil {
+		return err
+	}
+
+	if err := s.RemoveByHash(hash); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// copyToPath atomically copies the file at path to newPath
+// through a temporary file in the staging directory
+func copyToPath(path string, newPath string, stagingDir string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	tmpPath, _, err := createTemp(stagingDir, file, nil)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath) // no-op if the file was moved
+
+	return commitTemp(tmpPath, newPath)
+}
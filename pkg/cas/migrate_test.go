@@ -0,0 +1,65 @@
+package cas
+
+import (
+	"bytes"
+	"github.com/gfxv/go-stash/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func TestMigrateHashes(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	data := PrepareRawFile("some/path", sampleData(3*FRAME_SIZE))
+	legacy := legacyHash(data)
+	assert.NoError(t, storage.WriteCompressed(legacy, bytes.NewReader(ZLibPack(data))))
+	assert.NoError(t, storage.AddNewPath("key1", legacy))
+	assert.NoError(t, storage.AddNewPath("key2", legacy))
+
+	broken := legacyHash([]byte("broken"))
+	assert.NoError(t, storage.WriteCompressed(broken, bytes.NewReader(ZLibPack([]byte("other data")))))
+
+	// dry run only reports the files
+	result, err := storage.MigrateHashes(MigrateOpts{DryRun: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Scanned)
+	assert.Equal(t, map[string]string{legacy: SHA256.Sum(data)}, result.Migrated)
+	assert.Equal(t, []string{broken}, result.Failed)
+	assert.True(t, storage.Has(storage.MakePathFromHash(legacy)))
+
+	result, err = storage.MigrateHashes(MigrateOpts{})
+	assert.NoError(t, err)
+	migrated := result.Migrated[legacy]
+	assert.Equal(t, SHA256.Sum(data), migrated)
+
+	assert.False(t, storage.Has(storage.MakePathFromHash(legacy)))
+	for _, key := range []string{"key1", "key2"} {
+		hashes, err := storage.GetHashesByKey(key)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{migrated}, hashes)
+	}
+
+	reader, err := storage.OpenRange(migrated, FRAME_SIZE, 10, true)
+	assert.NoError(t, err)
+	part, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.NoError(t, reader.Close())
+	assert.Equal(t, data[FRAME_SIZE:FRAME_SIZE+10], part)
+
+	info, err := storage.Stat(migrated)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(data)), info.RawSize)
+	assert.Equal(t, "some/path", info.Path)
+
+	// nothing left to migrate except the corrupted file
+	result, err = storage.MigrateHashes(MigrateOpts{})
+	assert.NoError(t, err)
+	assert.Empty(t, result.Migrated)
+	assert.Equal(t, []string{broken}, result.Failed)
+}
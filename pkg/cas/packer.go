@@ -2,19 +2,46 @@ package cas
 
 import (
 	"bytes"
+	"compress/flate"
 	"compress/zlib"
+	"encoding/binary"
 	"fmt"
+	"hash"
+	"hash/adler32"
 	"io"
 )
 
+// FRAME_SIZE is the amount of raw data packed into a single frame,
+// see ZLibPackWriter for more details
+const FRAME_SIZE = 1024 * 1024 // 1 MiB
+
 type PackFunc func([]byte) []byte
 type UnpackFunc func([]byte) ([]byte, error)
+type PackWriterFunc func(io.Writer) io.WriteCloser
+type UnpackReaderFunc func(io.Reader) (io.ReadCloser, error)
+type UnpackFrameFunc func(io.Reader) io.ReadCloser
+
+// Frame describes the beginning of a part of packed data,
+// which can be unpacked without unpacking anything before it.
+type Frame struct {
+	// RawOffset is an offset of the frame in the unpacked data
+	RawOffset int64
+	// PackedOffset is an offset of the frame in the packed data
+	PackedOffset int64
+}
+
+// FramedWriter is implemented by writers returned from PackWriterFunc,
+// which split packed data into independently unpackable frames.
+// Frames must be called only after the writer is closed.
+type FramedWriter interface {
+	Frames() []Frame
+}
 
 // TODO: add levels of compression
 
 func ZLibPack(data []byte) []byte {
 	var buff bytes.Buffer
-	w := zlib.NewWriter(&buff)
+	w := ZLibPackWriter(&buff)
 	w.Write(data)
 	w.Close()
 	return buff.Bytes()
@@ -36,3 +63,141 @@ func ZLibUnpack(data []byte) ([]byte, error) {
 
 	return result.Bytes(), nil
 }
+
+// ZLibPackWriter wraps w with a zlib compressor, so data can be packed
+// on the fly. Data is not guaranteed to be written to w until Close is called.
+//
+// The output is a regular zlib stream, but every FRAME_SIZE bytes of raw data
+// the compressor is reset and flushed to a byte boundary, so no frame references
+// data of the previous ones. This makes it possible to unpack data starting
+// from any frame (see ZLibUnpackFrame), which is used to serve byte ranges.
+// Returned writer implements FramedWriter.
+func ZLibPackWriter(w io.Writer) io.WriteCloser {
+	return &zlibFramedWriter{
+		w:     &countingWriter{w: w},
+		adler: adler32.New(),
+	}
+}
+
+// ZLibUnpackReader wraps r with a zlib decompressor, so packed data
+// can be unpacked on the fly without loading it into memory.
+func ZLibUnpackReader(r io.Reader) (io.ReadCloser, error) {
+	const op = "cas.packer.ZLibUnpackReader"
+
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return zr, nil
+}
+
+// ZLibUnpackFrame unpacks data written by ZLibPackWriter, starting
+// from the beginning of a frame (r must be positioned at Frame.PackedOffset).
+// Unpacking continues through all the following frames until the end of data.
+func ZLibUnpackFrame(r io.Reader) io.ReadCloser {
+	return flate.NewReader(r)
+}
+
+// zlibFramedWriter writes zlib stream, which consists of independently
+// compressed frames (see ZLibPackWriter)
+type zlibFramedWriter struct {
+	w     *countingWriter
+	adler hash.Hash32
+	fw    *flate.Writer
+
+	written   int64 // amount of raw data written
+	frameSize int64 // amount of raw data written to the current frame
+	inFrame   bool
+	frames    []Frame
+}
+
+// zlib header for default compression level
+var zlibHeader = []byte{0x78, 0x9c}
+
+func (z *zlibFramedWriter) Write(p []byte) (int, error) {
+	total := 0
+	for len(p) > 0 {
+		if !z.inFrame {
+			if err := z.startFrame(); err != nil {
+				return total, err
+			}
+		}
+
+		part := p
+		if left := FRAME_SIZE - z.frameSize; int64(len(part)) > left {
+			part = part[:left]
+		}
+		n, err := z.fw.Write(part)
+		z.adler.Write(part[:n])
+		z.written += int64(n)
+		z.frameSize += int64(n)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		p = p[n:]
+
+		if z.frameSize == FRAME_SIZE {
+			// sync flush aligns the end of the frame to a byte boundary
+			if err := z.fw.Flush(); err != nil {
+				return total, err
+			}
+			z.inFrame = false
+		}
+	}
+	return total, nil
+}
+
+func (z *zlibFramedWriter) startFrame() error {
+	if z.fw == nil {
+		if _, err := z.w.Write(zlibHeader); err != nil {
+			return err
+		}
+		fw, err := flate.NewWriter(z.w, flate.DefaultCompression)
+		if err != nil {
+			return err
+		}
+		z.fw = fw
+	} else {
+		z.fw.Reset(z.w) // forget the previous frames
+	}
+
+	z.frames = append(z.frames, Frame{RawOffset: z.written, PackedOffset: z.w.n})
+	z.frameSize = 0
+	z.inFrame = true
+	return nil
+}
+
+func (z *zlibFramedWriter) Close() error {
+	if !z.inFrame {
+		// final block must be written even if there is no data left
+		if err := z.startFrame(); err != nil {
+			return err
+		}
+	}
+	if err := z.fw.Close(); err != nil {
+		return err
+	}
+	z.inFrame = false
+
+	checksum := make([]byte, 4)
+	binary.BigEndian.PutUint32(checksum, z.adler.Sum32())
+	_, err := z.w.Write(checksum)
+	return err
+}
+
+func (z *zlibFramedWriter) Frames() []Frame {
+	return z.frames
+}
+
+// countingWriter counts bytes written to the underlying writer
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
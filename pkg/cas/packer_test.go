@@ -0,0 +1,70 @@
+package cas
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sampleData(size int) []byte {
+	data := make([]byte, size)
+	random := rand.New(rand.NewSource(42))
+	for i := range data {
+		data[i] = byte('a' + random.Intn(8)) // compressible, but not too much
+	}
+	return data
+}
+
+func TestZLibPackUnpack(t *testing.T) {
+	tests := []struct {
+		name           string
+		size           int
+		expectedFrames int
+	}{
+		{name: "Empty", size: 0, expectedFrames: 1},
+		{name: "Single Frame", size: FRAME_SIZE / 2, expectedFrames: 1},
+		{name: "Exact Frame", size: FRAME_SIZE, expectedFrames: 2},
+		{name: "Multiple Frames", size: FRAME_SIZE*3 + 123, expectedFrames: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := sampleData(tt.size)
+
+			var packed bytes.Buffer
+			w := ZLibPackWriter(&packed)
+			_, err := w.Write(data)
+			assert.NoError(t, err)
+			assert.NoError(t, w.Close())
+
+			// output must be a regular zlib stream
+			unpacked, err := ZLibUnpack(packed.Bytes())
+			assert.NoError(t, err)
+			assert.Equal(t, len(data), len(unpacked))
+			assert.True(t, bytes.Equal(data, unpacked))
+
+			frames := w.(FramedWriter).Frames()
+			assert.Len(t, frames, tt.expectedFrames)
+		})
+	}
+}
+
+func TestZLibUnpackFrame(t *testing.T) {
+	data := sampleData(FRAME_SIZE*3 + 123)
+
+	var packed bytes.Buffer
+	w := ZLibPackWriter(&packed)
+	_, err := w.Write(data)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	for _, frame := range w.(FramedWriter).Frames() {
+		r := ZLibUnpackFrame(bytes.NewReader(packed.Bytes()[frame.PackedOffset:]))
+		unpacked, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		assert.True(t, bytes.Equal(data[frame.RawOffset:], unpacked), "wrong data for frame %v", frame)
+	}
+}
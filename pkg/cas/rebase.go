@@ -0,0 +1,68 @@
+package cas
+
+import "time"
+
+// RebaseState is the state of a rebase job
+type RebaseState string
+
+const (
+	// RebasePending jobs are created, but not started yet
+	RebasePending   RebaseState = "pending"
+	RebaseRunning   RebaseState = "running"
+	RebaseDone      RebaseState = "done"
+	RebaseFailed    RebaseState = "failed"
+	RebaseCancelled RebaseState = "cancelled"
+)
+
+// RebaseTarget is the part of a rebase plan which is moved to a single node
+type RebaseTarget struct {
+	Address string
+	Name    string
+	Keys    int
+	Bytes   int64
+}
+
+// RebaseJob is the checkpoint of a rebase job, which moves keys
+// from the current node to the nodes which should store them
+type RebaseJob struct {
+	ID    string
+	State RebaseState
+	// DryRun jobs only plan the rebase, no keys are moved
+	DryRun     bool
+	StartedAt  time.Time
+	FinishedAt time.Time
+
+	// Plan is the number of keys and bytes moved to every node, a resumed job keeps it.
+	// PlannedKeys is the number of distinct keys, PlannedBytes is the sum over the targets
+	Plan         []RebaseTarget
+	PlannedKeys  int
+	PlannedBytes int64
+
+	// LastKey is the last key of the last rebased chunk of keys. Keys are rebased
+	// in lexicographical order, so a resumed job continues right after it
+	LastKey   string
+	MovedKeys int
+	// MovedBytes is the number of bytes sent to all the nodes of the moved keys
+	MovedBytes int64
+	// FailedKeys is the number of keys which are not acknowledged
+	// by their nodes, they are kept until the next rebase
+	FailedKeys int
+	Error      string
+}
+
+// Finished returns true if the job is not going to make progress anymore
+func (j *RebaseJob) Finished() bool {
+	return j.State == RebaseDone || j.State == RebaseFailed || j.State == RebaseCancelled
+}
+
+// SaveRebaseJob saves the checkpoint of the rebase job, replacing
+// the previous checkpoint of the same job (see RebaseJob)
+func (s *Storage) SaveRebaseJob(job *RebaseJob) error {
+	return s.db.SaveRebaseJob(job)
+}
+
+// GetRebaseJob returns the last checkpoint of the rebase job with the given ID,
+// or of the last rebase job if the ID is empty. Returns false if there is no such job.
+func (s *Storage) GetRebaseJob(id string) (*RebaseJob, bool, error) {
+	return s.db.GetRebaseJob(id)
+}
@@ -0,0 +1,232 @@
+package cas
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// QUARANTINE_DIR is a directory inside of the base directory,
+// where corrupted files are moved by the scrubber
+const QUARANTINE_DIR = ".quarantine"
+
+// ErrCorrupted is returned when content of the file doesn't match its hash
+var ErrCorrupted = errors.New("file is corrupted")
+
+// RepairFunc returns a healthy packed copy of the file with the given hash,
+// e.g. received from another node
+type RepairFunc func(hash string) (io.ReadCloser, error)
+
+// ScrubOpts holds the settings of a single scrubber run
+type ScrubOpts struct {
+	// BytesPerSecond limits the read rate of the scrubber,
+	// so it doesn't compete with clients for disk. Zero means no limit.
+	BytesPerSecond int64
+	// Repair is used to replace corrupted files with healthy copies.
+	// If it's nil, corrupted files are only quarantined.
+	Repair RepairFunc
+}
+
+// ScrubIssue describes a corrupted file found by the scrubber
+type ScrubIssue struct {
+	Hash     string
+	Reason   string
+	Repaired bool
+}
+
+// ScrubReport describes the outcome of a scrubber run
+type ScrubReport struct {
+	StartedAt    time.Time
+	FinishedAt   time.Time
+	Scanned      int
+	ScannedBytes int64
+	Issues       []ScrubIssue
+}
+
+// Scrub checks the integrity of all the stored files.
+//
+// This method walks through the stored files at a rate limited by the options,
+// unpacks every file and checks that the hash of its content matches its name.
+// Files hashed with algorithms which are not registered are skipped.
+// Corrupted files are moved to the quarantine directory, their key-hash records
+// are kept, so the files can be repaired. If a repair function is provided,
+// it's used to get a healthy copy of every corrupted file, which is verified
+// before being stored. The report of the run is saved to the database
+// (see LastScrubReport). If any errors occur during this process,
+// the method returns an error.
+func (s *Storage) Scrub(opts ScrubOpts) (*ScrubReport, error) {
+	const op = "cas.storage.Scrub"
+
+	report := &ScrubReport{
+		StartedAt: time.Now(),
+		Issues:    make([]ScrubIssue, 0),
+	}
+	throttle := newThrottle(opts.BytesPerSecond)
+
+	err := s.walkBlobs(func(hash string, info os.FileInfo) error {
+		err := s.verifyBlob(hash, throttle)
+		if errors.Is(err, os.ErrNotExist) { // removed while scrubbing
+			return nil
+		}
+		if errors.Is(err, ErrUnknownHashAlgorithm) { // can't be verified
+			return nil
+		}
+		report.Scanned++
+		report.ScannedBytes += info.Size()
+		if !errors.Is(err, ErrCorrupted) {
+			return err
+		}
+
+		issue := ScrubIssue{Hash: hash, Reason: err.Error()}
+		if err := s.quarantine(hash); err != nil {
+			return err
+		}
+		if opts.Repair != nil {
+			if err := s.repair(hash, opts.Repair); err != nil {
+				issue.Reason = fmt.Sprintf("%s; repair failed: %v", issue.Reason, err)
+			} else {
+				issue.Repaired = true
+			}
+		}
+		report.Issues = append(report.Issues, issue)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	report.FinishedAt = time.Now()
+	if err := s.db.AddScrubReport(report); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return report, nil
+}
+
+// LastScrubReport returns the report of the last finished scrubber run.
+// Returns false if the scrubber has never finished a run.
+func (s *Storage) LastScrubReport() (*ScrubReport, bool, error) {
+	return s.db.GetLastScrubReport()
+}
+
+// verifyBlob unpacks the file with the given hash and checks that its content
+// matches the hash. The returned error wraps ErrCorrupted if it doesn't.
+// Content is hashed with the algorithm the hash is tagged with.
+func (s *Storage) verifyBlob(hash string, throttle *throttle) error {
+	algorithm, digest, err := ParseHash(hash)
+	if errors.Is(err, ErrInvalidHash) {
+		return fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(s.MakePathFromHash(hash))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	unpacked, err := s.UnpackReader(throttle.reader(file))
+	if err != nil {
+		return fmt.Errorf("%w: can't unpack: %v", ErrCorrupted, err)
+	}
+	defer unpacked.Close()
+
+	hasher := algorithm.New()
+	if _, err := io.Copy(hasher, unpacked); err != nil {
+		return fmt.Errorf("%w: can't unpack: %v", ErrCorrupted, err)
+	}
+
+	if actual := hex.EncodeToString(hasher.Sum(nil)); actual != digest {
+		return fmt.Errorf("%w: content digest is %s", ErrCorrupted, actual)
+	}
+	return nil
+}
+
+// quarantine moves the file with the given hash to the quarantine directory
+// and removes its metadata. Key-hash records of the file are kept.
+func (s *Storage) quarantine(hash string) error {
+	dir := filepath.Join(s.baseDir, QUARANTINE_DIR)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	// the same hash may be quarantined more than once
+	target := filepath.Join(dir, hash+"-"+strconv.FormatInt(time.Now().UnixNano(), 10))
+	if err := os.Rename(s.MakePathFromHash(hash), target); err != nil {
+		return err
+	}
+
+	if err := s.db.RemoveFrames(hash); err != nil {
+		return err
+	}
+	return s.db.RemoveBlob(hash)
+}
+
+// repair stores a copy of the file received from the repair function.
+// If the copy turns out to be corrupted too, it's quarantined as well.
+func (s *Storage) repair(hash string, repairFunc RepairFunc) error {
+	healthy, err := repairFunc(hash)
+	if err != nil {
+		return err
+	}
+	defer healthy.Close()
+
+	if err := s.WriteCompressed(hash, healthy); err != nil {
+		return err
+	}
+
+	if err := s.verifyBlob(hash, newThrottle(0)); err != nil {
+		if errors.Is(err, ErrCorrupted) {
+			if qErr := s.quarantine(hash); qErr != nil {
+				return qErr
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// throttle limits the rate at which data is read by all of its readers
+type throttle struct {
+	bytesPerSecond int64
+	start          time.Time
+	read           int64
+}
+
+func newThrottle(bytesPerSecond int64) *throttle {
+	return &throttle{bytesPerSecond: bytesPerSecond, start: time.Now()}
+}
+
+func (t *throttle) reader(r io.Reader) io.Reader {
+	if t.bytesPerSecond <= 0 {
+		return r
+	}
+	return &throttledReader{r: r, throttle: t}
+}
+
+// wait sleeps until reading n more bytes doesn't exceed the rate limit
+func (t *throttle) wait(n int) {
+	t.read += int64(n)
+	expected := time.Duration(float64(t.read) / float64(t.bytesPerSecond) * float64(time.Second))
+	if sleep := expected - time.Since(t.start); sleep > 0 {
+		time.Sleep(sleep)
+	}
+}
+
+type throttledReader struct {
+	r        io.Reader
+	throttle *throttle
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.throttle.wait(n)
+	return n, err
+}
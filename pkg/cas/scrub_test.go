@@ -0,0 +1,128 @@
+package cas
+
+import (
+	"bytes"
+	"errors"
+	"github.com/gfxv/go-stash/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func corrupt(t *testing.T, s *Storage, hash string) {
+	path := s.MakePathFromHash(hash)
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	data[len(data)/2] ^= 0xff
+	assert.NoError(t, os.WriteFile(path, data, 0666))
+}
+
+func TestScrub(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	healthy, err := storage.WriteFromRawData(PrepareRawFile("some/path", []byte("some data here")))
+	assert.NoError(t, err)
+	broken, err := storage.WriteFromRawData(PrepareRawFile("other/path", bytes.Repeat([]byte("other data"), 100)))
+	assert.NoError(t, err)
+	assert.NoError(t, storage.AddNewPath("key", broken))
+	corrupt(t, storage, broken)
+
+	report, err := storage.Scrub(ScrubOpts{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Scanned)
+	assert.Len(t, report.Issues, 1)
+	assert.Equal(t, broken, report.Issues[0].Hash)
+	assert.False(t, report.Issues[0].Repaired)
+
+	// corrupted file is moved to quarantine, but its key is kept
+	assert.False(t, storage.Has(storage.MakePathFromHash(broken)))
+	assert.True(t, storage.Has(storage.MakePathFromHash(healthy)))
+	quarantined, err := filepath.Glob(filepath.Join(root, QUARANTINE_DIR, broken+"-*"))
+	assert.NoError(t, err)
+	assert.Len(t, quarantined, 1)
+	hashes, err := storage.GetHashesByKey("key")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{broken}, hashes)
+
+	saved, found, err := storage.LastScrubReport()
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, report.Scanned, saved.Scanned)
+	assert.Equal(t, report.Issues, saved.Issues)
+}
+
+func TestScrubRepair(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	data := PrepareRawFile("some/path", []byte("some data here"))
+	hash, err := storage.WriteFromRawData(data)
+	assert.NoError(t, err)
+	packed, err := storage.GetByHash(hash)
+	assert.NoError(t, err)
+	corrupt(t, storage, hash)
+
+	repairFunc := func(requested string) (io.ReadCloser, error) {
+		assert.Equal(t, hash, requested)
+		return io.NopCloser(bytes.NewReader(packed)), nil
+	}
+	report, err := storage.Scrub(ScrubOpts{Repair: repairFunc})
+	assert.NoError(t, err)
+	assert.Len(t, report.Issues, 1)
+	assert.True(t, report.Issues[0].Repaired)
+
+	stored, err := storage.GetByHash(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, packed, stored)
+
+	// repair with a corrupted copy fails
+	corrupt(t, storage, hash)
+	repairFunc = func(string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader([]byte("garbage"))), nil
+	}
+	report, err = storage.Scrub(ScrubOpts{Repair: repairFunc})
+	assert.NoError(t, err)
+	assert.Len(t, report.Issues, 1)
+	assert.False(t, report.Issues[0].Repaired)
+	assert.False(t, storage.Has(storage.MakePathFromHash(hash)))
+
+	repairFunc = func(string) (io.ReadCloser, error) {
+		return nil, errors.New("no replicas")
+	}
+	report, err = storage.Scrub(ScrubOpts{Repair: repairFunc})
+	assert.NoError(t, err)
+	assert.Empty(t, report.Issues)
+}
+
+func TestScrubThrottle(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	// random data is incompressible, so the stored file is at least 8 KiB
+	data := make([]byte, 8*1024)
+	rand.New(rand.NewSource(1)).Read(data)
+	_, err = storage.WriteFromRawData(data)
+	assert.NoError(t, err)
+
+	start := time.Now()
+	_, err = storage.Scrub(ScrubOpts{BytesPerSecond: 32 * 1024})
+	assert.NoError(t, err)
+	assert.True(t, time.Since(start) >= 200*time.Millisecond)
+}
@@ -0,0 +1,124 @@
+package cas
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// STAGING_DIR is a directory inside of the base directory, where files
+// are written before being moved to their content-addressed paths
+const STAGING_DIR = ".staging"
+
+// TEMP_PATTERN is a pattern for names of files that are still being written
+const TEMP_PATTERN = "tmp-*"
+
+// prepareStagingDir creates the staging directory inside of the base directory
+// and removes any files left there by writes interrupted by a crash.
+func prepareStagingDir(baseDir string) error {
+	const op = "cas.staging.prepareStagingDir"
+
+	stagingDir := filepath.Join(baseDir, STAGING_DIR)
+	if err := os.MkdirAll(stagingDir, os.ModePerm); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	leftovers, err := os.ReadDir(stagingDir)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for _, entry := range leftovers {
+		if err := os.RemoveAll(filepath.Join(stagingDir, entry.Name())); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return nil
+}
+
+// writeTemp copies data from r to a new file in the staging directory,
+// compressing it on the way if pack is true. Returns path to the new file
+// and its frames, if PackWriter splits data into frames.
+func (s *Storage) writeTemp(r io.Reader, pack bool) (string, []Frame, error) {
+	var wrap PackWriterFunc
+	if pack {
+		wrap = s.PackWriter
+	}
+	return createTemp(filepath.Join(s.baseDir, STAGING_DIR), r, wrap)
+}
+
+// createTemp copies data from r to a new temporary file in dir and flushes
+// it to disk. If wrap is not nil, data is written through the writer it returns.
+// On error the temporary file is removed. Returns path to the new file
+// and frames of the written data, if the writer implements FramedWriter.
+func createTemp(dir string, r io.Reader, wrap PackWriterFunc) (string, []Frame, error) {
+	const op = "cas.staging.createTemp"
+
+	file, err := os.CreateTemp(dir, TEMP_PATTERN)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var frames []Frame
+	err = func() error {
+		buffered := bufio.NewWriter(file)
+		var w io.WriteCloser = nopWriteCloser{buffered}
+		if wrap != nil {
+			w = wrap(buffered)
+		}
+
+		if _, err := io.Copy(w, r); err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		if framed, ok := w.(FramedWriter); ok {
+			frames = framed.Frames()
+		}
+		if err := buffered.Flush(); err != nil {
+			return err
+		}
+		return file.Sync()
+	}()
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return file.Name(), frames, nil
+}
+
+// commitTemp atomically moves a file created by createTemp to path
+// and flushes the parent directory, so the move survives a crash.
+func commitTemp(tmpPath string, path string) error {
+	const op = "cas.staging.commitTemp"
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := syncDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// syncDir flushes directory entries of the given directory to disk
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,201 @@
+package cas
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// UNKNOWN_SIZE is used as a raw size of files that can't be unpacked
+// while being stored or were stored before their metadata was saved
+const UNKNOWN_SIZE = -1
+
+// MAX_HEADER_LENGTH limits the length of the header (see PrepareRawFile)
+// which is searched for in the raw data
+const MAX_HEADER_LENGTH = 4096
+
+// BlobInfo holds metadata of a stored file
+type BlobInfo struct {
+	Hash string
+	// PackedSize is a size of the file on disk
+	PackedSize int64
+	// RawSize is a size of the unpacked file (including header) or UNKNOWN_SIZE
+	RawSize int64
+	// Path is the original file path taken from the header
+	Path      string
+	Keys      []string
+	CreatedAt time.Time
+}
+
+// Stat returns metadata of the file stored under the provided hash.
+//
+// This method reads only the metadata saved when the file was stored, so the
+// content of the file is not transferred. For files stored without metadata,
+// sizes and creation time are taken from the file system, and the original
+// path is found by unpacking only the header of the file.
+// If the file does not exist, the returned error wraps os.ErrNotExist.
+func (s *Storage) Stat(hash string) (*BlobInfo, error) {
+	const op = "cas.storage.Stat"
+
+	if _, _, err := ParseHash(hash); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	path := s.MakePathFromHash(hash)
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	info, found, err := s.db.GetBlob(hash)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !found {
+		info = &BlobInfo{
+			Hash:       hash,
+			PackedSize: fileInfo.Size(),
+			RawSize:    UNKNOWN_SIZE,
+			CreatedAt:  fileInfo.ModTime(),
+		}
+		if info.Path, err = s.readHeader(path); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if info.Keys, err = s.db.GetKeysByHash(hash); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return info, nil
+}
+
+// readHeader unpacks only the beginning of the file
+// and returns the original path stored in its header
+func (s *Storage) readHeader(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	unpacked, err := s.UnpackReader(file)
+	if err != nil {
+		return "", err
+	}
+	defer unpacked.Close()
+
+	raw := &rawInspector{}
+	_, err = io.Copy(raw, io.LimitReader(unpacked, MAX_HEADER_LENGTH+1))
+	if err != nil {
+		return "", err
+	}
+	return raw.Path(), nil
+}
+
+// saveBlobInfo saves metadata of the file stored under the given hash
+func (s *Storage) saveBlobInfo(hash string, rawSize int64, path string) error {
+	fileInfo, err := os.Stat(s.MakePathFromHash(hash))
+	if err != nil {
+		return err
+	}
+
+	return s.db.AddBlob(&BlobInfo{
+		Hash:       hash,
+		PackedSize: fileInfo.Size(),
+		RawSize:    rawSize,
+		Path:       path,
+		CreatedAt:  time.Now(),
+	})
+}
+
+// rawInspector collects metadata of the raw data written to it
+type rawInspector struct {
+	size   int64
+	header []byte
+	// headerDone is true when the end of the header is found
+	// or the data turned out to have no header
+	headerDone bool
+	hasHeader  bool
+}
+
+func (r *rawInspector) Write(p []byte) (int, error) {
+	r.size += int64(len(p))
+	if r.headerDone {
+		return len(p), nil
+	}
+
+	if i := bytes.IndexByte(p, 0); i >= 0 {
+		r.header = append(r.header, p[:i]...)
+		r.headerDone = true
+		r.hasHeader = len(r.header) <= MAX_HEADER_LENGTH
+	} else {
+		r.header = append(r.header, p...)
+		r.headerDone = len(r.header) > MAX_HEADER_LENGTH
+	}
+	if r.headerDone && !r.hasHeader {
+		r.header = nil
+	}
+	return len(p), nil
+}
+
+// Path returns the original file path stored in the header (see PrepareRawFile)
+// or an empty string if there is no header
+func (r *rawInspector) Path() string {
+	if !r.hasHeader {
+		return ""
+	}
+	return string(r.header)
+}
+
+// unpackInspector unpacks the data written to it in the background
+// and collects its metadata using rawInspector
+type unpackInspector struct {
+	pipe *io.PipeWriter
+	done chan struct{}
+	raw  *rawInspector
+	err  error
+}
+
+// newUnpackInspector starts unpacking in the background. If content is not nil,
+// the unpacked data is written to it as well (e.g. to hash the data)
+func (s *Storage) newUnpackInspector(content io.Writer) *unpackInspector {
+	pipeReader, pipeWriter := io.Pipe()
+	u := &unpackInspector{
+		pipe: pipeWriter,
+		done: make(chan struct{}),
+		raw:  &rawInspector{},
+	}
+
+	go func() {
+		defer close(u.done)
+		defer io.Copy(io.Discard, pipeReader) // never block the writer
+
+		unpacked, err := s.UnpackReader(pipeReader)
+		if err != nil {
+			u.err = err
+			return
+		}
+		var w io.Writer = u.raw
+		if content != nil {
+			w = io.MultiWriter(u.raw, content)
+		}
+		_, u.err = io.Copy(w, unpacked)
+	}()
+
+	return u
+}
+
+func (u *unpackInspector) Write(p []byte) (int, error) {
+	return u.pipe.Write(p)
+}
+
+// Close stops unpacking and returns the collected metadata. If err is
+// not nil, it's treated as the reason the writing was interrupted.
+func (u *unpackInspector) Close(err error) (*rawInspector, error) {
+	u.pipe.CloseWithError(err)
+	<-u.done
+	return u.raw, u.err
+}
@@ -1,57 +1,68 @@
 package cas
 
 import (
+	"bufio"
 	"bytes"
-	"crypto/sha1"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 const PREFIX_LENGTH = 5
 
-type TransformPathFunc func([]byte) (string, string)
-
-func DefaultTransformPathFunc(data []byte) (prefix string, filename string) {
-	fullHash := sha1.Sum(data)
-	strHash := hex.EncodeToString(fullHash[:])
-	prefix, filename = strHash[:PREFIX_LENGTH], strHash[PREFIX_LENGTH:]
-	return
-}
+const COMPARE_BUFFER_SIZE = 32 * 1024 // 32 KiB
 
 type StorageOpts struct {
-	BaseDir           string
-	PathFunc          TransformPathFunc
+	BaseDir string
+	// HashAlgorithm is used to address newly stored files,
+	// files stored with other algorithms are still available
+	HashAlgorithm     HashAlgorithm
 	Pack              PackFunc
 	Unpack            UnpackFunc
+	PackWriter        PackWriterFunc
+	UnpackReader      UnpackReaderFunc
+	UnpackFrame       UnpackFrameFunc
 	ReplicationFactor int // TODO: implement locally
 }
 
 type Storage struct {
 	baseDir       string
-	transformPath TransformPathFunc
+	hashAlgorithm HashAlgorithm
 	db            *DB
 
-	Pack   PackFunc
-	Unpack UnpackFunc
+	Pack         PackFunc
+	Unpack       UnpackFunc
+	PackWriter   PackWriterFunc
+	UnpackReader UnpackReaderFunc
+	UnpackFrame  UnpackFrameFunc
 }
 
 // NewDefaultStorage creates a new instance of Storage.
 //
 // This function initializes the storage by creating the specified base directory,
+// cleaning up files left in the staging directory by interrupted writes,
 // setting up the database, and configuring any provided transformation functions
 // for paths. If any errors occur during the directory creation or database
 // initialization, an error is returned.
 func NewDefaultStorage(opts StorageOpts) (*Storage, error) {
 	const op = "cas.storage.NewDefaultStorage"
 
+	if opts.HashAlgorithm.New == nil {
+		return nil, fmt.Errorf("%s: %w", op, errors.New("hash algorithm is not set"))
+	}
+
 	if err := createBaseDir(opts.BaseDir); err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
+	if err := prepareStagingDir(opts.BaseDir); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
 	db, err := NewDB(opts.BaseDir)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -59,10 +70,13 @@ func NewDefaultStorage(opts StorageOpts) (*Storage, error) {
 
 	return &Storage{
 		baseDir:       opts.BaseDir,
-		transformPath: opts.PathFunc,
+		hashAlgorithm: opts.HashAlgorithm,
 		db:            db,
 		Pack:          opts.Pack,
 		Unpack:        opts.Unpack,
+		PackWriter:    opts.PackWriter,
+		UnpackReader:  opts.UnpackReader,
+		UnpackFrame:   opts.UnpackFrame,
 	}, nil
 }
 
@@ -145,22 +159,32 @@ func PrepareRawFile(path string, data []byte) []byte {
 	return prepared
 }
 
+// RawFileReader is a streaming counterpart of PrepareRawFile.
+//
+// It returns a reader that yields the same header (file path followed
+// by a null byte) and then the content read from data.
+func RawFileReader(path string, data io.Reader) io.Reader {
+	header := fmt.Sprintf("%s\u0000", path) // header = Path + \0
+	return io.MultiReader(strings.NewReader(header), data)
+}
+
 // Write saves the given data to a file at the specified path on disk.
 //
-// This method takes a file path and the data to be written as input. It creates
-// a new file at the specified path and writes the provided data to it. If
-// any errors occur during file creation or writing, the method returns an error.
+// This method takes a file path and the data to be written as input. The data
+// is written to a file in the staging directory first, flushed to disk and only
+// then atomically moved to the specified path, so a crash can never leave
+// a partially written file there. If any errors occur during writing
+// or moving the file, the method returns an error.
 func (s *Storage) Write(path string, data []byte) error {
 	const op = "cas.storage.Write"
 
-	file, err := os.Create(path)
+	tmpPath, _, err := s.writeTemp(bytes.NewReader(data), false)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
-	defer file.Close()
+	defer os.Remove(tmpPath) // no-op if the file was moved
 
-	_, err = io.Copy(file, bytes.NewReader(data))
-	if err != nil {
+	if err := commitTemp(tmpPath, path); err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -179,42 +203,181 @@ func (s *Storage) Write(path string, data []byte) error {
 func (s *Storage) WriteFromRawData(data []byte) (string, error) {
 	const op = "cas.storage.WriteFromRawData"
 
-	prefix, filename := s.transformPath(data)
-	compressed := s.Pack(data)
+	hash := s.hashAlgorithm.Sum(data)
+
+	tmpPath, frames, err := s.writeTemp(bytes.NewReader(data), true)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	defer os.Remove(tmpPath) // no-op if the file was moved
+
+	raw := &rawInspector{}
+	raw.Write(data)
+	if err := s.commitPacked(hash, tmpPath, frames, raw); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return hash, nil
+}
+
+// WriteFromReader is a streaming counterpart of WriteFromRawData.
+//
+// This method reads raw data from r and hashes, compresses and writes it to
+// a file in the staging directory incrementally, so the whole data is never
+// held in memory. When the data is fully read and flushed to disk, the file
+// is atomically moved to the path derived from the hash. If a file with the
+// same name already exists, it checks if the content is different to avoid
+// overwriting. The method returns the hash of the data or an error if any
+// operation fails.
+func (s *Storage) WriteFromReader(r io.Reader) (string, error) {
+	const op = "cas.storage.WriteFromReader"
+
+	hasher := s.hashAlgorithm.New()
+	raw := &rawInspector{}
+	tmpPath, frames, err := s.writeTemp(io.TeeReader(r, io.MultiWriter(hasher, raw)), true)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	defer os.Remove(tmpPath) // no-op if the file was moved
 
-	folders := filepath.Join(s.baseDir, prefix)
-	if err := os.MkdirAll(folders, os.ModePerm); err != nil { // TODO: change permissions (?), now - 777
+	strHash := s.hashAlgorithm.Format(hasher.Sum(nil))
+	if err := s.commitPacked(strHash, tmpPath, frames, raw); err != nil {
 		return "", fmt.Errorf("%s: %w", op, err)
 	}
 
-	fullPath := filepath.Join(folders, filename)
+	return strHash, nil
+}
+
+// commitPacked moves a file packed by writeTemp to the path derived from
+// the hash and saves its metadata and frame index. If a file with the same
+// name already exists, it checks if the content is different to avoid overwriting.
+// Existing file with the same content is replaced by the new one.
+func (s *Storage) commitPacked(hash string, tmpPath string, frames []Frame, raw *rawInspector) error {
+	const op = "cas.storage.commitPacked"
+
+	fullPath := s.MakePathFromHash(hash)
+	if err := s.PrepareParentFolders(fullPath); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
 	// check if file with given name (hash) exists and its content is different
 	if s.Has(fullPath) {
-		if err := compareFileContent(fullPath, &compressed); err != nil {
-			return "", fmt.Errorf("%s: %w", op, err)
+		if err := s.compareFiles(fullPath, tmpPath); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
 		}
-		//return "", fmt.Errorf("stash: collision detected! \n'%s/%s' already exists", prefix, filename)
-		return prefix + filename, nil
+	}
+	// existing file is replaced as well, since it could be packed without
+	// frames and must match the frame index. The new file is also in use
+	// again, so it survives the GC grace period
+	if err := commitTemp(tmpPath, fullPath); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.saveBlobInfo(hash, raw.size, raw.Path()); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// small files consist of a single frame, there is nothing to index
+	if len(frames) < 2 {
+		return nil
+	}
+	if err := s.db.SetFrames(hash, frames); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// WriteCompressed saves already compressed data read from r under the given hash.
+//
+// This method streams the data to a file in the staging directory and then
+// atomically moves it to the path derived from the hash, so the whole data
+// is never held in memory and a partially written file is never visible.
+// While being written, the data is unpacked in the background to collect
+// its metadata (see Stat). Data which can't be unpacked is stored anyway.
+// Frame index of the previously stored file (if any) is dropped, since
+// compressed data is not guaranteed to be split into frames.
+// The hash may be produced by any registered algorithm (see ParseHash).
+// If any errors occur during writing or moving the file, the method returns an error.
+func (s *Storage) WriteCompressed(hash string, r io.Reader) error {
+	return s.writeCompressed(hash, r, false)
+}
+
+// WriteCompressedVerified saves already compressed data read from r under
+// the given hash like WriteCompressed, but only if the unpacked data matches
+// the hash. Otherwise nothing is stored and the returned error wraps ErrCorrupted.
+// Content is hashed with the algorithm the hash is tagged with.
+func (s *Storage) WriteCompressedVerified(hash string, r io.Reader) error {
+	return s.writeCompressed(hash, r, true)
+}
+
+func (s *Storage) writeCompressed(hash string, r io.Reader, verify bool) error {
+	const op = "cas.storage.WriteCompressed"
+
+	algorithm, digest, err := ParseHash(hash)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	// Write compressed data to cas
+	fullPath := s.MakePathFromHash(hash)
+	if err := s.PrepareParentFolders(fullPath); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
 
-	err := s.Write(fullPath, compressed)
+	// the unpacked data is hashed only if it's verified
+	hasher := algorithm.New()
+	var content io.Writer
+	if verify {
+		content = hasher
+	}
+	unpacker := s.newUnpackInspector(content)
+	tmpPath, _, err := s.writeTemp(io.TeeReader(r, unpacker), false)
+	raw, unpackErr := unpacker.Close(err)
 	if err != nil {
-		return "", fmt.Errorf("%s: %w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer os.Remove(tmpPath) // no-op if the file was moved
+
+	if verify {
+		if unpackErr != nil {
+			return fmt.Errorf("%s: %w: can't unpack: %v", op, ErrCorrupted, unpackErr)
+		}
+		if actual := hex.EncodeToString(hasher.Sum(nil)); actual != digest {
+			return fmt.Errorf("%s: %w: content digest is %s", op, ErrCorrupted, actual)
+		}
+	}
+
+	if err := commitTemp(tmpPath, fullPath); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	rawSize := raw.size
+	if unpackErr != nil {
+		rawSize = UNKNOWN_SIZE
+	}
+	if err := s.saveBlobInfo(hash, rawSize, raw.Path()); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.db.RemoveFrames(hash); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	return prefix + filename, nil
+	return nil
 }
 
 // MakePathFromHash constructs a file path from a given hash.
 //
 // This method takes a hash string as input and creates a file path
-// by splitting the hash into two parts: the prefix and the remaining
-// characters. The resulting path is constructed by joining the base
-// directory with the prefix and the rest of the hash.
+// by splitting the digest of the hash into two parts: the prefix and
+// the remaining characters. The resulting path is constructed by joining
+// the base directory, the directory named after the algorithm tag,
+// the prefix and the rest of the digest. Files with legacy (untagged)
+// hashes are stored right in the base directory.
+// The hash must be valid, see ParseHash.
 func (s *Storage) MakePathFromHash(hash string) string {
-	return filepath.Join(s.baseDir, hash[:PREFIX_LENGTH], hash[PREFIX_LENGTH:])
+	tag, digest := splitHash(hash)
+	return filepath.Join(s.baseDir, tag, digest[:PREFIX_LENGTH], digest[PREFIX_LENGTH:])
 }
 
 func (s *Storage) PrepareParentFolders(fullPath string) error {
@@ -243,8 +406,7 @@ func (s *Storage) Get(key string) ([]*File, error) {
 
 	files := make([]*File, 0)
 	for _, hash := range hashes {
-		path := filepath.Join(s.baseDir, hash[:PREFIX_LENGTH], hash[PREFIX_LENGTH:])
-		file, err := s.read(path)
+		file, err := s.read(s.MakePathFromHash(hash))
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
@@ -261,14 +423,120 @@ func (s *Storage) Get(key string) ([]*File, error) {
 func (s *Storage) GetByHash(hash string) ([]byte, error) {
 	const op = "cas.storage.GetByHash"
 
-	path := filepath.Join(s.baseDir, hash[:PREFIX_LENGTH], hash[PREFIX_LENGTH:])
-	compressed, err := os.ReadFile(path)
+	if _, _, err := ParseHash(hash); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	compressed, err := os.ReadFile(s.MakePathFromHash(hash))
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	return compressed, nil
 }
 
+// OpenByHash opens the file stored under the provided hash for streaming.
+//
+// This method takes a hash string as input, constructs the file path based
+// on the hash and opens the file without reading its content. If decompress
+// is true, the returned reader unpacks the data on the fly, so memory usage
+// doesn't depend on the size of the file. The caller is responsible for
+// closing the returned reader.
+func (s *Storage) OpenByHash(hash string, decompress bool) (io.ReadCloser, error) {
+	return s.OpenRange(hash, 0, 0, decompress)
+}
+
+// OpenRange opens a byte range of the file stored under the provided hash.
+//
+// This method works the same way as OpenByHash, but the returned reader yields
+// at most length bytes (or everything up to the end if length is 0) starting
+// from offset. If decompress is true, offset and length are applied to the
+// unpacked data. In this case unpacking starts from the closest frame
+// before offset (see Frame), so only a small part of the file is unpacked
+// and thrown away. Files without a frame index are unpacked from the beginning.
+func (s *Storage) OpenRange(hash string, offset int64, length int64, decompress bool) (io.ReadCloser, error) {
+	const op = "cas.storage.OpenRange"
+
+	if offset < 0 || length < 0 {
+		return nil, fmt.Errorf("%s: %w", op, errors.New("negative offset or length"))
+	}
+
+	if _, _, err := ParseHash(hash); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	file, err := os.Open(s.MakePathFromHash(hash))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	reader, err := s.openRange(hash, file, offset, decompress)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if length > 0 {
+		reader = &rangeReader{Reader: io.LimitReader(reader, length), closer: reader}
+	}
+	return reader, nil
+}
+
+func (s *Storage) openRange(hash string, file *os.File, offset int64, decompress bool) (io.ReadCloser, error) {
+	if !decompress {
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return file, nil
+	}
+
+	var unpacked io.ReadCloser
+	skip := offset
+
+	frame, found := Frame{}, false
+	if offset > 0 {
+		var err error
+		if frame, found, err = s.db.GetFrame(hash, offset); err != nil {
+			return nil, err
+		}
+	}
+	if found {
+		if _, err := file.Seek(frame.PackedOffset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		unpacked = s.UnpackFrame(bufio.NewReader(file))
+		skip -= frame.RawOffset
+	} else {
+		var err error
+		if unpacked, err = s.UnpackReader(bufio.NewReader(file)); err != nil {
+			return nil, err
+		}
+	}
+
+	reader := &rangeReader{Reader: unpacked, closer: unpacked, file: file}
+	if _, err := io.CopyN(io.Discard, reader, skip); err != nil && err != io.EOF {
+		unpacked.Close()
+		return nil, err
+	}
+	return reader, nil
+}
+
+// rangeReader reads data from Reader and closes both
+// closer and file (if not nil) on Close
+type rangeReader struct {
+	io.Reader
+	closer io.Closer
+	file   *os.File
+}
+
+func (r *rangeReader) Close() error {
+	err := r.closer.Close()
+	if r.file != nil {
+		if fileErr := r.file.Close(); err == nil {
+			err = fileErr
+		}
+	}
+	return err
+}
+
 func (s *Storage) read(path string) (*File, error) {
 	const op = "cas.storage.read"
 
@@ -349,7 +617,62 @@ func compareFileContent(path string, data *[]byte) error {
 	return fmt.Errorf("stash: file '%s' already exists and its content is different from stashed, please remove this file manualy to avoid data overriding or corruption", path)
 }
 
-// createFile ...
+// compareFiles compares unpacked content of two packed files without
+// loading them into memory. Unpacked content is compared, since the same
+// data can be packed differently, e.g. by older versions, which didn't
+// split data into frames (see ZLibPackWriter).
+// Returns error if contents are not equal, otherwise - nil
+func (s *Storage) compareFiles(path string, otherPath string) error {
+	const op = "cas.storage.compareFiles"
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer file.Close()
+
+	other, err := os.Open(otherPath)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer other.Close()
+
+	unpacked, err := s.UnpackReader(file)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer unpacked.Close()
+
+	otherUnpacked, err := s.UnpackReader(other)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer otherUnpacked.Close()
+
+	buffer := make([]byte, COMPARE_BUFFER_SIZE)
+	otherBuffer := make([]byte, COMPARE_BUFFER_SIZE)
+	for {
+		n, err := io.ReadFull(unpacked, buffer)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		otherN, otherErr := io.ReadFull(otherUnpacked, otherBuffer)
+		if otherErr != nil && otherErr != io.EOF && otherErr != io.ErrUnexpectedEOF {
+			return fmt.Errorf("%s: %w", op, otherErr)
+		}
+
+		if !bytes.Equal(buffer[:n], otherBuffer[:otherN]) {
+			return fmt.Errorf("stash: file '%s' already exists and its content is different from stashed, please remove this file manualy to avoid data overriding or corruption", path)
+		}
+		if err != nil || otherErr != nil { // reached the end of both files
+			return nil
+		}
+	}
+}
+
+// createFile atomically creates a file with the given content.
+// The content is written to a temporary file in the same directory
+// first, so an interrupted write never leaves a truncated file at path.
 func createFile(path string, data *[]byte) error {
 	const op = "cas.storage.createFile"
 
@@ -360,20 +683,14 @@ func createFile(path string, data *[]byte) error {
 		}
 	}
 
-	file, err := os.Create(path)
+	// TODO: add logging ?
+	tmpPath, _, err := createTemp(parent, bytes.NewReader(*data), nil)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
-	defer func(file *os.File) {
-		tmpErr := file.Close()
-		if tmpErr != nil {
-			err = tmpErr
-		}
-	}(file)
+	defer os.Remove(tmpPath) // no-op if the file was moved
 
-	// TODO: add logging ?
-	_, err = file.Write(*data)
-	if err != nil {
+	if err := commitTemp(tmpPath, path); err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 	return nil
@@ -388,14 +705,14 @@ func (s *Storage) GetHashesByKey(key string) ([]string, error) {
 	return s.db.GetByKey(key)
 }
 
-// RemoveByKey deletes all files associated with the specified key.
+// RemoveByKey deletes all the references of the specified key.
 //
-// This method takes a key as input and removes all files that are linked
-// to that key in the storage. It first checks if the key is empty, returning
-// an error if it is. Then, it retrieves the associated hash values from
-// the database and attempts to remove each file by its hash. If any
-// operation fails during this process, an error is returned. Finally,
-// the method removes the key entry from the database.
+// This method takes a key as input and removes all key-hash records
+// of that key from the database. It first checks if the key is empty,
+// returning an error if it is. Files are not removed here, since they can be
+// shared with other keys; files which are no longer referenced by any key
+// are removed by CollectGarbage. If any operation fails during this process,
+// an error is returned.
 func (s *Storage) RemoveByKey(key string) error {
 	const op = "cas.storage.RemoveByKey"
 
@@ -403,38 +720,59 @@ func (s *Storage) RemoveByKey(key string) error {
 		return fmt.Errorf("%s: %w", op, errors.New("empty key"))
 	}
 
-	hashes, err := s.db.GetByKey(key)
-	if err != nil {
-		return fmt.Errorf("%s: %w", key, err)
+	if err := s.db.RemoveByKey(key); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	for _, hash := range hashes {
-		err := s.RemoveByHash(hash)
-		if err != nil {
-			return fmt.Errorf("%s: %w", op, err)
-		}
-	}
+	return nil
+}
+
+// Unlink deletes a single reference from the key to the file with the given hash.
+//
+// Like RemoveByKey, it doesn't remove the file itself (see CollectGarbage).
+// If the key doesn't reference the hash, the returned error wraps os.ErrNotExist.
+func (s *Storage) Unlink(key string, hash string) error {
+	const op = "cas.storage.Unlink"
 
-	err = s.db.RemoveByKey(key)
+	removed, err := s.db.RemoveLink(key, hash)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
+	if !removed {
+		return fmt.Errorf("%s: %w", op, os.ErrNotExist)
+	}
 
 	return nil
 }
 
+// GetKeysByHash retrieves keys referencing the file with the specified hash.
+func (s *Storage) GetKeysByHash(hash string) ([]string, error) {
+	return s.db.GetKeysByHash(hash)
+}
+
+// RefCount returns the number of keys referencing the file with the given hash.
+func (s *Storage) RefCount(hash string) (int, error) {
+	return s.db.RefCount(hash)
+}
+
 // RemoveByHash deletes the file associated with the specified hash.
 //
 // This method takes a hash string as input and constructs the corresponding
 // file path. It first checks if the file exists; if it does not, it returns
-// an error. If the file exists, it attempts to remove the file from disk.
+// an error. If the file exists, it attempts to remove the file from disk
+// along with all the key-hash records pointing to it, regardless of how many
+// keys reference it.
 // After removing the file, it checks if the parent directory is empty and
 // removes it if necessary. If any errors occur during these operations,
 // the method returns an error.
 func (s *Storage) RemoveByHash(hash string) error {
 	const op = "cas.storage.RemoveByHash"
 
-	fullPath := filepath.Join(s.baseDir, hash[:PREFIX_LENGTH], hash[PREFIX_LENGTH:])
+	if _, _, err := ParseHash(hash); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	fullPath := s.MakePathFromHash(hash)
 	if !s.Has(fullPath) {
 		return fmt.Errorf("%s: %w", op, os.ErrNotExist)
 	}
@@ -443,6 +781,18 @@ func (s *Storage) RemoveByHash(hash string) error {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	if err := s.db.RemoveFrames(hash); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.db.RemoveByHash(hash); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.db.RemoveBlob(hash); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
 	// remove parent directory if its empty
 	parent := filepath.Dir(fullPath)
 	dir, err := os.Open(parent)
@@ -466,3 +816,9 @@ func (s *Storage) DeleteAll() {}
 func (s *Storage) GetKeysByChunks(offset int) ([]string, error) {
 	return s.db.GetKeysByChunks(offset)
 }
+
+// GetKeysAfter retrieves a page of at most limit distinct keys starting
+// with prefix, which are greater than after. See DB.GetKeysAfter for details.
+func (s *Storage) GetKeysAfter(prefix string, after string, limit int) ([]string, error) {
+	return s.db.GetKeysAfter(prefix, after, limit)
+}
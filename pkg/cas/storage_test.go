@@ -1,19 +1,26 @@
 package cas
 
 import (
+	"bytes"
+	"compress/zlib"
 	"errors"
 	"github.com/gfxv/go-stash/internal/utils"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
 func sampleStorage(baseDir string) (*Storage, error) {
 	opts := StorageOpts{
-		BaseDir:  baseDir,
-		PathFunc: DefaultTransformPathFunc,
-		Pack:     ZLibPack,
-		Unpack:   ZLibUnpack,
+		BaseDir:       baseDir,
+		HashAlgorithm: SHA256,
+		Pack:          ZLibPack,
+		Unpack:        ZLibUnpack,
+		PackWriter:    ZLibPackWriter,
+		UnpackReader:  ZLibUnpackReader,
+		UnpackFrame:   ZLibUnpackFrame,
 	}
 
 	return NewDefaultStorage(opts)
@@ -37,6 +44,50 @@ func TestSaveDuplicate(t *testing.T) {
 
 }
 
+func TestSaveDuplicateUnframed(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	// older versions packed large files as a single zlib stream without frames
+	data := PrepareRawFile("some/path", bytes.Repeat([]byte("some data here"), 2*FRAME_SIZE/10))
+	var unframed bytes.Buffer
+	w := zlib.NewWriter(&unframed)
+	w.Write(data)
+	w.Close()
+	assert.NotEqual(t, ZLibPack(data), unframed.Bytes())
+
+	hash := SHA256.Sum(data)
+	path := storage.MakePathFromHash(hash)
+	assert.NoError(t, storage.PrepareParentFolders(path))
+	assert.NoError(t, storage.Write(path, unframed.Bytes()))
+
+	stored, err := storage.WriteFromRawData(data)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, stored)
+	_, err = storage.WriteFromReader(bytes.NewReader(data))
+	assert.NoError(t, err)
+
+	// the file is replaced, so it matches the frame index
+	r, err := storage.OpenRange(hash, FRAME_SIZE+10, 100, true)
+	assert.NoError(t, err)
+	part, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	assert.Equal(t, data[FRAME_SIZE+10:FRAME_SIZE+110], part)
+
+	// file with different content is not overwritten
+	other := PrepareRawFile("some/path", []byte("some data here"))
+	otherPath := storage.MakePathFromHash(SHA256.Sum(other))
+	assert.NoError(t, storage.PrepareParentFolders(otherPath))
+	assert.NoError(t, storage.Write(otherPath, ZLibPack([]byte("other data"))))
+	_, err = storage.WriteFromRawData(other)
+	assert.Error(t, err)
+}
+
 //=============//
 // RemoveByKey //
 //=============//
@@ -142,3 +193,340 @@ func TestRemoveByHashNonExisting(t *testing.T) {
 	assert.Error(t, err)
 
 }
+
+//============//
+// OpenByHash //
+//============//
+
+func TestOpenByHash(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	data := bytes.Repeat([]byte("some data here"), 10000)
+	hash, err := storage.WriteFromRawData(data)
+	assert.NoError(t, err)
+
+	reader, err := storage.OpenByHash(hash, true)
+	assert.NoError(t, err)
+	unpacked, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.NoError(t, reader.Close())
+	assert.Equal(t, data, unpacked)
+
+	reader, err = storage.OpenByHash(hash, false)
+	assert.NoError(t, err)
+	packed, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.NoError(t, reader.Close())
+	assert.Equal(t, ZLibPack(data), packed)
+}
+
+func TestOpenByHashNonExisting(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	_, err = storage.OpenByHash("SOME_NON_EXISTING_HASH", true)
+	assert.Error(t, err)
+}
+
+//=================//
+// WriteFromReader //
+//=================//
+
+func TestWriteFromReader(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	content := bytes.Repeat([]byte("some data here"), 10000)
+	hash, err := storage.WriteFromReader(RawFileReader("some/path", bytes.NewReader(content)))
+	assert.NoError(t, err)
+
+	// streaming write must be compatible with in-memory one
+	expectedHash, err := storage.WriteFromRawData(PrepareRawFile("some/path", content))
+	assert.NoError(t, err)
+	assert.Equal(t, expectedHash, hash)
+
+	// writing duplicate
+	_, err = storage.WriteFromReader(RawFileReader("some/path", bytes.NewReader(content)))
+	assert.NoError(t, err)
+
+	file, err := storage.read(storage.MakePathFromHash(hash))
+	assert.NoError(t, err)
+	assert.Equal(t, "some/path", file.Path)
+	assert.Equal(t, content, file.Data)
+
+	// no temporary files are left behind
+	leftovers, err := filepath.Glob(filepath.Join(root, STAGING_DIR, TEMP_PATTERN))
+	assert.NoError(t, err)
+	assert.Empty(t, leftovers)
+}
+
+func TestWriteCompressed(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	data := PrepareRawFile("some/path", []byte("some data here"))
+	hash := SHA256.Sum(data)
+	compressed := ZLibPack(data)
+
+	err = storage.WriteCompressed(hash, bytes.NewReader(compressed))
+	assert.NoError(t, err)
+
+	stored, err := storage.GetByHash(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, compressed, stored)
+}
+
+func TestWriteCompressedVerified(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	data := PrepareRawFile("some/path", []byte("some data here"))
+	hash := SHA256.Sum(data)
+	assert.NoError(t, storage.WriteCompressedVerified(hash, bytes.NewReader(ZLibPack(data))))
+	assert.True(t, storage.Has(storage.MakePathFromHash(hash)))
+
+	// data which doesn't match the hash is not stored
+	other := SHA256.Sum([]byte("other data"))
+	err = storage.WriteCompressedVerified(other, bytes.NewReader(ZLibPack(data)))
+	assert.ErrorIs(t, err, ErrCorrupted)
+	assert.False(t, storage.Has(storage.MakePathFromHash(other)))
+
+	err = storage.WriteCompressedVerified(other, bytes.NewReader([]byte("not zlib")))
+	assert.ErrorIs(t, err, ErrCorrupted)
+	assert.False(t, storage.Has(storage.MakePathFromHash(other)))
+
+	// no temporary files are left behind
+	leftovers, err := filepath.Glob(filepath.Join(root, STAGING_DIR, TEMP_PATTERN))
+	assert.NoError(t, err)
+	assert.Empty(t, leftovers)
+}
+
+//=========//
+// Staging //
+//=========//
+
+func TestStagingSweep(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	// simulate a write interrupted by a crash
+	leftover := filepath.Join(root, STAGING_DIR, "tmp-interrupted")
+	err = os.WriteFile(leftover, []byte("partial data"), os.ModePerm)
+	assert.NoError(t, err)
+
+	storage, err = sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	_, err = os.Stat(leftover)
+	assert.True(t, os.IsNotExist(err), "leftover staging file not removed")
+}
+
+func TestWriteReplacesAtomically(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	path := filepath.Join(root, "some_file")
+	assert.NoError(t, storage.Write(path, []byte("old content")))
+	assert.NoError(t, storage.Write(path, []byte("new content")))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("new content"), content)
+
+	leftovers, err := os.ReadDir(filepath.Join(root, STAGING_DIR))
+	assert.NoError(t, err)
+	assert.Empty(t, leftovers)
+}
+
+//===========//
+// OpenRange //
+//===========//
+
+func TestOpenRange(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	framed := sampleData(FRAME_SIZE*3 + 123)
+	framedHash, err := storage.WriteFromRawData(framed)
+	assert.NoError(t, err)
+
+	// file without frame index
+	small := sampleData(FRAME_SIZE / 2)
+	smallHash, err := storage.WriteFromRawData(small)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		hash   string
+		data   []byte
+		offset int64
+		length int64
+	}{
+		{name: "Whole File", hash: framedHash, data: framed, offset: 0, length: 0},
+		{name: "Inside Frame", hash: framedHash, data: framed, offset: 10, length: 100},
+		{name: "Across Frames", hash: framedHash, data: framed, offset: FRAME_SIZE - 50, length: FRAME_SIZE + 100},
+		{name: "Frame Start", hash: framedHash, data: framed, offset: FRAME_SIZE * 2, length: 10},
+		{name: "Up To The End", hash: framedHash, data: framed, offset: FRAME_SIZE*3 + 100, length: 0},
+		{name: "Length Past The End", hash: framedHash, data: framed, offset: FRAME_SIZE * 3, length: FRAME_SIZE},
+		{name: "Offset Past The End", hash: framedHash, data: framed, offset: FRAME_SIZE * 4, length: 10},
+		{name: "No Frame Index", hash: smallHash, data: small, offset: 1000, length: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := []byte{}
+			if tt.offset < int64(len(tt.data)) {
+				end := int64(len(tt.data))
+				if tt.length > 0 && tt.offset+tt.length < end {
+					end = tt.offset + tt.length
+				}
+				expected = tt.data[tt.offset:end]
+			}
+
+			reader, err := storage.OpenRange(tt.hash, tt.offset, tt.length, true)
+			assert.NoError(t, err)
+			result, err := io.ReadAll(reader)
+			assert.NoError(t, err)
+			assert.NoError(t, reader.Close())
+			assert.True(t, bytes.Equal(expected, result))
+		})
+	}
+}
+
+func TestOpenRangeCompressed(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	hash, err := storage.WriteFromRawData([]byte("some data here"))
+	assert.NoError(t, err)
+	packed, err := storage.GetByHash(hash)
+	assert.NoError(t, err)
+
+	reader, err := storage.OpenRange(hash, 2, 5, false)
+	assert.NoError(t, err)
+	result, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.NoError(t, reader.Close())
+	assert.Equal(t, packed[2:7], result)
+}
+
+//======//
+// Stat //
+//======//
+
+func TestStat(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	data := PrepareRawFile("some/path", []byte("some data here"))
+	hash, err := storage.WriteFromRawData(data)
+	assert.NoError(t, err)
+	assert.NoError(t, storage.AddNewPath("key1", hash))
+
+	packed, err := storage.GetByHash(hash)
+	assert.NoError(t, err)
+
+	info, err := storage.Stat(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, info.Hash)
+	assert.Equal(t, int64(len(packed)), info.PackedSize)
+	assert.Equal(t, int64(len(data)), info.RawSize)
+	assert.Equal(t, "some/path", info.Path)
+	assert.Equal(t, []string{"key1"}, info.Keys)
+	assert.False(t, info.CreatedAt.IsZero())
+
+	_, err = storage.Stat(SHA256.Sum([]byte("missing")))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	_, err = storage.Stat("0000000000")
+	assert.True(t, errors.Is(err, ErrInvalidHash))
+}
+
+func TestStatCompressed(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	data := PrepareRawFile("some/path", bytes.Repeat([]byte("some data here"), 10000))
+	hash := SHA256.Sum(data)
+	compressed := ZLibPack(data)
+	assert.NoError(t, storage.WriteCompressed(hash, bytes.NewReader(compressed)))
+
+	info, err := storage.Stat(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(compressed)), info.PackedSize)
+	assert.Equal(t, int64(len(data)), info.RawSize)
+	assert.Equal(t, "some/path", info.Path)
+
+	// data which can't be unpacked is stored with unknown size
+	invalid := SHA256.Sum([]byte("not zlib"))
+	assert.NoError(t, storage.WriteCompressed(invalid, bytes.NewReader([]byte("not zlib"))))
+	info, err = storage.Stat(invalid)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(UNKNOWN_SIZE), info.RawSize)
+	assert.Equal(t, int64(len("not zlib")), info.PackedSize)
+}
+
+func TestStatWithoutMetadata(t *testing.T) {
+	const root = "stash-test"
+	defer utils.CleanUp(root)
+
+	storage, err := sampleStorage(root)
+	assert.NotNil(t, storage)
+	assert.NoError(t, err)
+
+	hash, err := storage.WriteFromRawData(PrepareRawFile("some/path", []byte("some data here")))
+	assert.NoError(t, err)
+	assert.NoError(t, storage.db.RemoveBlob(hash))
+
+	info, err := storage.Stat(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, "some/path", info.Path)
+	assert.Equal(t, int64(UNKNOWN_SIZE), info.RawSize)
+	assert.Empty(t, info.Keys)
+}
@@ -3,17 +3,95 @@ package dht
 import (
 	"fmt"
 	"hash/fnv"
+	"math"
 	"net"
+	"sort"
 	"sync"
+	"time"
 )
 
+// DEFAULT_WEIGHT is the weight of nodes created by NewNode
+const DEFAULT_WEIGHT = 1.0
+
 type Node struct {
+	// ID is a persistent identifier of the node, which is kept when the address
+	// of the node changes. Nodes without ID are identified by their address
+	ID string
+	// Name is an optional human-readable name of the node
+	Name  string
 	Addr  net.Addr
 	Alive bool
+	// Weight is the relative capacity of the node. The share of keys
+	// the node gets on the ring is proportional to its weight,
+	// e.g. a node with weight 2 gets twice the keys of a node with weight 1
+	Weight float64
+	// Zone is the failure domain of the node (e.g. a rack or a data center).
+	// Replicas of a key are spread across distinct zones whenever possible.
+	// Nodes without zone are treated as separate failure domains
+	Zone string
+	// State is the lifecycle state of the node, which decides whether the node
+	// owns keys, serves reads and takes writes. Zero value is NodeActive
+	State NodeState
+
+	// Suspicion is the suspicion level (phi) of the failure detector of the node.
+	// It grows while the node is not heard from, a node becomes suspected
+	// once it doesn't respond to pings and its suspicion exceeds the threshold
+	Suspicion float64
+	// LastSeen is the time the node was last heard from, zero if it never was
+	LastSeen time.Time
+	// Flaps is the number of times the node became available again after a failure
+	Flaps int
 }
 
 func NewNode(addr net.Addr) *Node {
-	return &Node{addr, false}
+	return NewWeightedNode(addr, DEFAULT_WEIGHT)
+}
+
+// NewWeightedNode creates Node with the given weight.
+// Non-positive weights are replaced with DEFAULT_WEIGHT
+func NewWeightedNode(addr net.Addr, weight float64) *Node {
+	if weight <= 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+		weight = DEFAULT_WEIGHT
+	}
+	return &Node{Addr: addr, Alive: false, Weight: weight}
+}
+
+// ResolveNode creates Node with the given TCP address, weight and zone
+func ResolveNode(address string, weight float64, zone string) (*Node, error) {
+	addr, err := net.ResolveTCPAddr("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	node := NewWeightedNode(addr, weight)
+	node.Zone = zone
+	return node, nil
+}
+
+// Key returns the key the node is placed by: the ID of the node,
+// or the address if the ID is not known
+func (n *Node) Key() string {
+	if n.ID != "" {
+		return n.ID
+	}
+	return n.Addr.String()
+}
+
+// SameAs returns true if other is the same node (with the same ID) with
+// the same address, name, weight and zone, so adding it again changes nothing
+func (n *Node) SameAs(other *Node) bool {
+	return n.ID == other.ID && n.Addr.String() == other.Addr.String() &&
+		n.Name == other.Name && n.Weight == other.Weight && n.Zone == other.Zone
+}
+
+// String returns the name (or the key) of the node along with its address
+func (n *Node) String() string {
+	if n.Name != "" {
+		return fmt.Sprintf("%s (%s)", n.Name, n.Addr)
+	}
+	if n.ID != "" {
+		return fmt.Sprintf("%s (%s)", n.ID, n.Addr)
+	}
+	return n.Addr.String()
 }
 
 func HashKey(key string) int {
@@ -25,64 +103,280 @@ func HashKey(key string) int {
 	return int(h.Sum64())
 }
 
+// mix64 is the SplitMix64 finalizer, it mixes all the bits of x,
+// so hashes of similar strings get unrelated values
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// DEFAULT_VIRTUAL_NODES is the number of points a single node
+// takes on the ring created by NewHashRing
+const DEFAULT_VIRTUAL_NODES = 1
+
 type HashRing struct {
-	mu    sync.Mutex
-	ids   []int
-	nodes map[int]*Node
+	mu sync.Mutex
+	// vnodes is the number of points (virtual nodes) every node takes on the ring
+	vnodes int
+	// ids are sorted positions of all the virtual nodes on the ring
+	ids []int
+	// points maps positions of virtual nodes to their nodes
+	points map[int]*Node
+	// nodes maps ids of nodes (hashes of their keys) to the nodes
+	nodes members
 }
 
-// NewHashRing creates empty HashRing
+// NewHashRing creates empty HashRing, where every node takes a single point
 func NewHashRing() *HashRing {
+	return NewHashRingWithVNodes(DEFAULT_VIRTUAL_NODES)
+}
+
+// NewHashRingWithVNodes creates empty HashRing, where every node takes
+// vnodes points (virtual nodes). More virtual nodes spread the keyspace between
+// nodes more evenly. All nodes of the cluster must use the same number of
+// virtual nodes, otherwise they would disagree on key placement.
+func NewHashRingWithVNodes(vnodes int) *HashRing {
+	if vnodes < 1 {
+		vnodes = 1
+	}
 	return &HashRing{
-		mu:    sync.Mutex{},
-		ids:   make([]int, 0),
-		nodes: make(map[int]*Node),
+		mu:     sync.Mutex{},
+		vnodes: vnodes,
+		ids:    make([]int, 0),
+		points: make(map[int]*Node),
+		nodes:  make(members),
 	}
 }
 
-// AddNode adds node to Hash Ring
+// AddNode adds node to Hash Ring along with all of its virtual nodes.
+// The number of virtual nodes is scaled by the weight of the node (see pointCount).
+// Nodes which are already on the ring are skipped, but their addresses are updated,
+// so a node which changed its address keeps its keys. A node which was known
+// only by its address is replaced once it's added with its ID.
 func (h *HashRing) AddNode(nodes ...*Node) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	for _, node := range nodes {
-		nodeKey := HashKey(node.Addr.String())
-		h.mu.Lock()
-		h.insertId(nodeKey)
-		h.nodes[nodeKey] = node
-		h.mu.Unlock()
+		stale, node := h.nodes.prepareAdd(node)
+		if node == nil {
+			continue
+		}
+		if stale != nil {
+			h.removeNode(stale)
+		}
+		h.nodes[HashKey(node.Key())] = node
+
+		for i := range h.pointCount(node) {
+			pointKey := h.vnodeKey(node, i)
+			if _, ok := h.points[pointKey]; ok { // taken by another node
+				continue
+			}
+			h.insertId(pointKey)
+			h.points[pointKey] = node
+		}
 	}
 }
 
+// RemoveNode removes node from Hash Ring along with all of its virtual nodes.
+// Nodes are matched by their ID or address
 func (h *HashRing) RemoveNode(nodes ...*Node) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	for _, node := range nodes {
-		nodeKey := HashKey(node.Addr.String())
-		index := findIndex(h.ids, nodeKey)
-		h.mu.Lock()
-		delete(h.nodes, nodeKey)
-		h.ids = append(h.ids[:index], h.ids[index+1:]...) // hmmm...
-		h.mu.Unlock()
+		if _, stored, ok := h.nodes.match(node); ok {
+			h.removeNode(stored)
+		}
+	}
+}
+
+func (h *HashRing) removeNode(stored *Node) {
+	delete(h.nodes, HashKey(stored.Key()))
+
+	ids := make([]int, 0, len(h.ids))
+	for _, id := range h.ids {
+		if h.points[id] == stored {
+			delete(h.points, id)
+			continue
+		}
+		ids = append(ids, id)
 	}
+	h.ids = ids
 }
 
-// GetNodeForKey returns Node corresponding to given key.
+// GetNodeForKey returns Node corresponding to given key,
+// which is the node of the first point clockwise from the key on the ring
+// owning keys (see NodeState.OwnsKeys).
 // Error can occur if Node is not found
 func (h *HashRing) GetNodeForKey(key string) (*Node, error) {
-	hashedKey := HashKey(key)
-	closestNodeId := findClosestElement(h.ids, hashedKey)
-	node, ok := h.nodes[closestNodeId]
-	if !ok {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var owner *Node
+	h.walk(key)(func(node *Node) bool {
+		if !node.State.OwnsKeys() {
+			return true
+		}
+		owner = node
+		return false
+	})
+	if owner == nil {
 		return nil, fmt.Errorf("stash: DHT Node for key '%s' not found", key)
 	}
-	return node, nil
+	return owner, nil
+}
+
+// GetNodesForKey returns the preference list of the key: n distinct nodes
+// found by walking the ring clockwise from the key. The first node is the one
+// returned by GetNodeForKey, the rest of them store replicas of the key.
+// Virtual nodes of already found nodes are skipped, and nodes from zones which
+// are already in the list are skipped while there are nodes from other zones
+// (see spreadZones). If there are fewer than n nodes on the ring, all of them are returned.
+// Nodes which don't own keys are skipped.
+// Error can occur if there are no nodes owning keys
+func (h *HashRing) GetNodesForKey(key string, n int) ([]*Node, error) {
+	return h.FilterNodesForKey(key, n, OwnsKeys)
+}
+
+// FilterNodesForKey returns n distinct accepted nodes found by walking
+// the ring clockwise from the key, spread across zones (see spreadZones).
+// Error can occur if there are no accepted nodes
+func (h *HashRing) FilterNodesForKey(key string, n int, accept func(*Node) bool) ([]*Node, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	nodes := spreadZones(filterWalk(h.walk(key), accept), n)
+	if n > 0 && len(nodes) == 0 {
+		return nil, fmt.Errorf("stash: DHT Node for key '%s' not found", key)
+	}
+	return nodes, nil
+}
+
+// walk yields distinct nodes of the ring clockwise from the key
+func (h *HashRing) walk(key string) func(yield func(*Node) bool) {
+	return func(yield func(*Node) bool) {
+		if len(h.ids) == 0 {
+			return
+		}
+		start := successorIndex(h.ids, h.position(key))
+		found := make(map[*Node]bool)
+		for i := range h.ids {
+			node := h.points[h.ids[(start+i)%len(h.ids)]]
+			if found[node] {
+				continue
+			}
+			found[node] = true
+			if !yield(node) {
+				return
+			}
+		}
+	}
 }
 
-// NodeExists returns true if node exists in hash ring, false otherwise
+// SetNodeState changes the state of the node with the given ID or address.
+// Nodes keep their points on the ring in all the states, nodes which don't own
+// keys are skipped by lookups, so keys move back once they own keys again.
+// The previous state is returned, the node is replaced with its updated copy
+func (h *HashRing) SetNodeState(key string, state NodeState) (NodeState, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var previous NodeState
+	ok := h.updateNode(key, func(node *Node) {
+		previous, node.State = node.State, state
+	})
+	return previous, ok
+}
+
+// SetNodeAlive marks the node with the given ID or address as alive or not,
+// the node is replaced with its updated copy (see updateNode)
+func (h *HashRing) SetNodeAlive(key string, alive bool) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.updateNode(key, func(node *Node) { node.Alive = alive })
+}
+
+// SetNodeHealth sets the state of the failure detector of the node with the given
+// ID or address, the node is replaced with its updated copy (see updateNode)
+func (h *HashRing) SetNodeHealth(key string, suspicion float64, lastSeen time.Time, flaps int) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.updateNode(key, func(node *Node) {
+		node.Suspicion, node.LastSeen, node.Flaps = suspicion, lastSeen, flaps
+	})
+}
+
+// updateNode replaces the node with the given ID or address with its copy
+// changed by change, the points of the node are kept. The lock must be held
+func (h *HashRing) updateNode(key string, change func(*Node)) bool {
+	stale, updated := h.nodes.update(key, change)
+	if stale == nil {
+		return false
+	}
+	for id, node := range h.points {
+		if node == stale {
+			h.points[id] = updated
+		}
+	}
+	return true
+}
+
+// NodeExists returns true if node with the given ID or address
+// exists in hash ring, false otherwise
 func (h *HashRing) NodeExists(key string) bool {
-	hashedKey := HashKey(key)
-	_, ok := h.nodes[hashedKey]
-	return ok
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.nodes.exists(key)
 }
 
+// GetNodes returns physical nodes of the ring mapped by their ids,
+// virtual nodes are not included
 func (h *HashRing) GetNodes() map[int]*Node {
-	return h.nodes
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.nodes.copy()
+}
+
+// pointCount returns the number of virtual nodes the node takes on the ring,
+// which is the number of virtual nodes per node multiplied by the weight
+// of the node. Every node takes at least one point.
+func (h *HashRing) pointCount(node *Node) int {
+	weight := node.Weight
+	if weight <= 0 { // created without NewNode
+		weight = DEFAULT_WEIGHT
+	}
+	return max(1, int(math.Round(float64(h.vnodes)*weight)))
+}
+
+// vnodeKey returns position of the i-th virtual node of the node.
+// The first virtual node is placed at the position of the node key,
+// so rings with a single virtual node per node stay the same.
+func (h *HashRing) vnodeKey(node *Node, i int) int {
+	if i == 0 {
+		return h.position(node.Key())
+	}
+	return h.position(fmt.Sprintf("%s#%d", node.Key(), i))
+}
+
+// position returns position of the key on the ring.
+// FNV spreads strings which differ only in the last bytes (e.g. "key-1"
+// and "key-2" or keys of virtual nodes) poorly, so on rings with several
+// virtual nodes the hash is mixed with mix64. Rings with a single virtual
+// node keep positions of HashKey, so placement of their keys doesn't change.
+func (h *HashRing) position(key string) int {
+	if h.vnodes > 1 {
+		return int(mix64(uint64(HashKey(key))))
+	}
+	return HashKey(key)
 }
 
 func (h *HashRing) insertId(id int) {
@@ -91,6 +385,9 @@ func (h *HashRing) insertId(id int) {
 		return
 	}
 	newIdPos := findIndex(h.ids, id) + 1
+	if id < h.ids[0] { // findIndex doesn't tell apart ids before and after the first one
+		newIdPos = 0
+	}
 	newIds := make([]int, 0)
 	newIds = append(newIds, h.ids[:newIdPos]...) // append elements BEFORE new Id
 	newIds = append(newIds, id)                  // append Id
@@ -140,49 +437,25 @@ func findIndex(arr []int, target int) int {
 	return mid
 }
 
-func findClosestElement(arr []int, target int) int {
-	n := len(arr)
-
-	// base case
-	if n == 0 {
-		return 0
-	}
-	if target <= arr[0] {
-		return arr[0]
+// findSuccessor returns the first element of the sorted arr which is greater
+// than or equal to target. If there is no such element, the search wraps around
+// the ring and the first element is returned. False is returned for empty arr.
+//
+// Example: arr = [1, 3, 8, 11, 19], target = 12, result = 19
+// Example: arr = [1, 3, 8, 11, 19], target = 20, result = 1
+func findSuccessor(arr []int, target int) (int, bool) {
+	if len(arr) == 0 {
+		return 0, false
 	}
-	if target >= arr[n-1] {
-		return arr[n-1]
-	}
-
-	// well... binary search AGAIN
-	low := 0
-	high := n - 1
-	mid := 0
-	for low < high {
-		mid = (low + high) / 2
-
-		if arr[mid] == target {
-			return arr[mid]
-		}
-
-		if target < arr[mid] {
-			if mid > 0 && target > arr[mid-1] {
-				return getClosest(target, arr[mid-1], arr[mid])
-			}
-			high = mid - 1
-		} else if target > arr[mid] {
-			if mid < n-1 && target < arr[mid+1] {
-				return getClosest(target, arr[mid], arr[mid+1])
-			}
-			low = mid + 1
-		}
-	}
-	return mid
+	return arr[successorIndex(arr, target)], true
 }
 
-func getClosest(target, val1, val2 int) int {
-	if target-val1 >= val2-target {
-		return val2
+// successorIndex returns the index of the element returned by findSuccessor.
+// arr must not be empty
+func successorIndex(arr []int, target int) int {
+	i := sort.SearchInts(arr, target)
+	if i == len(arr) { // wrap around
+		i = 0
 	}
-	return val1
+	return i
 }
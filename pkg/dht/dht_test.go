@@ -1,9 +1,13 @@
 package dht
 
 import (
+	"fmt"
 	"github.com/stretchr/testify/assert"
+	"math/rand"
 	"net"
+	"sort"
 	"testing"
+	"testing/quick"
 )
 
 func TestGetNodeForKey(t *testing.T) {
@@ -63,6 +67,26 @@ func TestFindInsertIndexDistance(t *testing.T) {
 	assert.Equal(t, expected, result2)
 }
 
+func TestFindSuccessor(t *testing.T) {
+	arr := []int{1, 3, 8, 11, 19}
+	cases := map[int]int{
+		-5: 1,
+		1:  1,
+		2:  3,
+		12: 19,
+		19: 19,
+		20: 1, // wraps around
+	}
+	for target, expected := range cases {
+		result, ok := findSuccessor(arr, target)
+		assert.True(t, ok)
+		assert.Equal(t, expected, result, "successor of %d", target)
+	}
+
+	_, ok := findSuccessor(nil, 12)
+	assert.False(t, ok)
+}
+
 func TestInsertId(t *testing.T) {
 	newId := 12
 	arr := []int{1, 3, 8, 11, 19, 20, 24}
@@ -80,3 +104,276 @@ func TestInsertIdEmptyArr(t *testing.T) {
 	hashRing.insertId(newId)
 	assert.Equal(t, expected, hashRing.ids)
 }
+
+func TestInsertIdBeforeFirst(t *testing.T) {
+	arr := []int{3, 8, 11}
+	expected := []int{1, 3, 8, 11}
+	hashRing := HashRing{ids: arr, nodes: nil}
+	hashRing.insertId(1)
+	assert.Equal(t, expected, hashRing.ids)
+}
+
+func makeNodes(t testing.TB, count int) []*Node {
+	nodes := make([]*Node, 0, count)
+	for i := range count {
+		addr, err := net.ResolveTCPAddr("tcp", fmt.Sprintf("10.0.0.%d:5555", i+1))
+		assert.NoError(t, err, "error in resolving tcp address")
+		nodes = append(nodes, NewNode(addr))
+	}
+	return nodes
+}
+
+// distributionSkew returns the ratio between the number of keys
+// owned by the most loaded node and the average number of keys per node
+func distributionSkew(t *testing.T, ring *HashRing, keys int) float64 {
+	owned := make(map[*Node]int)
+	for i := range keys {
+		node, err := ring.GetNodeForKey(fmt.Sprintf("key-%d", i))
+		assert.NoError(t, err)
+		owned[node]++
+	}
+
+	maxOwned := 0
+	for _, count := range owned {
+		maxOwned = max(maxOwned, count)
+	}
+	return float64(maxOwned) / (float64(keys) / float64(len(ring.GetNodes())))
+}
+
+func TestVirtualNodesDistribution(t *testing.T) {
+	const keys = 50000
+	nodes := makeNodes(t, 5)
+
+	single := NewHashRing()
+	single.AddNode(nodes...)
+	virtual := NewHashRingWithVNodes(200)
+	virtual.AddNode(nodes...)
+
+	assert.Len(t, virtual.GetNodes(), 5)
+	assert.Len(t, virtual.ids, 5*200)
+
+	singleSkew := distributionSkew(t, single, keys)
+	virtualSkew := distributionSkew(t, virtual, keys)
+	t.Logf("skew with a single point per node: %.2f, with virtual nodes: %.2f", singleSkew, virtualSkew)
+	assert.Less(t, virtualSkew, 1.2)
+	assert.Less(t, virtualSkew, singleSkew)
+}
+
+func TestRemoveNodeWithVirtualNodes(t *testing.T) {
+	nodes := makeNodes(t, 3)
+	ring := NewHashRingWithVNodes(50)
+	ring.AddNode(nodes...)
+	ring.AddNode(nodes[0]) // already on the ring
+	assert.Len(t, ring.ids, 3*50)
+
+	ring.RemoveNode(nodes[1])
+	assert.Len(t, ring.GetNodes(), 2)
+	assert.Len(t, ring.ids, 2*50)
+	assert.Len(t, ring.points, 2*50)
+	assert.False(t, ring.NodeExists(nodes[1].Addr.String()))
+
+	for i := range 1000 {
+		node, err := ring.GetNodeForKey(fmt.Sprintf("key-%d", i))
+		assert.NoError(t, err)
+		assert.NotEqual(t, nodes[1], node)
+	}
+}
+
+func TestWeightedNodes(t *testing.T) {
+	const keys = 50000
+	nodes := makeNodes(t, 3)
+	nodes[0].Weight = 2 // e.g. 4 TB disk next to 2 TB ones
+
+	ring := NewHashRingWithVNodes(200)
+	ring.AddNode(nodes...)
+	assert.Len(t, ring.ids, 4*200)
+
+	owned := make(map[*Node]int)
+	for i := range keys {
+		node, err := ring.GetNodeForKey(fmt.Sprintf("key-%d", i))
+		assert.NoError(t, err)
+		owned[node]++
+	}
+	for _, node := range nodes[1:] {
+		ratio := float64(owned[nodes[0]]) / float64(owned[node])
+		t.Logf("keys of weighted node: %d, of %s: %d", owned[nodes[0]], node.Addr, owned[node])
+		assert.InDelta(t, 2.0, ratio, 0.3)
+	}
+}
+
+func TestNewWeightedNode(t *testing.T) {
+	addr, err := net.ResolveTCPAddr("tcp", "10.0.0.1:5555")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2.5, NewWeightedNode(addr, 2.5).Weight)
+	assert.Equal(t, DEFAULT_WEIGHT, NewWeightedNode(addr, 0).Weight)
+	assert.Equal(t, DEFAULT_WEIGHT, NewWeightedNode(addr, -1).Weight)
+	assert.Equal(t, DEFAULT_WEIGHT, NewNode(addr).Weight)
+
+	ring := NewHashRingWithVNodes(10)
+	assert.Equal(t, 1, ring.pointCount(NewWeightedNode(addr, 0.01))) // at least one point
+	assert.Equal(t, 15, ring.pointCount(NewWeightedNode(addr, 1.5)))
+}
+
+// quickConfig returns settings of property checks, the source of random
+// values is fixed to keep the statistical bounds from flaking
+func quickConfig() *quick.Config {
+	return &quick.Config{MaxCount: 30, Rand: rand.New(rand.NewSource(1))}
+}
+
+// ringMoves returns the keys which are owned by different nodes on the two rings
+func ringMoves(t *testing.T, before, after *HashRing, keys int) map[string][2]*Node {
+	moved := make(map[string][2]*Node)
+	for i := range keys {
+		key := fmt.Sprintf("key-%d", i)
+		oldNode, err := before.GetNodeForKey(key)
+		assert.NoError(t, err)
+		newNode, err := after.GetNodeForKey(key)
+		assert.NoError(t, err)
+		if oldNode != newNode {
+			moved[key] = [2]*Node{oldNode, newNode}
+		}
+	}
+	return moved
+}
+
+func TestKeyMovementOnAddNode(t *testing.T) {
+	const keys = 10000
+	property := func(seed int64) bool {
+		r := rand.New(rand.NewSource(seed))
+		count := 2 + r.Intn(14)
+		nodes := makeNodes(t, count+1)
+		vnodes := 1 + r.Intn(128)
+
+		before := NewHashRingWithVNodes(vnodes)
+		before.AddNode(nodes[:count]...)
+		after := NewHashRingWithVNodes(vnodes)
+		after.AddNode(nodes[:count]...)
+		after.AddNode(nodes[count])
+		if !sort.IntsAreSorted(after.ids) {
+			return false
+		}
+
+		moved := ringMoves(t, before, after, keys)
+		for _, move := range moved {
+			if move[1] != nodes[count] { // keys only move to the new node
+				return false
+			}
+		}
+
+		// the new node takes about 1/N of keys, the bound is loose
+		// since a few virtual nodes don't split the ring evenly
+		expected := float64(keys) / float64(count+1)
+		return float64(len(moved)) < expected*(2+8/float64(vnodes))
+	}
+	assert.NoError(t, quick.Check(property, quickConfig()))
+}
+
+func TestKeyMovementOnRemoveNode(t *testing.T) {
+	const keys = 10000
+	property := func(seed int64) bool {
+		r := rand.New(rand.NewSource(seed))
+		count := 2 + r.Intn(14)
+		nodes := makeNodes(t, count)
+		removed := nodes[r.Intn(count)]
+		vnodes := 1 + r.Intn(128)
+
+		before := NewHashRingWithVNodes(vnodes)
+		before.AddNode(nodes...)
+		after := NewHashRingWithVNodes(vnodes)
+		after.AddNode(nodes...)
+		after.RemoveNode(removed)
+
+		moved := ringMoves(t, before, after, keys)
+		for _, move := range moved {
+			if move[0] != removed { // only keys of the removed node move
+				return false
+			}
+		}
+		for i := range keys {
+			key := fmt.Sprintf("key-%d", i)
+			node, _ := before.GetNodeForKey(key)
+			if _, ok := moved[key]; node == removed && !ok {
+				return false
+			}
+		}
+		return true
+	}
+	assert.NoError(t, quick.Check(property, quickConfig()))
+}
+
+func TestKeyMovementWithVirtualNodes(t *testing.T) {
+	const keys = 50000
+	const vnodes = 200
+	for count := 2; count <= 16; count *= 2 {
+		nodes := makeNodes(t, count+1)
+		before := NewHashRingWithVNodes(vnodes)
+		before.AddNode(nodes[:count]...)
+		after := NewHashRingWithVNodes(vnodes)
+		after.AddNode(nodes...)
+
+		moved := ringMoves(t, before, after, keys)
+		fraction := float64(len(moved)) / keys
+		expected := 1 / float64(count+1)
+		t.Logf("%d -> %d nodes: moved %.3f of keys, expected %.3f", count, count+1, fraction, expected)
+		assert.InDelta(t, expected, fraction, expected*0.2)
+	}
+}
+
+// handBuiltRing returns a ring with the virtual nodes at the given positions
+func handBuiltRing(points map[int]*Node) *HashRing {
+	ring := &HashRing{vnodes: 1, ids: make([]int, 0), points: points, nodes: make(map[int]*Node)}
+	for id, node := range points {
+		ring.ids = append(ring.ids, id)
+		ring.nodes[HashKey(node.Addr.String())] = node
+	}
+	sort.Ints(ring.ids)
+	return ring
+}
+
+func TestGetNodesForKeyWalksClockwise(t *testing.T) {
+	const key = "some_key"
+	at := HashKey(key)
+	nodes := makeNodes(t, 3)
+	a, b, c := nodes[0], nodes[1], nodes[2]
+
+	ring := handBuiltRing(map[int]*Node{
+		at - 10: a,
+		at + 5:  b,
+		at + 7:  b, // second virtual node of b is skipped
+		at + 20: c,
+		at + 30: a,
+	})
+	preference, err := ring.GetNodesForKey(key, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Node{b, c, a}, preference)
+
+	preference, err = ring.GetNodesForKey(key, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Node{b, c}, preference)
+
+	// more replicas than nodes
+	preference, err = ring.GetNodesForKey(key, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Node{b, c, a}, preference)
+
+	// the key is after all the points, so the walk wraps around
+	ring = handBuiltRing(map[int]*Node{
+		at - 30: c,
+		at - 20: a,
+		at - 10: b,
+	})
+	preference, err = ring.GetNodesForKey(key, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Node{c, a, b}, preference)
+
+	// the key is exactly at a point
+	ring = handBuiltRing(map[int]*Node{
+		at - 5: a,
+		at:     c,
+		at + 5: b,
+	})
+	preference, err = ring.GetNodesForKey(key, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Node{c, b, a}, preference)
+}
@@ -0,0 +1,118 @@
+package dht
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RING_EPOCH_FILE is the name of the file storing the ring epoch of the node
+const RING_EPOCH_FILE = "ring-epoch"
+
+// Epoch is the ring epoch of a node: the version of the view of the nodes
+// the node has, along with the digest of the view (see MembershipDigest).
+//
+// The version grows only when the view changes, and it's moved forward to the
+// versions of newer epochs once their nodes are pulled, so the versions order
+// the views of the cluster. Nodes with the same view may have different versions,
+// the digests tell them apart.
+type Epoch struct {
+	Version uint64
+	// Digest is zero if it's unknown, then the epochs are compared by versions only
+	Digest uint64
+}
+
+// Same returns true if the epochs are of the same view of the nodes
+func (e Epoch) Same(other Epoch) bool {
+	return e.Digest != 0 && e.Digest == other.Digest
+}
+
+// Newer returns true if the epoch is of another view of the nodes with a greater version
+func (e Epoch) Newer(other Epoch) bool {
+	return !e.Same(other) && e.Version > other.Version
+}
+
+// Older returns true if the epoch is of another view of the nodes with a lower version
+func (e Epoch) Older(other Epoch) bool {
+	return other.Newer(e)
+}
+
+func (e Epoch) String() string {
+	return fmt.Sprintf("%d/%016x", e.Version, e.Digest)
+}
+
+// MembershipDigest returns the digest of the nodes, which is the same on every node
+// placing keys on the same nodes: it covers the keys (see Node.Key), the states,
+// the weights and the zones of the nodes regardless of their order.
+// Names, addresses of identified nodes and liveness don't change placement,
+// so they are not covered.
+func MembershipDigest(nodes map[int]*Node) uint64 {
+	entries := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		entries = append(entries, fmt.Sprintf("%s|%s|%s|%s",
+			node.Key(), node.State, strconv.FormatFloat(node.Weight, 'g', -1, 64), node.Zone))
+	}
+	sort.Strings(entries)
+
+	h := fnv.New64a()
+	for _, entry := range entries {
+		h.Write([]byte(entry))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// LoadRingEpoch returns the ring epoch stored in RING_EPOCH_FILE in dir.
+// If the file doesn't exist (e.g. on the first start), the epoch is zero.
+// Files saved without the digest are loaded with zero digest.
+func LoadRingEpoch(dir string) (Epoch, error) {
+	const op = "dht.epoch.LoadRingEpoch"
+
+	data, err := os.ReadFile(filepath.Join(dir, RING_EPOCH_FILE))
+	if errors.Is(err, os.ErrNotExist) {
+		return Epoch{}, nil
+	}
+	if err != nil {
+		return Epoch{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 || len(fields) > 2 {
+		return Epoch{}, fmt.Errorf("%s: malformed ring epoch '%s'", op, strings.TrimSpace(string(data)))
+	}
+	var epoch Epoch
+	epoch.Version, err = strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return Epoch{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(fields) == 2 {
+		epoch.Digest, err = strconv.ParseUint(fields[1], 16, 64)
+		if err != nil {
+			return Epoch{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return epoch, nil
+}
+
+// SaveRingEpoch stores the ring epoch in RING_EPOCH_FILE in dir,
+// so the epoch doesn't go back after a restart
+func SaveRingEpoch(dir string, epoch Epoch) error {
+	const op = "dht.epoch.SaveRingEpoch"
+
+	// write the epoch atomically, so a crash doesn't leave an empty file
+	path := filepath.Join(dir, RING_EPOCH_FILE)
+	tmpPath := path + ".tmp"
+	data := fmt.Sprintf("%d %016x\n", epoch.Version, epoch.Digest)
+	if err := os.WriteFile(tmpPath, []byte(data), 0644); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
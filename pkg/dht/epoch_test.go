@@ -0,0 +1,78 @@
+package dht
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadRingEpoch(t *testing.T) {
+	dir := t.TempDir()
+
+	epoch, err := LoadRingEpoch(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, Epoch{}, epoch)
+
+	assert.NoError(t, SaveRingEpoch(dir, Epoch{Version: 42, Digest: 0xabcdef}))
+	epoch, err = LoadRingEpoch(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, Epoch{Version: 42, Digest: 0xabcdef}, epoch)
+
+	// epochs saved without the digest
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, RING_EPOCH_FILE), []byte("7\n"), 0644))
+	epoch, err = LoadRingEpoch(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, Epoch{Version: 7}, epoch)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, RING_EPOCH_FILE), []byte("-1\n"), 0644))
+	_, err = LoadRingEpoch(dir)
+	assert.Error(t, err)
+}
+
+func TestEpochOrder(t *testing.T) {
+	current := Epoch{Version: 5, Digest: 1}
+
+	// the same view is neither older nor newer regardless of the version
+	for _, version := range []uint64{4, 5, 6} {
+		same := Epoch{Version: version, Digest: 1}
+		assert.True(t, same.Same(current))
+		assert.False(t, same.Newer(current))
+		assert.False(t, same.Older(current))
+	}
+
+	older, newer, concurrent := Epoch{Version: 4, Digest: 2}, Epoch{Version: 6, Digest: 2}, Epoch{Version: 5, Digest: 2}
+	assert.True(t, older.Older(current))
+	assert.True(t, newer.Newer(current))
+	assert.False(t, concurrent.Older(current))
+	assert.False(t, concurrent.Newer(current))
+
+	// epochs with unknown digests are compared by versions
+	assert.True(t, Epoch{Version: 4}.Older(current))
+	assert.False(t, Epoch{Version: 5}.Same(Epoch{Version: 5}))
+}
+
+func TestMembershipDigest(t *testing.T) {
+	nodes := makeZonedNodes(t, "rack-1", "rack-2", "")
+	ring := NewHashRing()
+	ring.AddNode(nodes...)
+	digest := MembershipDigest(ring.GetNodes())
+
+	// liveness and names don't change placement
+	other := NewHashRing()
+	for i := len(nodes) - 1; i >= 0; i-- {
+		node := *nodes[i]
+		node.Alive, node.Name = true, "renamed"
+		other.AddNode(&node)
+	}
+	assert.Equal(t, digest, MembershipDigest(other.GetNodes()))
+
+	other.SetNodeState(nodes[0].Key(), NodeDraining)
+	assert.NotEqual(t, digest, MembershipDigest(other.GetNodes()))
+	other.SetNodeState(nodes[0].Key(), NodeActive)
+	assert.Equal(t, digest, MembershipDigest(other.GetNodes()))
+
+	other.RemoveNode(nodes[1])
+	assert.NotEqual(t, digest, MembershipDigest(other.GetNodes()))
+}
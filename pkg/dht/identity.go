@@ -0,0 +1,58 @@
+package dht
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NODE_ID_FILE is the name of the file storing the ID of the node
+const NODE_ID_FILE = "node-id"
+
+// NewNodeID returns a new random ID of a node, which is a version 4 UUID
+func NewNodeID() (string, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		return "", err
+	}
+	uuid[6] = (uuid[6] & 0x0f) | 0x40 // version 4
+	uuid[8] = (uuid[8] & 0x3f) | 0x80 // variant 10
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16]), nil
+}
+
+// LoadNodeID returns the ID of the node stored in NODE_ID_FILE in dir.
+// On the first start the ID is generated and stored, so the node keeps
+// its ID (and its keys) when its address changes.
+func LoadNodeID(dir string) (string, error) {
+	const op = "dht.identity.LoadNodeID"
+
+	path := filepath.Join(dir, NODE_ID_FILE)
+	data, err := os.ReadFile(path)
+	if err == nil {
+		id := strings.TrimSpace(string(data))
+		if id == "" {
+			return "", fmt.Errorf("%s: %s is empty", op, path)
+		}
+		return id, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := NewNodeID()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	// write the ID atomically, so a crash doesn't leave an empty file
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(id+"\n"), 0644); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,35 @@
+package dht
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadNodeID(t *testing.T) {
+	dir := t.TempDir()
+
+	id, err := LoadNodeID(dir)
+	assert.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), id)
+	assert.FileExists(t, filepath.Join(dir, NODE_ID_FILE))
+
+	again, err := LoadNodeID(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, id, again)
+
+	other, err := LoadNodeID(t.TempDir())
+	assert.NoError(t, err)
+	assert.NotEqual(t, id, other)
+}
+
+func TestLoadNodeIDEmptyFile(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, NODE_ID_FILE), []byte("\n"), 0644))
+
+	_, err := LoadNodeID(dir)
+	assert.Error(t, err)
+}
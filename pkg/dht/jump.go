@@ -0,0 +1,236 @@
+package dht
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// JumpHash places keys with jump consistent hash
+// (see "A Fast, Minimal Memory, Consistent Hash Algorithm", Lamping and Veach).
+//
+// Jump hash maps a key to one of the numbered buckets in O(log N) without any
+// memory per bucket. Nodes take buckets in the order of their ids, so all the nodes
+// of the cluster agree on buckets regardless of the order nodes were added in.
+// Key movement is minimal only when the added (or removed) node has the largest id,
+// otherwise buckets of the following nodes are shifted and more keys move.
+// Node weights are rounded to the number of buckets a node takes.
+type JumpHash struct {
+	mu sync.Mutex
+	// nodes maps ids of nodes (hashes of their keys) to the nodes
+	nodes members
+	// buckets are nodes in the order of their ids, every node
+	// takes as many buckets as its rounded weight
+	buckets []*Node
+}
+
+// NewJumpHash creates empty JumpHash placement
+func NewJumpHash() *JumpHash {
+	return &JumpHash{
+		mu:      sync.Mutex{},
+		nodes:   make(members),
+		buckets: make([]*Node, 0),
+	}
+}
+
+// AddNode adds nodes to the placement. Nodes which are already added are skipped,
+// but their addresses are updated. A node which was known only by its address
+// is replaced once it's added with its ID
+func (j *JumpHash) AddNode(nodes ...*Node) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, node := range nodes {
+		stale, node := j.nodes.prepareAdd(node)
+		if node == nil {
+			continue
+		}
+		if stale != nil {
+			delete(j.nodes, HashKey(stale.Key()))
+		}
+		j.nodes[HashKey(node.Key())] = node
+	}
+	j.updateBuckets()
+}
+
+// RemoveNode removes nodes from the placement, nodes are matched by their ID or address
+func (j *JumpHash) RemoveNode(nodes ...*Node) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, node := range nodes {
+		if id, _, ok := j.nodes.match(node); ok {
+			delete(j.nodes, id)
+		}
+	}
+	j.updateBuckets()
+}
+
+// GetNodeForKey returns the node owning the bucket of the key. If the node
+// doesn't own keys (see NodeState.OwnsKeys), the nodes of the next buckets are tried.
+// Error can occur if there are no nodes owning keys
+func (j *JumpHash) GetNodeForKey(key string) (*Node, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var owner *Node
+	j.walk(key)(func(node *Node) bool {
+		if !node.State.OwnsKeys() {
+			return true
+		}
+		owner = node
+		return false
+	})
+	if owner == nil {
+		return nil, fmt.Errorf("stash: DHT Node for key '%s' not found", key)
+	}
+	return owner, nil
+}
+
+// GetNodesForKey returns the node owning the bucket of the key followed by
+// the nodes owning the next buckets, buckets of already found nodes are skipped.
+// Nodes are spread across zones (see spreadZones). If there are fewer than n nodes, all of them are returned.
+// Nodes which don't own keys are skipped.
+// Error can occur if there are no nodes owning keys
+func (j *JumpHash) GetNodesForKey(key string, n int) ([]*Node, error) {
+	return j.FilterNodesForKey(key, n, OwnsKeys)
+}
+
+// FilterNodesForKey returns n accepted nodes owning the bucket of the key
+// and the next buckets, spread across zones (see spreadZones).
+//
+// Nodes keep their buckets in all the states, so unlike the other strategies
+// the result is not the same as jump hash over the accepted nodes only:
+// keys of a skipped node go to the nodes of the next buckets.
+// Error can occur if there are no accepted nodes
+func (j *JumpHash) FilterNodesForKey(key string, n int, accept func(*Node) bool) ([]*Node, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	nodes := spreadZones(filterWalk(j.walk(key), accept), n)
+	if n > 0 && len(nodes) == 0 {
+		return nil, fmt.Errorf("stash: DHT Node for key '%s' not found", key)
+	}
+	return nodes, nil
+}
+
+// walk yields distinct nodes owning the bucket of the key and the next buckets
+func (j *JumpHash) walk(key string) func(yield func(*Node) bool) {
+	return func(yield func(*Node) bool) {
+		if len(j.buckets) == 0 {
+			return
+		}
+		start := jumpHash(uint64(HashKey(key)), len(j.buckets))
+		found := make(map[*Node]bool)
+		for i := range j.buckets {
+			node := j.buckets[(start+i)%len(j.buckets)]
+			if found[node] {
+				continue
+			}
+			found[node] = true
+			if !yield(node) {
+				return
+			}
+		}
+	}
+}
+
+// SetNodeState changes the state of the node with the given ID or address.
+// Buckets are not changed, so keys of the other nodes don't move.
+// The previous state is returned, the node is replaced with its updated copy
+func (j *JumpHash) SetNodeState(key string, state NodeState) (NodeState, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var previous NodeState
+	ok := j.updateNode(key, func(node *Node) {
+		previous, node.State = node.State, state
+	})
+	return previous, ok
+}
+
+// SetNodeAlive marks the node with the given ID or address as alive or not,
+// the node is replaced with its updated copy (see updateNode)
+func (j *JumpHash) SetNodeAlive(key string, alive bool) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.updateNode(key, func(node *Node) { node.Alive = alive })
+}
+
+// SetNodeHealth sets the state of the failure detector of the node with the given
+// ID or address, the node is replaced with its updated copy (see updateNode)
+func (j *JumpHash) SetNodeHealth(key string, suspicion float64, lastSeen time.Time, flaps int) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.updateNode(key, func(node *Node) {
+		node.Suspicion, node.LastSeen, node.Flaps = suspicion, lastSeen, flaps
+	})
+}
+
+// updateNode replaces the node with the given ID or address with its copy
+// changed by change, the buckets of the node are kept. The lock must be held
+func (j *JumpHash) updateNode(key string, change func(*Node)) bool {
+	stale, updated := j.nodes.update(key, change)
+	if stale == nil {
+		return false
+	}
+	for i, node := range j.buckets {
+		if node == stale {
+			j.buckets[i] = updated
+		}
+	}
+	return true
+}
+
+// NodeExists returns true if node with the given ID or address
+// exists in the placement, false otherwise
+func (j *JumpHash) NodeExists(key string) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.nodes.exists(key)
+}
+
+// GetNodes returns nodes of the placement mapped by their ids
+func (j *JumpHash) GetNodes() map[int]*Node {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.nodes.copy()
+}
+
+func (j *JumpHash) updateBuckets() {
+	ids := make([]int, 0, len(j.nodes))
+	for id := range j.nodes {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	buckets := make([]*Node, 0, len(ids))
+	for _, id := range ids {
+		node := j.nodes[id]
+		weight := node.Weight
+		if weight <= 0 { // created without NewNode
+			weight = DEFAULT_WEIGHT
+		}
+		for range max(1, int(math.Round(weight))) {
+			buckets = append(buckets, node)
+		}
+	}
+	j.buckets = buckets
+}
+
+// jumpHash returns the bucket of the key in range [0, buckets)
+func jumpHash(key uint64, buckets int) int {
+	b, next := int64(-1), int64(0)
+	for next < int64(buckets) {
+		b = next
+		key = key*2862933555777941757 + 1
+		next = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
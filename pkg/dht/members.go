@@ -0,0 +1,99 @@
+package dht
+
+// members maps ids of physical nodes (hashes of their keys, see Node.Key)
+// to the nodes. It's shared by the placement strategies to keep track of
+// nodes, which may be known by their address before their ID is known.
+type members map[int]*Node
+
+// match returns the member which is the same node as the given one:
+// the member with the same key, or the member with the same address.
+func (m members) match(node *Node) (int, *Node, bool) {
+	id := HashKey(node.Key())
+	if stored, ok := m[id]; ok {
+		return id, stored, true
+	}
+	address := node.Addr.String()
+	for id, stored := range m {
+		if stored.Addr.String() == address {
+			return id, stored, true
+		}
+	}
+	return 0, nil, false
+}
+
+// MatchNode returns the node of nodes (see Placement.GetNodes) which is the same
+// node as the given one: the node with the same key, or the node with the same address.
+// It returns nil if there is no such node
+func MatchNode(nodes map[int]*Node, node *Node) *Node {
+	_, stored, _ := members(nodes).match(node)
+	return stored
+}
+
+// prepareAdd decides how the node is added to the members.
+// It returns the node to add, which is nil if the node should not be added,
+// because it's already a member. A member whose address, name, weight or zone
+// is changed is replaced with its updated copy, so a node keeps its place after
+// its address is changed, and the nodes returned to callers before are not modified.
+// If the returned stale member is not nil, it must be removed before adding the node,
+// since it's the same node known by its address (or by an outdated ID).
+func (m members) prepareAdd(node *Node) (stale *Node, added *Node) {
+	_, stored, ok := m.match(node)
+	if !ok {
+		return nil, node
+	}
+	if node.ID == "" {
+		return nil, nil
+	}
+	if stored.ID != node.ID {
+		return stored, node
+	}
+	if stored.SameAs(node) {
+		return nil, nil
+	}
+	updated := *stored
+	updated.Addr, updated.Name = node.Addr, node.Name
+	updated.Weight, updated.Zone = node.Weight, node.Zone
+	return stored, &updated
+}
+
+// exists returns true if there is a member with the given ID or address
+func (m members) exists(key string) bool {
+	return m.find(key) != nil
+}
+
+// find returns the member with the given ID or address, or nil if there is none
+func (m members) find(key string) *Node {
+	if stored, ok := m[HashKey(key)]; ok {
+		return stored
+	}
+	for _, stored := range m {
+		if stored.Addr.String() == key {
+			return stored
+		}
+	}
+	return nil
+}
+
+// update replaces the member with the given ID or address with its copy
+// changed by change, so the nodes returned to callers before are not modified.
+// It returns the replaced member and its copy, which must replace the member
+// in the placement, or nils if there is no such member.
+func (m members) update(key string, change func(*Node)) (*Node, *Node) {
+	stored := m.find(key)
+	if stored == nil {
+		return nil, nil
+	}
+	updated := *stored
+	change(&updated)
+	m[HashKey(stored.Key())] = &updated
+	return stored, &updated
+}
+
+// copy returns a copy of the members, which can be used without holding the lock
+func (m members) copy() map[int]*Node {
+	nodes := make(map[int]*Node, len(m))
+	for id, node := range m {
+		nodes[id] = node
+	}
+	return nodes
+}
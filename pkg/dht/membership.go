@@ -0,0 +1,113 @@
+package dht
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// MEMBERS_FILE is the name of the file storing the known nodes of the cluster
+const MEMBERS_FILE = "members.json"
+
+// member is a node as it's stored in MEMBERS_FILE.
+// Liveness of nodes is not stored, since it's outdated after a restart
+type member struct {
+	ID      string  `json:"id,omitempty"`
+	Name    string  `json:"name,omitempty"`
+	Address string  `json:"address"`
+	Weight  float64 `json:"weight"`
+	Zone    string  `json:"zone,omitempty"`
+	// State is the name of the lifecycle state, it's empty
+	// in files saved before node states were introduced
+	State string `json:"state,omitempty"`
+}
+
+// SaveMembers stores the nodes in MEMBERS_FILE in dir, replacing the nodes
+// stored before. The file is replaced atomically, so a crash leaves
+// either the old or the new list of nodes.
+func SaveMembers(dir string, nodes []*Node) error {
+	const op = "dht.membership.SaveMembers"
+
+	members := make([]member, 0, len(nodes))
+	for _, node := range nodes {
+		members = append(members, member{
+			ID:      node.ID,
+			Name:    node.Name,
+			Address: node.Addr.String(),
+			Weight:  node.Weight,
+			Zone:    node.Zone,
+			State:   node.State.String(),
+		})
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Address < members[j].Address
+	})
+
+	data, err := json.MarshalIndent(members, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, MEMBERS_FILE+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer os.Remove(tmp.Name()) // no-op if the file was renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, MEMBERS_FILE)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// LoadMembers returns the nodes stored in MEMBERS_FILE in dir.
+// If the file doesn't exist (e.g. on the first start), no nodes are returned.
+// Loaded nodes are not alive until they pass a health check.
+// Nodes whose addresses can't be resolved anymore are skipped,
+// the errors of skipped nodes are returned along with the loaded nodes.
+func LoadMembers(dir string) ([]*Node, []error, error) {
+	const op = "dht.membership.LoadMembers"
+
+	data, err := os.ReadFile(filepath.Join(dir, MEMBERS_FILE))
+	if errors.Is(err, os.ErrNotExist) {
+		return make([]*Node, 0), nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	members := make([]member, 0)
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	nodes := make([]*Node, 0, len(members))
+	skipped := make([]error, 0)
+	for _, m := range members {
+		node, err := ResolveNode(m.Address, m.Weight, m.Zone)
+		if err != nil {
+			skipped = append(skipped, fmt.Errorf("%s: can't resolve '%s': %w", op, m.Address, err))
+			continue
+		}
+		node.ID, node.Name = m.ID, m.Name
+		node.State, err = ParseNodeState(m.State)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", op, err)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, skipped, nil
+}
@@ -0,0 +1,63 @@
+package dht
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveAndLoadMembers(t *testing.T) {
+	dir := t.TempDir()
+
+	nodes := makeZonedNodes(t, "rack-1", "rack-2", "")
+	nodes[0].ID, nodes[0].Name = "node-0", "first"
+	nodes[1].Weight = 2
+	nodes[1].Alive = true
+	nodes[2].State = NodeDraining
+	assert.NoError(t, SaveMembers(dir, nodes))
+
+	loaded, skipped, err := LoadMembers(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, skipped)
+	assert.Len(t, loaded, len(nodes))
+	for i, node := range loaded {
+		assert.Equal(t, nodes[i].ID, node.ID)
+		assert.Equal(t, nodes[i].Name, node.Name)
+		assert.Equal(t, nodes[i].Addr.String(), node.Addr.String())
+		assert.Equal(t, nodes[i].Weight, node.Weight)
+		assert.Equal(t, nodes[i].Zone, node.Zone)
+		assert.Equal(t, nodes[i].State, node.State)
+		assert.False(t, node.Alive, "liveness must not be saved")
+	}
+
+	// saved nodes are replaced
+	assert.NoError(t, SaveMembers(dir, nodes[:1]))
+	loaded, _, err = LoadMembers(dir)
+	assert.NoError(t, err)
+	assert.Len(t, loaded, 1)
+}
+
+func TestLoadMembersNoFile(t *testing.T) {
+	loaded, skipped, err := LoadMembers(t.TempDir())
+	assert.NoError(t, err)
+	assert.Empty(t, skipped)
+	assert.Empty(t, loaded)
+}
+
+func TestLoadMembersUnresolved(t *testing.T) {
+	dir := t.TempDir()
+	data := `[
+  {"id": "node-0", "address": "127.0.0.1:5555", "weight": 1, "state": "active"},
+  {"id": "node-1", "address": "127.0.0.1", "weight": 1, "state": "active"}
+]`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, MEMBERS_FILE), []byte(data), 0644))
+
+	// the node which can't be resolved is skipped
+	loaded, skipped, err := LoadMembers(dir)
+	assert.NoError(t, err)
+	assert.Len(t, skipped, 1)
+	assert.Len(t, loaded, 1)
+	assert.Equal(t, "node-0", loaded[0].ID)
+}
@@ -0,0 +1,82 @@
+package dht
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrUnknownPlacement is returned for placement strategies which are not implemented
+var ErrUnknownPlacement = errors.New("unknown placement strategy")
+
+// Names of the placement strategies accepted by NewPlacement
+const (
+	PLACEMENT_RING       = "ring"
+	PLACEMENT_RENDEZVOUS = "rendezvous"
+	PLACEMENT_JUMP       = "jump"
+)
+
+// Placement decides which node is responsible for a key.
+//
+// All nodes of the cluster must use the same placement strategy,
+// otherwise they would disagree on key placement.
+// Implementations are safe for concurrent use.
+type Placement interface {
+	// AddNode adds nodes to the placement, nodes which are already added are skipped.
+	// Nodes are placed by their keys (see Node.Key)
+	AddNode(nodes ...*Node)
+	// RemoveNode removes nodes from the placement, nodes are matched by their ID or address
+	RemoveNode(nodes ...*Node)
+	// GetNodeForKey returns Node corresponding to given key.
+	// Only nodes owning keys are considered (see NodeState.OwnsKeys).
+	// Error can occur if Node is not found
+	GetNodeForKey(key string) (*Node, error)
+	// GetNodesForKey returns up to n distinct nodes responsible for the key in the
+	// order of preference. The first one is the node returned by GetNodeForKey,
+	// the rest of them store replicas of the key.
+	// Only nodes owning keys are considered (see NodeState.OwnsKeys).
+	// Error can occur if there are no such nodes
+	GetNodesForKey(key string, n int) ([]*Node, error)
+	// FilterNodesForKey returns up to n distinct nodes for the key in the order
+	// of preference, skipping nodes which are not accepted. The result is the same
+	// as the preference list of the key placed only on the accepted nodes,
+	// e.g. FilterNodesForKey(key, n, ServesReads) returns the nodes serving reads of the key.
+	// Error can occur if there are no accepted nodes
+	FilterNodesForKey(key string, n int, accept func(*Node) bool) ([]*Node, error)
+	// SetNodeState changes the state of the node with the given ID or address
+	// and returns the previous state of the node. The node is replaced with
+	// its updated copy. False is returned if there is no such node
+	SetNodeState(key string, state NodeState) (NodeState, bool)
+	// SetNodeAlive marks the node with the given ID or address as alive or not.
+	// The node is replaced with its updated copy, so the nodes returned before
+	// are not modified. False is returned if there is no such node
+	SetNodeAlive(key string, alive bool) bool
+	// SetNodeHealth sets the state of the failure detector of the node with
+	// the given ID or address (see Node.Suspicion). The node is replaced with
+	// its updated copy. False is returned if there is no such node
+	SetNodeHealth(key string, suspicion float64, lastSeen time.Time, flaps int) bool
+	// NodeExists returns true if node with the given ID or address is added, false otherwise
+	NodeExists(key string) bool
+	// GetNodes returns a copy of nodes mapped by their ids (hashes of their keys)
+	GetNodes() map[int]*Node
+}
+
+var (
+	_ Placement = (*HashRing)(nil)
+	_ Placement = (*Rendezvous)(nil)
+	_ Placement = (*JumpHash)(nil)
+)
+
+// NewPlacement creates empty placement with the given strategy.
+// vnodes is the number of virtual nodes per node, it's used only by the hash ring.
+func NewPlacement(strategy string, vnodes int) (Placement, error) {
+	switch strategy {
+	case PLACEMENT_RING:
+		return NewHashRingWithVNodes(vnodes), nil
+	case PLACEMENT_RENDEZVOUS:
+		return NewRendezvous(), nil
+	case PLACEMENT_JUMP:
+		return NewJumpHash(), nil
+	}
+	return nil, fmt.Errorf("stash: %w: '%s'", ErrUnknownPlacement, strategy)
+}
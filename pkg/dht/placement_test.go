@@ -0,0 +1,433 @@
+package dht
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var strategies = []string{PLACEMENT_RING, PLACEMENT_RENDEZVOUS, PLACEMENT_JUMP}
+
+func newTestPlacement(t testing.TB, strategy string) Placement {
+	placement, err := NewPlacement(strategy, 128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return placement
+}
+
+func TestNewPlacement(t *testing.T) {
+	for _, strategy := range strategies {
+		placement := newTestPlacement(t, strategy)
+		_, err := placement.GetNodeForKey("key")
+		assert.Error(t, err, strategy)
+	}
+
+	_, err := NewPlacement("random", 128)
+	assert.True(t, errors.Is(err, ErrUnknownPlacement))
+}
+
+func TestPlacementStrategies(t *testing.T) {
+	const keys = 30000
+	for _, strategy := range strategies {
+		t.Run(strategy, func(t *testing.T) {
+			nodes := makeNodes(t, 4)
+			nodes[0].Weight = 2
+
+			placement := newTestPlacement(t, strategy)
+			placement.AddNode(nodes...)
+			placement.AddNode(nodes[1]) // already added
+			assert.Len(t, placement.GetNodes(), 4)
+			assert.True(t, placement.NodeExists(nodes[2].Addr.String()))
+
+			owned := make(map[*Node]int)
+			for i := range keys {
+				key := fmt.Sprintf("key-%d", i)
+				node, err := placement.GetNodeForKey(key)
+				assert.NoError(t, err)
+				again, _ := placement.GetNodeForKey(key)
+				assert.Equal(t, node, again)
+				owned[node]++
+			}
+			for _, node := range nodes[1:] {
+				ratio := float64(owned[nodes[0]]) / float64(owned[node])
+				assert.InDelta(t, 2.0, ratio, 0.4, "weighted node to %s", node.Addr)
+			}
+
+			placement.RemoveNode(nodes[0])
+			assert.False(t, placement.NodeExists(nodes[0].Addr.String()))
+			for i := range 1000 {
+				node, err := placement.GetNodeForKey(fmt.Sprintf("key-%d", i))
+				assert.NoError(t, err)
+				assert.NotEqual(t, nodes[0], node)
+			}
+		})
+	}
+}
+
+func TestRendezvousKeyMovement(t *testing.T) {
+	const keys = 10000
+	nodes := makeNodes(t, 6)
+
+	before := NewRendezvous()
+	before.AddNode(nodes[:5]...)
+	after := NewRendezvous()
+	after.AddNode(nodes...)
+
+	for i := range keys {
+		key := fmt.Sprintf("key-%d", i)
+		oldNode, _ := before.GetNodeForKey(key)
+		newNode, _ := after.GetNodeForKey(key)
+		if oldNode != newNode {
+			assert.Equal(t, nodes[5], newNode, "keys only move to the new node")
+		}
+	}
+}
+
+func TestJumpHash(t *testing.T) {
+	for key := range uint64(10000) {
+		hashed := mix64(key)
+		prev := jumpHash(hashed, 1)
+		assert.Zero(t, prev)
+		for buckets := 2; buckets <= 32; buckets++ {
+			bucket := jumpHash(hashed, buckets)
+			assert.True(t, bucket == prev || bucket == buckets-1, "keys only move to the new bucket")
+			prev = bucket
+		}
+	}
+}
+
+// BenchmarkPlacement compares placement strategies. Besides lookup latency,
+// it reports the skew of the distribution (the ratio between the number of keys
+// owned by the most loaded node and the average) and the fraction
+// of keys moved when a node is added to the cluster.
+func BenchmarkPlacement(b *testing.B) {
+	const keys = 100000
+	for _, strategy := range strategies {
+		for _, count := range []int{8, 64} {
+			b.Run(fmt.Sprintf("%s/nodes=%d", strategy, count), func(b *testing.B) {
+				nodes := makeNodes(b, count+1)
+				before := newTestPlacement(b, strategy)
+				before.AddNode(nodes[:count]...)
+				after := newTestPlacement(b, strategy)
+				after.AddNode(nodes...)
+
+				owned := make(map[*Node]int)
+				moved := 0
+				for i := range keys {
+					key := fmt.Sprintf("key-%d", i)
+					node, _ := before.GetNodeForKey(key)
+					owned[node]++
+					if newNode, _ := after.GetNodeForKey(key); newNode != node {
+						moved++
+					}
+				}
+				maxOwned := 0
+				for _, n := range owned {
+					maxOwned = max(maxOwned, n)
+				}
+
+				lookupKeys := make([]string, 1024)
+				for i := range lookupKeys {
+					lookupKeys[i] = fmt.Sprintf("lookup-%d", i)
+				}
+				b.ResetTimer()
+				for i := range b.N {
+					_, _ = before.GetNodeForKey(lookupKeys[i%len(lookupKeys)])
+				}
+				b.StopTimer()
+
+				b.ReportMetric(float64(maxOwned)/(float64(keys)/float64(count)), "skew")
+				b.ReportMetric(float64(moved)/keys, "moved")
+				b.ReportMetric(1/float64(count+1), "moved-optimal")
+			})
+		}
+	}
+}
+
+func TestGetNodesForKey(t *testing.T) {
+	for _, strategy := range strategies {
+		t.Run(strategy, func(t *testing.T) {
+			placement := newTestPlacement(t, strategy)
+			_, err := placement.GetNodesForKey("key", 3)
+			assert.Error(t, err)
+
+			nodes := makeNodes(t, 5)
+			nodes[0].Weight = 3
+			placement.AddNode(nodes...)
+
+			for i := range 1000 {
+				key := fmt.Sprintf("key-%d", i)
+				preference, err := placement.GetNodesForKey(key, 3)
+				assert.NoError(t, err)
+				assert.Len(t, preference, 3)
+
+				owner, _ := placement.GetNodeForKey(key)
+				assert.Equal(t, owner, preference[0], "the first node owns the key")
+
+				distinct := make(map[*Node]bool)
+				for _, node := range preference {
+					distinct[node] = true
+				}
+				assert.Len(t, distinct, 3, "nodes are distinct")
+			}
+
+			all, err := placement.GetNodesForKey("key", 10)
+			assert.NoError(t, err)
+			assert.Len(t, all, 5)
+			none, err := placement.GetNodesForKey("key", 0)
+			assert.NoError(t, err)
+			assert.Empty(t, none)
+		})
+	}
+}
+
+func TestPlacementNodeIdentity(t *testing.T) {
+	const keys = 1000
+	for _, strategy := range strategies {
+		t.Run(strategy, func(t *testing.T) {
+			nodes := makeNodes(t, 4)
+			for i, node := range nodes {
+				node.ID = fmt.Sprintf("node-%d", i)
+			}
+			placement := newTestPlacement(t, strategy)
+			placement.AddNode(nodes...)
+
+			owners := make(map[string]string)
+			for i := range keys {
+				key := fmt.Sprintf("key-%d", i)
+				node, _ := placement.GetNodeForKey(key)
+				owners[key] = node.ID
+			}
+
+			// node changed its address, but kept its ID and keys
+			moved := makeNodes(t, 5)[4]
+			moved.ID, moved.Name = nodes[1].ID, "moved"
+			placement.AddNode(moved)
+			assert.Len(t, placement.GetNodes(), 4)
+			assert.True(t, placement.NodeExists(moved.Addr.String()))
+			assert.True(t, placement.NodeExists(moved.ID))
+			for key, owner := range owners {
+				node, _ := placement.GetNodeForKey(key)
+				assert.Equal(t, owner, node.ID, key)
+			}
+			// the stored node is replaced with its updated copy
+			stored := placement.GetNodes()[HashKey(moved.ID)]
+			assert.NotSame(t, nodes[1], stored)
+			assert.Equal(t, moved.Addr, stored.Addr)
+			assert.Equal(t, "moved", stored.Name)
+			assert.NotEqual(t, moved.Addr, nodes[1].Addr)
+			list, _ := placement.GetNodesForKey("key", 4)
+			assert.Contains(t, list, stored)
+			assert.NotContains(t, list, nodes[1])
+
+			// node removed by its address
+			placement.RemoveNode(NewNode(moved.Addr))
+			assert.False(t, placement.NodeExists(moved.ID))
+			assert.Len(t, placement.GetNodes(), 3)
+
+			// GetNodes returns a copy
+			for id := range placement.GetNodes() {
+				delete(placement.GetNodes(), id)
+			}
+			assert.Len(t, placement.GetNodes(), 3)
+		})
+	}
+}
+
+func TestPlacementNodeReweighted(t *testing.T) {
+	const keys = 3000
+	for _, strategy := range strategies {
+		t.Run(strategy, func(t *testing.T) {
+			nodes := makeNodes(t, 3)
+			for i, node := range nodes {
+				node.ID = fmt.Sprintf("node-%d", i)
+			}
+			placement := newTestPlacement(t, strategy)
+			placement.AddNode(nodes...)
+
+			owned := func(id string) int {
+				count := 0
+				for i := range keys {
+					node, _ := placement.GetNodeForKey(fmt.Sprintf("key-%d", i))
+					if node.ID == id {
+						count++
+					}
+				}
+				return count
+			}
+			before := owned("node-0")
+
+			// node with the same ID and address changed its weight and zone
+			reweighted := NewWeightedNode(nodes[0].Addr, 3)
+			reweighted.ID, reweighted.Zone = nodes[0].ID, "zone-b"
+			placement.AddNode(reweighted)
+			assert.Len(t, placement.GetNodes(), 3)
+			stored := placement.GetNodes()[HashKey(reweighted.ID)]
+			assert.NotSame(t, nodes[0], stored)
+			assert.Equal(t, 3.0, stored.Weight)
+			assert.Equal(t, "zone-b", stored.Zone)
+			assert.Equal(t, DEFAULT_WEIGHT, nodes[0].Weight, "nodes returned before are not modified")
+			assert.Greater(t, owned("node-0"), before)
+		})
+	}
+}
+
+func TestPlacementIdentifiesNode(t *testing.T) {
+	for _, strategy := range strategies {
+		t.Run(strategy, func(t *testing.T) {
+			nodes := makeNodes(t, 3)
+			placement := newTestPlacement(t, strategy)
+			placement.AddNode(nodes...)
+
+			// node known by its address is replaced once it's added with its ID
+			identified := NewNode(nodes[0].Addr)
+			identified.ID = "node-0"
+			placement.AddNode(identified)
+			assert.Len(t, placement.GetNodes(), 3)
+			assert.True(t, placement.NodeExists("node-0"))
+			assert.Contains(t, placement.GetNodes(), HashKey("node-0"))
+			assert.NotContains(t, placement.GetNodes(), HashKey(nodes[0].Addr.String()))
+
+			// adding the node by its address again changes nothing
+			placement.AddNode(NewNode(nodes[0].Addr))
+			assert.Len(t, placement.GetNodes(), 3)
+			assert.Contains(t, placement.GetNodes(), HashKey("node-0"))
+		})
+	}
+}
+
+func TestPlacementNodeStates(t *testing.T) {
+	const keys = 1000
+	for _, strategy := range strategies {
+		t.Run(strategy, func(t *testing.T) {
+			nodes := makeNodes(t, 4)
+			placement := newTestPlacement(t, strategy)
+			placement.AddNode(nodes...)
+			// nodes are replaced with their copies on changes, so they are compared by keys
+			keyOf := func(node *Node) string { return node.Key() }
+			keysOf := func(nodes []*Node) []string {
+				keys := make([]string, 0, len(nodes))
+				for _, node := range nodes {
+					keys = append(keys, node.Key())
+				}
+				return keys
+			}
+
+			owners := make(map[string]string)
+			for i := range keys {
+				key := fmt.Sprintf("key-%d", i)
+				owner, _ := placement.GetNodeForKey(key)
+				owners[key] = keyOf(owner)
+			}
+
+			_, ok := placement.SetNodeState("unknown", NodeLeaving)
+			assert.False(t, ok)
+			previous, ok := placement.SetNodeState(nodes[0].Addr.String(), NodeJoining)
+			assert.True(t, ok)
+			assert.Equal(t, NodeActive, previous)
+			_, ok = placement.SetNodeState(nodes[1].Addr.String(), NodeDraining)
+			assert.True(t, ok)
+			_, ok = placement.SetNodeState(nodes[2].Addr.String(), NodeLeaving)
+			assert.True(t, ok)
+			assert.Len(t, placement.GetNodes(), 4, "nodes are kept in all the states")
+			assert.Equal(t, NodeActive, nodes[2].State, "nodes returned before are not modified")
+			previous, _ = placement.SetNodeState(nodes[2].Addr.String(), NodeLeaving)
+			assert.Equal(t, NodeLeaving, previous)
+
+			for key, owner := range owners {
+				node, err := placement.GetNodeForKey(key)
+				assert.NoError(t, err)
+				assert.NotEqual(t, nodes[2].Key(), keyOf(node), "leaving node doesn't own keys")
+				if owner != nodes[2].Key() {
+					assert.Equal(t, owner, keyOf(node), "keys of owning nodes don't move")
+				}
+
+				preference, _ := placement.GetNodesForKey(key, 4)
+				assert.Len(t, preference, 3)
+				assert.NotContains(t, keysOf(preference), nodes[2].Key())
+
+				reads, _ := placement.FilterNodesForKey(key, 4, ServesReads)
+				assert.ElementsMatch(t, []string{nodes[1].Key(), nodes[3].Key()}, keysOf(reads))
+				writes, _ := placement.FilterNodesForKey(key, 4, TakesWrites)
+				assert.ElementsMatch(t, []string{nodes[0].Key(), nodes[3].Key()}, keysOf(writes))
+			}
+
+			// keys move back once the node owns keys again
+			placement.SetNodeState(nodes[2].Addr.String(), NodeActive)
+			for key, owner := range owners {
+				node, _ := placement.GetNodeForKey(key)
+				assert.Equal(t, owner, keyOf(node), key)
+			}
+
+			for _, node := range nodes {
+				placement.SetNodeState(node.Addr.String(), NodeDead)
+			}
+			_, err := placement.GetNodeForKey("key")
+			assert.Error(t, err)
+			_, err = placement.GetNodesForKey("key", 3)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPlacementNodeAlive(t *testing.T) {
+	for _, strategy := range strategies {
+		t.Run(strategy, func(t *testing.T) {
+			nodes := makeNodes(t, 3)
+			placement := newTestPlacement(t, strategy)
+			placement.AddNode(nodes...)
+			owner, _ := placement.GetNodeForKey("key")
+
+			assert.False(t, placement.SetNodeAlive("unknown", true))
+			assert.True(t, placement.SetNodeAlive(owner.Addr.String(), true))
+			assert.False(t, owner.Alive, "nodes returned before are not modified")
+
+			// the updated copy keeps the place of the node
+			updated, _ := placement.GetNodeForKey("key")
+			assert.True(t, updated.Alive)
+			assert.Equal(t, owner.Addr, updated.Addr)
+			assert.Same(t, placement.GetNodes()[HashKey(owner.Key())], updated)
+			preference, _ := placement.GetNodesForKey("key", 3)
+			assert.Contains(t, preference, updated)
+			assert.NotContains(t, preference, owner)
+		})
+	}
+}
+
+func TestPlacementNodeHealth(t *testing.T) {
+	for _, strategy := range strategies {
+		t.Run(strategy, func(t *testing.T) {
+			nodes := makeNodes(t, 3)
+			placement := newTestPlacement(t, strategy)
+			placement.AddNode(nodes...)
+			lastSeen := time.Now()
+
+			assert.False(t, placement.SetNodeHealth("unknown", 1, lastSeen, 1))
+
+			// nodes are read while their health is updated
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for i := range 100 {
+					placement.SetNodeHealth(nodes[0].Addr.String(), float64(i), lastSeen, i)
+				}
+			}()
+			for range 100 {
+				for _, node := range placement.GetNodes() {
+					_ = node.Suspicion + float64(node.Flaps)
+				}
+			}
+			<-done
+
+			stored := placement.GetNodes()[HashKey(nodes[0].Key())]
+			assert.Equal(t, 99.0, stored.Suspicion)
+			assert.Equal(t, 99, stored.Flaps)
+			assert.Equal(t, lastSeen, stored.LastSeen)
+			assert.Zero(t, nodes[0].Flaps, "nodes returned before are not modified")
+		})
+	}
+}
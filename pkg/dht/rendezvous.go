@@ -0,0 +1,207 @@
+package dht
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Rendezvous places keys with rendezvous (highest random weight) hashing.
+//
+// Every node gets a score for the key, which is derived from the hashes of
+// the key and the node, and the node with the highest score owns the key.
+// Only keys of a removed node move, and an added node takes only the keys it
+// wins, so key movement is minimal without virtual nodes. Scores are scaled
+// by node weights, so the share of keys a node gets is proportional to its weight.
+// Lookups take O(N) for N nodes.
+type Rendezvous struct {
+	mu sync.Mutex
+	// nodes maps ids of nodes (hashes of their keys) to the nodes
+	nodes members
+}
+
+// NewRendezvous creates empty Rendezvous placement
+func NewRendezvous() *Rendezvous {
+	return &Rendezvous{
+		mu:    sync.Mutex{},
+		nodes: make(members),
+	}
+}
+
+// AddNode adds nodes to the placement. Nodes which are already added are skipped,
+// but their addresses are updated. A node which was known only by its address
+// is replaced once it's added with its ID
+func (r *Rendezvous) AddNode(nodes ...*Node) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, node := range nodes {
+		stale, node := r.nodes.prepareAdd(node)
+		if node == nil {
+			continue
+		}
+		if stale != nil {
+			delete(r.nodes, HashKey(stale.Key()))
+		}
+		r.nodes[HashKey(node.Key())] = node
+	}
+}
+
+// RemoveNode removes nodes from the placement, nodes are matched by their ID or address
+func (r *Rendezvous) RemoveNode(nodes ...*Node) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, node := range nodes {
+		if id, _, ok := r.nodes.match(node); ok {
+			delete(r.nodes, id)
+		}
+	}
+}
+
+// GetNodeForKey returns the node with the highest score for the key
+// among the nodes owning keys (see NodeState.OwnsKeys).
+// Error can occur if there are no such nodes
+func (r *Rendezvous) GetNodeForKey(key string) (*Node, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hashedKey := uint64(HashKey(key))
+	var owner *Node
+	ownerId, ownerScore := 0, math.Inf(-1)
+	for id, node := range r.nodes {
+		if !node.State.OwnsKeys() {
+			continue
+		}
+		score := rendezvousScore(hashedKey, uint64(id), node.Weight)
+		// ties are broken by ids, so the result doesn't depend on map order
+		if score > ownerScore || (score == ownerScore && id > ownerId) {
+			owner, ownerId, ownerScore = node, id, score
+		}
+	}
+	if owner == nil {
+		return nil, fmt.Errorf("stash: DHT Node for key '%s' not found", key)
+	}
+	return owner, nil
+}
+
+// GetNodesForKey returns n nodes owning keys with the highest scores for the key
+// in the order of their scores, spread across zones (see spreadZones).
+// If there are fewer than n nodes, all of them are returned.
+// Error can occur if there are no nodes owning keys
+func (r *Rendezvous) GetNodesForKey(key string, n int) ([]*Node, error) {
+	return r.FilterNodesForKey(key, n, OwnsKeys)
+}
+
+// FilterNodesForKey returns n accepted nodes with the highest scores for the key
+// in the order of their scores, spread across zones (see spreadZones).
+// Error can occur if there are no accepted nodes
+func (r *Rendezvous) FilterNodesForKey(key string, n int, accept func(*Node) bool) ([]*Node, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	type scoredNode struct {
+		id    int
+		score float64
+	}
+	hashedKey := uint64(HashKey(key))
+	scored := make([]scoredNode, 0, len(r.nodes))
+	for id, node := range r.nodes {
+		if !accept(node) {
+			continue
+		}
+		scored = append(scored, scoredNode{id, rendezvousScore(hashedKey, uint64(id), node.Weight)})
+	}
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].score == scored[j].score {
+			return scored[i].id > scored[j].id
+		}
+		return scored[i].score > scored[j].score
+	})
+
+	walk := func(yield func(*Node) bool) {
+		for _, node := range scored {
+			if !yield(r.nodes[node.id]) {
+				return
+			}
+		}
+	}
+	nodes := spreadZones(walk, n)
+	if n > 0 && len(nodes) == 0 {
+		return nil, fmt.Errorf("stash: DHT Node for key '%s' not found", key)
+	}
+	return nodes, nil
+}
+
+// SetNodeState changes the state of the node with the given ID or address
+// and returns its previous state, the node is replaced with its updated copy
+func (r *Rendezvous) SetNodeState(key string, state NodeState) (NodeState, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var previous NodeState
+	ok := r.updateNode(key, func(node *Node) {
+		previous, node.State = node.State, state
+	})
+	return previous, ok
+}
+
+// SetNodeAlive marks the node with the given ID or address as alive or not,
+// the node is replaced with its updated copy
+func (r *Rendezvous) SetNodeAlive(key string, alive bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.updateNode(key, func(node *Node) { node.Alive = alive })
+}
+
+// SetNodeHealth sets the state of the failure detector of the node with the given
+// ID or address, the node is replaced with its updated copy
+func (r *Rendezvous) SetNodeHealth(key string, suspicion float64, lastSeen time.Time, flaps int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.updateNode(key, func(node *Node) {
+		node.Suspicion, node.LastSeen, node.Flaps = suspicion, lastSeen, flaps
+	})
+}
+
+// updateNode replaces the node with the given ID or address with its copy
+// changed by change. The lock must be held
+func (r *Rendezvous) updateNode(key string, change func(*Node)) bool {
+	stale, _ := r.nodes.update(key, change)
+	return stale != nil
+}
+
+// NodeExists returns true if node with the given ID or address
+// exists in the placement, false otherwise
+func (r *Rendezvous) NodeExists(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.nodes.exists(key)
+}
+
+// GetNodes returns nodes of the placement mapped by their ids
+func (r *Rendezvous) GetNodes() map[int]*Node {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.nodes.copy()
+}
+
+// rendezvousScore returns the score of the node for the key.
+//
+// The combined hash is mapped to a uniform value u in (0, 1), and the score
+// is -weight / ln(u), so the probability of the node having the highest score
+// is proportional to its weight (see "Weighted distributed hash tables", Schindelhauer and Schomaker).
+func rendezvousScore(hashedKey, nodeId uint64, weight float64) float64 {
+	if weight <= 0 { // created without NewNode
+		weight = DEFAULT_WEIGHT
+	}
+	h := mix64(hashedKey ^ nodeId)
+	u := (float64(h>>11) + 0.5) / (1 << 53)
+	return -weight / math.Log(u)
+}
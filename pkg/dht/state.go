@@ -0,0 +1,156 @@
+package dht
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NODE_STATE_FILE is the name of the file storing the lifecycle state of the node
+const NODE_STATE_FILE = "node-state"
+
+// ErrInvalidTransition is returned for changes of node states which are not allowed
+var ErrInvalidTransition = errors.New("invalid node state transition")
+
+// NodeState is the lifecycle state of a node. It's independent from failures
+// of the node: a failed node keeps its state, but it's not alive (see Node.Alive).
+type NodeState int
+
+const (
+	// NodeActive nodes own keys, serve reads and take writes
+	NodeActive NodeState = iota
+	// NodeJoining nodes own keys and take writes, but don't serve reads
+	// until they receive the data of their keys and become active
+	NodeJoining
+	// NodeDraining nodes own keys and serve reads, but take no new writes,
+	// which go to the next nodes of preference lists instead
+	NodeDraining
+	// NodeLeaving nodes don't own keys anymore, their data is moved
+	// to the nodes which own it now
+	NodeLeaving
+	// NodeDead nodes have left the cluster
+	NodeDead
+)
+
+var nodeStateNames = map[NodeState]string{
+	NodeActive:   "active",
+	NodeJoining:  "joining",
+	NodeDraining: "draining",
+	NodeLeaving:  "leaving",
+	NodeDead:     "dead",
+}
+
+// nodeStateTransitions maps states to the states a node can move to
+var nodeStateTransitions = map[NodeState][]NodeState{
+	NodeJoining:  {NodeActive, NodeLeaving, NodeDead},
+	NodeActive:   {NodeDraining, NodeLeaving, NodeDead},
+	NodeDraining: {NodeActive, NodeLeaving, NodeDead},
+	NodeLeaving:  {NodeActive, NodeDead},
+	NodeDead:     {NodeJoining},
+}
+
+func (s NodeState) String() string {
+	if name, ok := nodeStateNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown (%d)", int(s))
+}
+
+// ParseNodeState returns the state with the given name.
+// Empty name is parsed as NodeActive
+func ParseNodeState(name string) (NodeState, error) {
+	if name == "" {
+		return NodeActive, nil
+	}
+	for state, stateName := range nodeStateNames {
+		if stateName == name {
+			return state, nil
+		}
+	}
+	return NodeActive, fmt.Errorf("unknown node state '%s'", name)
+}
+
+// OwnsKeys returns true if nodes in the state are placed on preference lists of keys
+func (s NodeState) OwnsKeys() bool {
+	return s == NodeActive || s == NodeJoining || s == NodeDraining
+}
+
+// ServesReads returns true if nodes in the state serve reads of their keys
+func (s NodeState) ServesReads() bool {
+	return s == NodeActive || s == NodeDraining
+}
+
+// TakesWrites returns true if nodes in the state take writes of their keys
+func (s NodeState) TakesWrites() bool {
+	return s == NodeActive || s == NodeJoining
+}
+
+// CanMoveTo returns nil if a node can move from the state to the given one,
+// otherwise the returned error wraps ErrInvalidTransition
+func (s NodeState) CanMoveTo(state NodeState) error {
+	if s == state {
+		return nil
+	}
+	for _, allowed := range nodeStateTransitions[s] {
+		if allowed == state {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: from %s to %s", ErrInvalidTransition, s, state)
+}
+
+// Node filters accepted by Placement.FilterNodesForKey
+var (
+	// OwnsKeys accepts nodes placed on preference lists of keys
+	OwnsKeys = func(node *Node) bool { return node.State.OwnsKeys() }
+	// ServesReads accepts nodes serving reads of their keys
+	ServesReads = func(node *Node) bool { return node.State.ServesReads() }
+	// TakesWrites accepts nodes taking writes of their keys
+	TakesWrites = func(node *Node) bool { return node.State.TakesWrites() }
+)
+
+// LoadNodeState returns the lifecycle state of the node stored in NODE_STATE_FILE in dir.
+// On the first start the initial state is stored and returned.
+func LoadNodeState(dir string, initial NodeState) (NodeState, error) {
+	const op = "dht.state.LoadNodeState"
+
+	data, err := os.ReadFile(filepath.Join(dir, NODE_STATE_FILE))
+	if errors.Is(err, os.ErrNotExist) {
+		if err := SaveNodeState(dir, initial); err != nil {
+			return initial, fmt.Errorf("%s: %w", op, err)
+		}
+		return initial, nil
+	}
+	if err != nil {
+		return initial, fmt.Errorf("%s: %w", op, err)
+	}
+
+	name := strings.TrimSpace(string(data))
+	if name == "" {
+		return initial, fmt.Errorf("%s: %s is empty", op, NODE_STATE_FILE)
+	}
+	state, err := ParseNodeState(name)
+	if err != nil {
+		return initial, fmt.Errorf("%s: %w", op, err)
+	}
+	return state, nil
+}
+
+// SaveNodeState stores the lifecycle state of the node in NODE_STATE_FILE in dir,
+// so the node is in the same state after a restart
+func SaveNodeState(dir string, state NodeState) error {
+	const op = "dht.state.SaveNodeState"
+
+	// write the state atomically, so a crash doesn't leave an empty file
+	path := filepath.Join(dir, NODE_STATE_FILE)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(state.String()+"\n"), 0644); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
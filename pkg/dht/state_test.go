@@ -0,0 +1,57 @@
+package dht
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNodeState(t *testing.T) {
+	for state := range nodeStateNames {
+		parsed, err := ParseNodeState(state.String())
+		assert.NoError(t, err)
+		assert.Equal(t, state, parsed)
+	}
+
+	parsed, err := ParseNodeState("")
+	assert.NoError(t, err)
+	assert.Equal(t, NodeActive, parsed)
+
+	_, err = ParseNodeState("sleeping")
+	assert.Error(t, err)
+}
+
+func TestNodeStateTransitions(t *testing.T) {
+	assert.NoError(t, NodeJoining.CanMoveTo(NodeActive))
+	assert.NoError(t, NodeActive.CanMoveTo(NodeDraining))
+	assert.NoError(t, NodeDraining.CanMoveTo(NodeLeaving))
+	assert.NoError(t, NodeLeaving.CanMoveTo(NodeDead))
+	assert.NoError(t, NodeDraining.CanMoveTo(NodeActive))
+	assert.NoError(t, NodeActive.CanMoveTo(NodeActive))
+
+	err := NodeDead.CanMoveTo(NodeActive)
+	assert.True(t, errors.Is(err, ErrInvalidTransition))
+	err = NodeActive.CanMoveTo(NodeJoining)
+	assert.True(t, errors.Is(err, ErrInvalidTransition))
+}
+
+func TestLoadNodeState(t *testing.T) {
+	dir := t.TempDir()
+
+	state, err := LoadNodeState(dir, NodeJoining)
+	assert.NoError(t, err)
+	assert.Equal(t, NodeJoining, state)
+	assert.FileExists(t, filepath.Join(dir, NODE_STATE_FILE))
+
+	assert.NoError(t, SaveNodeState(dir, NodeDraining))
+	state, err = LoadNodeState(dir, NodeJoining)
+	assert.NoError(t, err)
+	assert.Equal(t, NodeDraining, state)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, NODE_STATE_FILE), []byte("sleeping\n"), 0644))
+	_, err = LoadNodeState(dir, NodeActive)
+	assert.Error(t, err)
+}
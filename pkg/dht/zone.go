@@ -0,0 +1,65 @@
+package dht
+
+// spreadZones picks n nodes out of the nodes yielded by walk in the order of
+// preference, so that the picked nodes span as many distinct zones as possible.
+//
+// Nodes from zones which are already picked are put aside, and they are used
+// only if there are not enough nodes from other zones. So the first yielded node
+// is always picked first, and with nodes from a single zone (or without zones)
+// the result is the first n yielded nodes. walk must yield distinct nodes
+// and stop once yield returns false.
+func spreadZones(walk func(yield func(*Node) bool), n int) []*Node {
+	picked := make([]*Node, 0)
+	if n <= 0 {
+		return picked
+	}
+
+	spare := make([]*Node, 0)
+	zones := make(map[string]bool)
+	walk(func(node *Node) bool {
+		if node.Zone != "" && zones[node.Zone] {
+			if len(spare) < n {
+				spare = append(spare, node)
+			}
+			return true
+		}
+		zones[node.Zone] = true
+		picked = append(picked, node)
+		return len(picked) < n
+	})
+
+	for i := 0; len(picked) < n && i < len(spare); i++ {
+		picked = append(picked, spare[i])
+	}
+	return picked
+}
+
+// filterWalk returns walk yielding only the nodes accepted by accept
+func filterWalk(walk func(yield func(*Node) bool), accept func(*Node) bool) func(yield func(*Node) bool) {
+	return func(yield func(*Node) bool) {
+		walk(func(node *Node) bool {
+			if !accept(node) {
+				return true
+			}
+			return yield(node)
+		})
+	}
+}
+
+// CountZones returns the number of distinct zones of the nodes.
+// Every node without zone is counted as a separate zone
+func CountZones(nodes []*Node) int {
+	zones := make(map[string]bool)
+	count := 0
+	for _, node := range nodes {
+		if node.Zone == "" {
+			count++
+			continue
+		}
+		if !zones[node.Zone] {
+			zones[node.Zone] = true
+			count++
+		}
+	}
+	return count
+}
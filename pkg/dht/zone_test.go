@@ -0,0 +1,74 @@
+package dht
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makeZonedNodes(t testing.TB, zones ...string) []*Node {
+	nodes := makeNodes(t, len(zones))
+	for i, zone := range zones {
+		nodes[i].Zone = zone
+	}
+	return nodes
+}
+
+func TestSpreadZones(t *testing.T) {
+	nodes := makeZonedNodes(t, "a", "a", "b", "", "c", "")
+	walk := func(yield func(*Node) bool) {
+		for _, node := range nodes {
+			if !yield(node) {
+				return
+			}
+		}
+	}
+
+	cases := []struct {
+		n        int
+		expected []*Node
+	}{
+		{n: 0, expected: []*Node{}},
+		{n: 1, expected: []*Node{nodes[0]}},
+		{n: 3, expected: []*Node{nodes[0], nodes[2], nodes[3]}},
+		{n: 5, expected: []*Node{nodes[0], nodes[2], nodes[3], nodes[4], nodes[5]}},
+		{n: 6, expected: []*Node{nodes[0], nodes[2], nodes[3], nodes[4], nodes[5], nodes[1]}},
+		{n: 10, expected: []*Node{nodes[0], nodes[2], nodes[3], nodes[4], nodes[5], nodes[1]}},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.expected, spreadZones(walk, tc.n), "n = %d", tc.n)
+	}
+}
+
+func TestCountZones(t *testing.T) {
+	assert.Equal(t, 0, CountZones(nil))
+	assert.Equal(t, 1, CountZones(makeZonedNodes(t, "a", "a")))
+	assert.Equal(t, 4, CountZones(makeZonedNodes(t, "a", "b", "", "a", "")))
+}
+
+func TestZoneAwarePlacement(t *testing.T) {
+	for _, strategy := range strategies {
+		t.Run(strategy, func(t *testing.T) {
+			nodes := makeZonedNodes(t, "a", "a", "a", "b", "b", "b", "c", "c", "c")
+			placement := newTestPlacement(t, strategy)
+			placement.AddNode(nodes...)
+
+			for i := range 1000 {
+				key := fmt.Sprintf("key-%d", i)
+				preference, err := placement.GetNodesForKey(key, 3)
+				assert.NoError(t, err)
+				assert.Equal(t, 3, CountZones(preference), "replicas of %s are in distinct zones", key)
+
+				owner, _ := placement.GetNodeForKey(key)
+				assert.Equal(t, owner, preference[0])
+
+				// more copies than zones: every zone is used, the rest are filled
+				preference, err = placement.GetNodesForKey(key, 5)
+				assert.NoError(t, err)
+				assert.Len(t, preference, 5)
+				assert.Equal(t, 3, CountZones(preference[:3]))
+			}
+		})
+	}
+}
@@ -0,0 +1,705 @@
+package gossip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_PROBE_TIMEOUT     = 500 * time.Millisecond
+	DEFAULT_INDIRECT_PROBES   = 3
+	DEFAULT_SUSPICION_TIMEOUT = 5 * time.Second
+	DEFAULT_RETRANSMIT_MULT   = 4
+	DEFAULT_MAX_PIGGYBACK     = 16
+)
+
+// ErrNoAck is returned by PingReq if the member didn't respond to the indirect ping
+var ErrNoAck = errors.New("no ack")
+
+// Transport delivers messages between members
+type Transport interface {
+	// Ping sends the message to the member listening on the address
+	// and returns its reply
+	Ping(ctx context.Context, address string, msg Message) (Message, error)
+	// PingReq asks the member listening on the via address to ping the member
+	// listening on the target address. The reply of the via member is returned
+	// if the target member responded, otherwise an error is returned.
+	PingReq(ctx context.Context, via string, target string, msg Message) (Message, error)
+}
+
+// Config holds the settings of the gossip protocol
+type Config struct {
+	// ProbeTimeout is the time to wait for a reply to direct and indirect pings
+	ProbeTimeout time.Duration
+	// IndirectProbes is the number of members asked to ping the member which
+	// didn't respond to the direct ping
+	IndirectProbes int
+	// SuspicionTimeout is the time a suspected member has to refute
+	// the suspicion before it's declared dead
+	SuspicionTimeout time.Duration
+	// RetransmitMult scales the number of times every update is piggybacked,
+	// which is RetransmitMult * log(N + 1) for a cluster of N members
+	RetransmitMult int
+	// MaxPiggyback limits the number of updates piggybacked on a single message
+	MaxPiggyback int
+	// Phi holds the settings of the failure detectors of the members. A member
+	// which doesn't respond to pings becomes suspected only if its phi
+	// exceeds the threshold, so a single slow reply doesn't make it suspected.
+	Phi PhiConfig
+
+	// Changes receives members which were added or changed their state.
+	// The protocol never blocks on it: changes which don't fit into the channel
+	// are queued, and queued changes of the same member are coalesced
+	Changes chan<- Member
+}
+
+// withDefaults returns the config with the unset settings replaced with defaults
+func (c Config) withDefaults() Config {
+	if c.ProbeTimeout <= 0 {
+		c.ProbeTimeout = DEFAULT_PROBE_TIMEOUT
+	}
+	if c.IndirectProbes <= 0 {
+		c.IndirectProbes = DEFAULT_INDIRECT_PROBES
+	}
+	if c.SuspicionTimeout <= 0 {
+		c.SuspicionTimeout = DEFAULT_SUSPICION_TIMEOUT
+	}
+	if c.RetransmitMult <= 0 {
+		c.RetransmitMult = DEFAULT_RETRANSMIT_MULT
+	}
+	if c.MaxPiggyback <= 0 {
+		c.MaxPiggyback = DEFAULT_MAX_PIGGYBACK
+	}
+	c.Phi = c.Phi.withDefaults()
+	return c
+}
+
+type member struct {
+	Member
+	// suspectedAt is the time the member became suspected
+	suspectedAt time.Time
+	// contacted is set once the full membership was exchanged with the member
+	contacted bool
+	// detector is fed with heartbeats, which are messages from the member
+	// and acks of pings sent to it
+	detector *PhiDetector
+	// flaps is the number of times the member became alive again
+	// after being suspected or declared dead
+	flaps int
+}
+
+// MemberHealth is the state of the failure detector of a member
+type MemberHealth struct {
+	Member
+	// Suspicion is the current phi value of the member (see PhiDetector)
+	Suspicion float64
+	// LastSeen is the time of the last heartbeat of the member,
+	// it's zero if the member was never heard from
+	LastSeen time.Time
+	// Flaps is the number of times the member became alive again
+	// after being suspected or declared dead
+	Flaps int
+}
+
+type broadcast struct {
+	member    Member
+	transmits int
+}
+
+// Gossip is a SWIM-style membership of the cluster.
+//
+// Every protocol period (see Probe) a member is picked in randomized round-robin
+// order and pinged. If it doesn't respond, IndirectProbes random members are asked
+// to ping it. If none of them gets a reply, the member becomes suspected, and if
+// it doesn't refute the suspicion within SuspicionTimeout, it's declared dead.
+// Changes of the membership are piggybacked on pings and replies, and the full
+// membership is exchanged on the first contact, so joining nodes learn all
+// the members from a single seed.
+type Gossip struct {
+	mu        sync.Mutex
+	self      Member
+	transport Transport
+	cfg       Config
+
+	// members maps keys of the members (see Member.Key) to them
+	members map[string]*member
+	// probeOrder holds keys of the members to probe during the current round
+	probeOrder []string
+	// broadcasts holds the updates to piggyback, mapped by keys of the members
+	broadcasts map[string]*broadcast
+	// removed maps keys of the removed members to them (see Remove)
+	removed map[string]Member
+	// changes delivers the changes to cfg.Changes, it's nil if the channel is not set
+	changes *changeQueue
+	rand    *rand.Rand
+}
+
+// New creates Gossip of the self member, which knows no other members yet
+func New(self Member, transport Transport, cfg Config) *Gossip {
+	self.State = StateAlive
+	g := &Gossip{
+		self:       self,
+		transport:  transport,
+		cfg:        cfg.withDefaults(),
+		members:    make(map[string]*member),
+		probeOrder: make([]string, 0),
+		broadcasts: make(map[string]*broadcast),
+		removed:    make(map[string]Member),
+		rand:       rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+	if cfg.Changes != nil {
+		g.changes = newChangeQueue(cfg.Changes)
+	}
+	return g
+}
+
+// Self returns the self member
+func (g *Gossip) Self() Member {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.self
+}
+
+// SetLifecycle changes the lifecycle of the self member and returns the self member.
+// The incarnation is increased, so the change overrides what other members know
+// about the self member, and it's spread with the next messages
+func (g *Gossip) SetLifecycle(lifecycle string) Member {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.self.Lifecycle == lifecycle {
+		return g.self
+	}
+	g.self.Lifecycle = lifecycle
+	g.self.Incarnation++
+	g.enqueue(g.self)
+	return g.self
+}
+
+// Members returns all the known members except the self member, sorted by keys
+func (g *Gossip) Members() []Member {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	members := make([]Member, 0, len(g.members))
+	for _, m := range g.members {
+		members = append(members, m.Member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Key() < members[j].Key()
+	})
+	return members
+}
+
+// Health returns the states of the failure detectors of all the known members
+// except the self member, sorted by keys
+func (g *Gossip) Health() []MemberHealth {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	health := make([]MemberHealth, 0, len(g.members))
+	for _, m := range g.members {
+		health = append(health, MemberHealth{
+			Member:    m.Member,
+			Suspicion: m.detector.Phi(now),
+			LastSeen:  m.detector.LastSeen(),
+			Flaps:     m.flaps,
+		})
+	}
+	sort.Slice(health, func(i, j int) bool {
+		return health[i].Key() < health[j].Key()
+	})
+	return health
+}
+
+// Join adds the members which are not known yet (e.g. seeds from the config)
+// as alive ones. Known members are skipped. Removed members join again.
+func (g *Gossip) Join(members ...Member) {
+	changes := make([]Member, 0)
+
+	g.mu.Lock()
+	for _, m := range members {
+		if g.isSelf(m) || g.find(m) != nil {
+			continue
+		}
+		if tombstone, ok := g.findRemoved(m); ok {
+			delete(g.removed, tombstone.Key())
+		}
+		m.State, m.Incarnation = StateAlive, 0
+		g.members[m.Key()] = g.newMember(m)
+		changes = append(changes, m)
+	}
+	g.mu.Unlock()
+
+	g.notify(changes)
+}
+
+// Remove removes the member (e.g. the node which left the cluster). Updates about
+// the member from other members are ignored, so it's not added back by them,
+// until the member joins again (see Join).
+func (g *Gossip) Remove(m Member) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if known := g.find(m); known != nil {
+		delete(g.members, known.Key())
+		delete(g.broadcasts, known.Key())
+		m = known.Member
+	}
+	g.removed[m.Key()] = m
+}
+
+// IsRemoved returns true if the member is removed (see Remove)
+func (g *Gossip) IsRemoved(m Member) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	_, ok := g.findRemoved(m)
+	return ok
+}
+
+// Probe runs a single protocol period, it must be called periodically
+// (e.g. every second). Suspected members are declared dead
+// once their suspicion times out, and the next member is pinged directly,
+// and if it doesn't respond, indirectly through other members.
+// The member becomes suspected if all of the pings fail.
+func (g *Gossip) Probe(ctx context.Context) {
+	g.mu.Lock()
+	changes := g.expireSuspicions()
+	target, ok := g.nextTarget()
+	var msg Message
+	if ok {
+		msg = g.message(target.Key(), !g.members[target.Key()].contacted)
+	}
+	g.mu.Unlock()
+	g.notify(changes)
+	if !ok {
+		return
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, g.cfg.ProbeTimeout)
+	reply, err := g.transport.Ping(pingCtx, target.Address, msg)
+	cancel()
+	if err == nil {
+		g.acknowledge(target, reply)
+		return
+	}
+	if target.State == StateDead {
+		return
+	}
+
+	if reply, ok := g.probeIndirect(ctx, target); ok {
+		g.handle(reply)
+		g.mu.Lock()
+		if m := g.find(target); m != nil { // acked through the peer
+			m.detector.Heartbeat(time.Now())
+		}
+		g.mu.Unlock()
+		return
+	}
+
+	g.mu.Lock()
+	changes = g.suspect(target)
+	g.mu.Unlock()
+	g.notify(changes)
+}
+
+// probeIndirect asks random members to ping the target
+// and returns the first reply received
+func (g *Gossip) probeIndirect(ctx context.Context, target Member) (Message, bool) {
+	g.mu.Lock()
+	peers := g.randomMembers(g.cfg.IndirectProbes, target.Key())
+	messages := make([]Message, len(peers))
+	for i, peer := range peers {
+		messages[i] = g.message(peer.Key(), false)
+	}
+	g.mu.Unlock()
+	if len(peers) == 0 {
+		return Message{}, false
+	}
+
+	// the peers wait for the target for ProbeTimeout as well
+	ctx, cancel := context.WithTimeout(ctx, 2*g.cfg.ProbeTimeout)
+	defer cancel()
+
+	replies := make(chan Message, len(peers))
+	var wg sync.WaitGroup
+	for i, peer := range peers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reply, err := g.transport.PingReq(ctx, peer.Address, target.Address, messages[i])
+			if err == nil {
+				replies <- reply
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(replies)
+	}()
+
+	reply, ok := <-replies
+	return reply, ok
+}
+
+// HandlePing merges the received message and returns the reply.
+// If the sender is not known yet, the reply contains the full membership.
+func (g *Gossip) HandlePing(msg Message) Message {
+	g.mu.Lock()
+	known := g.find(msg.From) != nil
+	changes := g.merge(msg)
+	reply := g.message(msg.From.Key(), !known)
+	if m := g.find(msg.From); m != nil && !known {
+		m.contacted = true
+	}
+	g.mu.Unlock()
+
+	g.notify(changes)
+	return reply
+}
+
+// HandlePingReq merges the received message and pings the target on behalf
+// of the sender. The reply for the sender is returned if the target responded,
+// otherwise the returned error wraps ErrNoAck.
+func (g *Gossip) HandlePingReq(ctx context.Context, target string, msg Message) (Message, error) {
+	g.mu.Lock()
+	changes := g.merge(msg)
+	targetMember := Member{Address: target}
+	targetKey := target
+	if m := g.findByAddress(target); m != nil {
+		targetMember, targetKey = m.Member, m.Key()
+	}
+	ping := g.message(targetKey, false)
+	g.mu.Unlock()
+	g.notify(changes)
+
+	ctx, cancel := context.WithTimeout(ctx, g.cfg.ProbeTimeout)
+	defer cancel()
+	reply, err := g.transport.Ping(ctx, target, ping)
+	if err != nil {
+		return Message{}, fmt.Errorf("%w from %s: %v", ErrNoAck, target, err)
+	}
+	g.acknowledge(targetMember, reply)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.message(msg.From.Key(), false), nil
+}
+
+// acknowledge merges the reply of the pinged target
+func (g *Gossip) acknowledge(target Member, reply Message) {
+	g.mu.Lock()
+	var changes []Member
+	if g.isSelf(reply.From) {
+		// the address of the target is the address of the self member
+		// (e.g. the node itself is in the list of nodes from the config)
+		delete(g.members, target.Key())
+		self := g.self
+		self.Address = target.Address
+		changes = []Member{self}
+	} else {
+		changes = g.merge(reply)
+		if m := g.find(reply.From); m != nil {
+			m.contacted = true
+		}
+	}
+	g.mu.Unlock()
+
+	g.notify(changes)
+}
+
+// handle merges the message received without direct contact with its sender
+func (g *Gossip) handle(msg Message) {
+	g.mu.Lock()
+	changes := g.merge(msg)
+	g.mu.Unlock()
+
+	g.notify(changes)
+}
+
+// merge applies the sender and the updates of the message to the membership
+// and returns the changed members. The lock must be held.
+func (g *Gossip) merge(msg Message) []Member {
+	changes := make([]Member, 0)
+	if msg.From.Key() != "" {
+		changes = append(changes, g.apply(msg.From, true)...)
+		if m := g.find(msg.From); m != nil {
+			m.detector.Heartbeat(time.Now())
+		}
+	}
+	for _, update := range msg.Updates {
+		changes = append(changes, g.apply(update, false)...)
+	}
+	return changes
+}
+
+// apply merges a single update and returns the member if it's changed.
+// The address, the name, the weight and the zone of a known member are
+// taken only from the member itself (direct updates), other updates change
+// only the state and the lifecycle of the member. The lock must be held.
+func (g *Gossip) apply(update Member, direct bool) []Member {
+	if g.isSelf(update) {
+		g.refute(update)
+		return nil
+	}
+	if _, ok := g.findRemoved(update); ok {
+		return nil
+	}
+
+	m := g.find(update)
+	if m == nil {
+		g.members[update.Key()] = g.newMember(update)
+		g.enqueue(update)
+		return []Member{update}
+	}
+
+	changed := false
+	if m.ID == "" && update.ID != "" { // the member is identified
+		delete(g.members, m.Key())
+		m.ID = update.ID
+		g.members[m.Key()] = m
+		changed = true
+	}
+	if direct && !sameInfo(m.Member, update) {
+		m.Name, m.Address, m.Weight, m.Zone = update.Name, update.Address, update.Weight, update.Zone
+		m.Lifecycle = update.Lifecycle
+		changed = true
+	}
+	if update.supersedes(m.Member) {
+		if update.State == StateSuspect && m.State != StateSuspect {
+			m.suspectedAt = time.Now()
+		}
+		if update.State == StateAlive && m.State != StateAlive {
+			m.flaps++
+		}
+		m.State, m.Incarnation = update.State, update.Incarnation
+		m.Lifecycle = update.Lifecycle
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+	g.enqueue(m.Member)
+	return []Member{m.Member}
+}
+
+// sameInfo returns true if the members have the same address, name, weight, zone and lifecycle
+func sameInfo(a, b Member) bool {
+	return a.Address == b.Address && a.Name == b.Name && a.Weight == b.Weight && a.Zone == b.Zone &&
+		a.Lifecycle == b.Lifecycle
+}
+
+// refute handles the update about the self member: suspicions, death and outdated
+// lifecycles (e.g. from before a restart) are refuted by increasing the incarnation,
+// which makes other members accept the current self member. The lock must be held.
+func (g *Gossip) refute(update Member) {
+	if update.Incarnation < g.self.Incarnation {
+		return
+	}
+	if update.State == StateAlive && update.Lifecycle == g.self.Lifecycle {
+		g.self.Incarnation = update.Incarnation
+		return
+	}
+	g.self.Incarnation = update.Incarnation + 1
+	g.enqueue(g.self)
+}
+
+// suspect marks the target as suspected if its phi exceeds the threshold,
+// the lock must be held
+func (g *Gossip) suspect(target Member) []Member {
+	m := g.find(target)
+	if m == nil || m.State != StateAlive || !m.detector.Suspicious(time.Now()) {
+		return nil
+	}
+	update := m.Member
+	update.State = StateSuspect
+	return g.apply(update, false)
+}
+
+// expireSuspicions declares dead the suspected members which didn't refute
+// the suspicion in time, the lock must be held
+func (g *Gossip) expireSuspicions() []Member {
+	changes := make([]Member, 0)
+	deadline := time.Now().Add(-g.cfg.SuspicionTimeout)
+	for _, m := range g.members {
+		if m.State != StateSuspect || m.suspectedAt.After(deadline) {
+			continue
+		}
+		update := m.Member
+		update.State = StateDead
+		changes = append(changes, g.apply(update, false)...)
+	}
+	return changes
+}
+
+// nextTarget returns the next member to probe. Members are probed in random
+// order, and the order is shuffled after every member is probed once,
+// so every member is probed within a bounded time. Dead members are skipped,
+// except a single random one per round, so members declared dead during
+// a network partition learn about it and refute their death once it heals.
+// The lock must be held.
+func (g *Gossip) nextTarget() (Member, bool) {
+	for {
+		if len(g.probeOrder) == 0 {
+			dead := make([]string, 0)
+			for key, m := range g.members {
+				if m.State == StateDead {
+					dead = append(dead, key)
+					continue
+				}
+				g.probeOrder = append(g.probeOrder, key)
+			}
+			if len(dead) != 0 {
+				g.probeOrder = append(g.probeOrder, dead[g.rand.IntN(len(dead))])
+			}
+			if len(g.probeOrder) == 0 {
+				return Member{}, false
+			}
+			g.rand.Shuffle(len(g.probeOrder), func(i, j int) {
+				g.probeOrder[i], g.probeOrder[j] = g.probeOrder[j], g.probeOrder[i]
+			})
+		}
+
+		key := g.probeOrder[0]
+		g.probeOrder = g.probeOrder[1:]
+		if m, ok := g.members[key]; ok {
+			return m.Member, true
+		}
+	}
+}
+
+// randomMembers returns up to n random alive members except the excluded one,
+// the lock must be held
+func (g *Gossip) randomMembers(n int, exclude string) []Member {
+	candidates := make([]Member, 0)
+	for key, m := range g.members {
+		if key != exclude && m.State == StateAlive {
+			candidates = append(candidates, m.Member)
+		}
+	}
+	g.rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	return candidates[:min(n, len(candidates))]
+}
+
+// message returns the message for the member with the given key.
+// Recent updates are piggybacked, the ones transmitted the least go first.
+// If full is set, all the known members are sent. The lock must be held.
+func (g *Gossip) message(to string, full bool) Message {
+	msg := Message{From: g.self, Updates: make([]Member, 0)}
+	if full {
+		for _, m := range g.members {
+			if m.Key() != to {
+				msg.Updates = append(msg.Updates, m.Member)
+			}
+		}
+	}
+	if m, ok := g.members[to]; ok && m.State != StateAlive {
+		// the member has to refute the suspicion or its death
+		msg.Updates = append(msg.Updates, m.Member)
+	}
+
+	pending := make([]*broadcast, 0, len(g.broadcasts))
+	for key, b := range g.broadcasts {
+		if key != to {
+			pending = append(pending, b)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].transmits < pending[j].transmits
+	})
+
+	limit := g.retransmitLimit()
+	for _, b := range pending[:min(g.cfg.MaxPiggyback, len(pending))] {
+		msg.Updates = append(msg.Updates, b.member)
+		b.transmits++
+		if b.transmits >= limit {
+			delete(g.broadcasts, b.member.Key())
+		}
+	}
+	return msg
+}
+
+// enqueue schedules the update to be piggybacked, it replaces
+// the previous update about the same member. The lock must be held.
+func (g *Gossip) enqueue(update Member) {
+	g.broadcasts[update.Key()] = &broadcast{member: update}
+}
+
+// retransmitLimit returns the number of times every update is piggybacked,
+// which is enough for the update to reach all members with high probability.
+// The lock must be held.
+func (g *Gossip) retransmitLimit() int {
+	return g.cfg.RetransmitMult * int(math.Ceil(math.Log10(float64(len(g.members)+2))))
+}
+
+// newMember creates the state of the member, which was never heard from
+func (g *Gossip) newMember(m Member) *member {
+	known := &member{Member: m, detector: NewPhiDetector(g.cfg.Phi)}
+	if m.State == StateSuspect {
+		known.suspectedAt = time.Now()
+	}
+	return known
+}
+
+// find returns the known member which is the same as the given one:
+// the member with the same key, or the member at the same address
+// if one of them has no ID.
+// The lock must be held.
+func (g *Gossip) find(m Member) *member {
+	if known, ok := g.members[m.Key()]; ok {
+		return known
+	}
+	if known := g.findByAddress(m.Address); known != nil && (m.ID == "" || known.ID == "") {
+		return known
+	}
+	return nil
+}
+
+// findRemoved returns the removed member which is the same as the given one,
+// members are matched the same way as by find. The lock must be held.
+func (g *Gossip) findRemoved(m Member) (Member, bool) {
+	if removed, ok := g.removed[m.Key()]; ok {
+		return removed, true
+	}
+	for _, removed := range g.removed {
+		if removed.Address == m.Address && (m.ID == "" || removed.ID == "") {
+			return removed, true
+		}
+	}
+	return Member{}, false
+}
+
+// findByAddress returns the member with the given address, the lock must be held
+func (g *Gossip) findByAddress(address string) *member {
+	for _, m := range g.members {
+		if m.Address == address {
+			return m
+		}
+	}
+	return nil
+}
+
+// isSelf returns true if the member is the self member, the lock must be held
+func (g *Gossip) isSelf(m Member) bool {
+	return m.Key() == g.self.Key()
+}
+
+// notify sends the changes to cfg.Changes without blocking (see changeQueue)
+func (g *Gossip) notify(changes []Member) {
+	if g.changes == nil || len(changes) == 0 {
+		return
+	}
+	g.changes.push(changes...)
+}
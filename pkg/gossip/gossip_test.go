@@ -0,0 +1,363 @@
+package gossip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testNetwork delivers messages between members in memory
+type testNetwork struct {
+	mu    sync.Mutex
+	nodes map[string]*Gossip
+	// down members don't respond to anything
+	down map[string]bool
+	// cut holds broken direct links between members
+	cut map[[2]string]bool
+}
+
+type testTransport struct {
+	network *testNetwork
+	from    string
+}
+
+func (n *testNetwork) reach(from, to string) (*Gossip, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	node, ok := n.nodes[to]
+	if !ok || n.down[to] || n.cut[[2]string{from, to}] {
+		return nil, errors.New("unreachable")
+	}
+	return node, nil
+}
+
+func (t *testTransport) Ping(_ context.Context, address string, msg Message) (Message, error) {
+	node, err := t.network.reach(t.from, address)
+	if err != nil {
+		return Message{}, err
+	}
+	return node.HandlePing(msg), nil
+}
+
+func (t *testTransport) PingReq(ctx context.Context, via string, target string, msg Message) (Message, error) {
+	node, err := t.network.reach(t.from, via)
+	if err != nil {
+		return Message{}, err
+	}
+	return node.HandlePingReq(ctx, target, msg)
+}
+
+func newTestCluster(t *testing.T, count int, cfg Config) (*testNetwork, []*Gossip) {
+	network := &testNetwork{
+		nodes: make(map[string]*Gossip),
+		down:  make(map[string]bool),
+		cut:   make(map[[2]string]bool),
+	}
+	nodes := make([]*Gossip, 0, count)
+	for i := range count {
+		self := Member{ID: fmt.Sprintf("node-%d", i), Address: fmt.Sprintf("10.0.0.%d:5555", i+1), Weight: 1}
+		node := New(self, &testTransport{network: network, from: self.Address}, cfg)
+		network.nodes[self.Address] = node
+		nodes = append(nodes, node)
+	}
+	return network, nodes
+}
+
+// probeRounds runs the given number of protocol periods on all the members
+func probeRounds(nodes []*Gossip, rounds int) {
+	for range rounds {
+		for _, node := range nodes {
+			node.Probe(context.Background())
+		}
+	}
+}
+
+func memberState(node *Gossip, id string) (State, bool) {
+	for _, m := range node.Members() {
+		if m.ID == id {
+			return m.State, true
+		}
+	}
+	return 0, false
+}
+
+func TestSupersedes(t *testing.T) {
+	tests := []struct {
+		update, known Member
+		expected      bool
+	}{
+		{Member{State: StateAlive, Incarnation: 1}, Member{State: StateSuspect, Incarnation: 0}, true},
+		{Member{State: StateAlive, Incarnation: 1}, Member{State: StateDead, Incarnation: 0}, true},
+		{Member{State: StateAlive, Incarnation: 1}, Member{State: StateSuspect, Incarnation: 1}, false},
+		{Member{State: StateSuspect, Incarnation: 1}, Member{State: StateAlive, Incarnation: 1}, true},
+		{Member{State: StateSuspect, Incarnation: 0}, Member{State: StateAlive, Incarnation: 1}, false},
+		{Member{State: StateDead, Incarnation: 1}, Member{State: StateSuspect, Incarnation: 1}, true},
+		{Member{State: StateSuspect, Incarnation: 1}, Member{State: StateDead, Incarnation: 1}, false},
+		{Member{State: StateAlive, Incarnation: 1}, Member{State: StateAlive, Incarnation: 1}, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tt.update.supersedes(tt.known), "%+v over %+v", tt.update, tt.known)
+	}
+}
+
+func TestJoinThroughSeed(t *testing.T) {
+	_, nodes := newTestCluster(t, 5, Config{})
+	seed := nodes[0].Self()
+	for _, node := range nodes[1:] {
+		node.Join(Member{Address: seed.Address}) // seeds are known by their address
+	}
+
+	probeRounds(nodes, 3)
+
+	for _, node := range nodes {
+		members := node.Members()
+		assert.Len(t, members, 4, node.Self().ID)
+		for _, m := range members {
+			assert.NotEmpty(t, m.ID, "member %s is not identified", m.Address)
+			assert.Equal(t, StateAlive, m.State)
+		}
+	}
+}
+
+func TestFailureDetection(t *testing.T) {
+	changes := make(chan Member, 100)
+	network, nodes := newTestCluster(t, 4, Config{SuspicionTimeout: time.Nanosecond, Changes: changes})
+	for _, node := range nodes {
+		for _, other := range nodes {
+			node.Join(other.Self())
+		}
+	}
+	for len(changes) > 0 {
+		<-changes
+	}
+
+	failed := nodes[3].Self()
+	network.down[failed.Address] = true
+	probeRounds(nodes[:3], 6)
+
+	for _, node := range nodes[:3] {
+		state, ok := memberState(node, failed.ID)
+		assert.True(t, ok)
+		assert.Equal(t, StateDead, state, node.Self().ID)
+	}
+
+	states := make([]State, 0)
+	for len(changes) > 0 {
+		change := <-changes
+		assert.Equal(t, failed.ID, change.ID)
+		states = append(states, change.State)
+	}
+	assert.Contains(t, states, StateSuspect)
+	assert.Contains(t, states, StateDead)
+}
+
+func TestIndirectProbe(t *testing.T) {
+	network, nodes := newTestCluster(t, 3, Config{})
+	for _, node := range nodes {
+		for _, other := range nodes {
+			node.Join(other.Self())
+		}
+	}
+
+	// node-0 can't reach node-1 directly, but node-2 can
+	network.cut[[2]string{nodes[0].Self().Address, nodes[1].Self().Address}] = true
+	probeRounds(nodes[:1], 4)
+
+	state, _ := memberState(nodes[0], "node-1")
+	assert.Equal(t, StateAlive, state)
+}
+
+func TestRefuteSuspicion(t *testing.T) {
+	network, nodes := newTestCluster(t, 3, Config{SuspicionTimeout: time.Hour})
+	for _, node := range nodes {
+		for _, other := range nodes {
+			node.Join(other.Self())
+		}
+	}
+
+	// node-2 is unreachable for a while and becomes suspected
+	network.down[nodes[2].Self().Address] = true
+	probeRounds(nodes[:2], 2)
+	state, _ := memberState(nodes[0], "node-2")
+	assert.Equal(t, StateSuspect, state)
+
+	network.down[nodes[2].Self().Address] = false
+	probeRounds(nodes, 4)
+
+	assert.Equal(t, uint64(1), nodes[2].Self().Incarnation)
+	for _, node := range nodes[:2] {
+		state, _ := memberState(node, "node-2")
+		assert.Equal(t, StateAlive, state, node.Self().ID)
+	}
+}
+
+func TestRejoinAfterDeath(t *testing.T) {
+	network, nodes := newTestCluster(t, 2, Config{SuspicionTimeout: time.Nanosecond})
+	nodes[0].Join(nodes[1].Self())
+	nodes[1].Join(nodes[0].Self())
+
+	network.down[nodes[1].Self().Address] = true
+	probeRounds(nodes[:1], 3)
+	state, _ := memberState(nodes[0], "node-1")
+	assert.Equal(t, StateDead, state)
+
+	// dead members are still probed from time to time
+	network.down[nodes[1].Self().Address] = false
+	probeRounds(nodes, 2)
+	state, _ = memberState(nodes[0], "node-1")
+	assert.Equal(t, StateAlive, state)
+}
+
+func TestSelfInSeeds(t *testing.T) {
+	changes := make(chan Member, 10)
+	network, nodes := newTestCluster(t, 1, Config{Changes: changes})
+	self := nodes[0].Self()
+	network.nodes["127.0.0.1:5555"] = nodes[0]
+
+	nodes[0].Join(Member{Address: "127.0.0.1:5555"})
+	<-changes
+	nodes[0].Probe(context.Background())
+
+	assert.Empty(t, nodes[0].Members())
+	identified := <-changes
+	assert.Equal(t, self.ID, identified.ID)
+	assert.Equal(t, "127.0.0.1:5555", identified.Address)
+}
+
+func TestPiggybackLimit(t *testing.T) {
+	_, nodes := newTestCluster(t, 1, Config{MaxPiggyback: 2, RetransmitMult: 1})
+	node := nodes[0]
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	for i := range 3 {
+		node.apply(Member{ID: fmt.Sprintf("member-%d", i), Address: fmt.Sprintf("10.0.1.%d:5555", i)}, false)
+	}
+	assert.Len(t, node.message("", false).Updates, 2)
+	assert.Len(t, node.message("", false).Updates, 1) // the rest of the updates
+	assert.Empty(t, node.message("", false).Updates)
+}
+
+func TestSlowReplyNotSuspected(t *testing.T) {
+	network, nodes := newTestCluster(t, 2, Config{Phi: PhiConfig{FirstHeartbeatEstimate: time.Hour}})
+	nodes[0].Join(nodes[1].Self())
+	nodes[1].Join(nodes[0].Self())
+	probeRounds(nodes, 1)
+
+	// node-1 was heard from recently, so a single missed reply is not suspicious
+	network.down[nodes[1].Self().Address] = true
+	probeRounds(nodes[:1], 1)
+	state, _ := memberState(nodes[0], "node-1")
+	assert.Equal(t, StateAlive, state)
+
+	health := nodes[0].Health()
+	assert.Len(t, health, 1)
+	assert.False(t, health[0].LastSeen.IsZero())
+	assert.Less(t, health[0].Suspicion, DEFAULT_PHI_THRESHOLD)
+}
+
+func TestFlaps(t *testing.T) {
+	// every missed reply is suspicious
+	cfg := Config{SuspicionTimeout: time.Hour, Phi: PhiConfig{Threshold: 1e-9}}
+	network, nodes := newTestCluster(t, 2, cfg)
+	nodes[0].Join(nodes[1].Self())
+	nodes[1].Join(nodes[0].Self())
+
+	for range 2 {
+		network.down[nodes[1].Self().Address] = true
+		probeRounds(nodes[:1], 1)
+		network.down[nodes[1].Self().Address] = false
+		probeRounds(nodes, 2)
+	}
+
+	health := nodes[0].Health()
+	assert.Equal(t, StateAlive, health[0].State)
+	assert.Equal(t, 2, health[0].Flaps)
+}
+
+func TestSetLifecycle(t *testing.T) {
+	_, nodes := newTestCluster(t, 4, Config{})
+	seed := nodes[0].Self()
+	for _, node := range nodes[1:] {
+		node.Join(Member{Address: seed.Address})
+	}
+	probeRounds(nodes, 3)
+
+	self := nodes[3].SetLifecycle("draining")
+	assert.Equal(t, uint64(1), self.Incarnation)
+	probeRounds(nodes, 4)
+
+	for _, node := range nodes[:3] {
+		for _, m := range node.Members() {
+			if m.ID == self.ID {
+				assert.Equal(t, "draining", m.Lifecycle, node.Self().ID)
+			}
+		}
+	}
+}
+
+func TestRemoveMember(t *testing.T) {
+	_, nodes := newTestCluster(t, 3, Config{})
+	for _, node := range nodes {
+		for _, other := range nodes {
+			node.Join(other.Self())
+		}
+	}
+	probeRounds(nodes, 2)
+
+	// node-2 left the cluster, but node-1 still spreads it
+	removed := nodes[2].Self()
+	nodes[0].Remove(Member{ID: removed.ID})
+	assert.True(t, nodes[0].IsRemoved(removed))
+	probeRounds(nodes[:2], 4)
+	_, ok := memberState(nodes[0], removed.ID)
+	assert.False(t, ok, "removed member is not added back by other members")
+
+	// the member is added back once it joins again
+	nodes[0].Join(removed)
+	assert.False(t, nodes[0].IsRemoved(removed))
+	_, ok = memberState(nodes[0], removed.ID)
+	assert.True(t, ok)
+}
+
+func TestChangesDontBlock(t *testing.T) {
+	changes := make(chan Member)
+	_, nodes := newTestCluster(t, 1, Config{Changes: changes})
+	node := nodes[0]
+
+	members := make([]Member, 0)
+	for i := range 3 {
+		members = append(members, Member{ID: fmt.Sprintf("member-%d", i), Address: fmt.Sprintf("10.0.1.%d:5555", i)})
+	}
+	// nothing receives the changes yet
+	node.Join(members...)
+	suspected := members[0]
+	suspected.State = StateSuspect
+	node.handle(Message{Updates: []Member{suspected}})
+
+	// every member gets its latest state, changes of the same member may be coalesced
+	received := make([]Member, 0)
+	for {
+		select {
+		case change := <-changes:
+			received = append(received, change)
+			continue
+		case <-time.After(100 * time.Millisecond):
+		}
+		break
+	}
+	assert.LessOrEqual(t, len(received), 4)
+	latest := make(map[string]Member)
+	for _, change := range received {
+		latest[change.ID] = change
+	}
+	assert.Len(t, latest, 3)
+	assert.Equal(t, StateSuspect, latest[suspected.ID].State)
+	assert.Equal(t, StateAlive, latest[members[1].ID].State)
+}
@@ -0,0 +1,75 @@
+package gossip
+
+import "fmt"
+
+// State is the state of a member as seen by other members
+type State int
+
+const (
+	// StateAlive members respond to pings
+	StateAlive State = iota
+	// StateSuspect members didn't respond to direct and indirect pings,
+	// they are declared dead unless they refute the suspicion in time
+	StateSuspect
+	// StateDead members didn't refute the suspicion
+	StateDead
+)
+
+func (s State) String() string {
+	switch s {
+	case StateAlive:
+		return "alive"
+	case StateSuspect:
+		return "suspect"
+	case StateDead:
+		return "dead"
+	}
+	return fmt.Sprintf("unknown (%d)", int(s))
+}
+
+// Member is a node of the cluster along with its state
+type Member struct {
+	// ID is the persistent identifier of the node, members without ID
+	// are identified by their address until they are contacted
+	ID      string
+	Name    string
+	Address string
+	Weight  float64
+	Zone    string
+	// Lifecycle is the lifecycle state of the node (e.g. "active" or "draining"),
+	// which is opaque to the protocol. It's changed only by the member itself
+	// along with its incarnation (see Gossip.SetLifecycle).
+	Lifecycle string
+
+	State State
+	// Incarnation orders updates about the member. It's increased only
+	// by the member itself, to refute suspicions about it.
+	Incarnation uint64
+}
+
+// Key returns the key the member is identified by: its ID,
+// or the address if the ID is not known
+func (m Member) Key() string {
+	if m.ID != "" {
+		return m.ID
+	}
+	return m.Address
+}
+
+// supersedes returns true if the update overrides the known state of the member.
+//
+// Updates with greater incarnations override the older ones. For the same
+// incarnation suspicion overrides aliveness, and death overrides both.
+func (m Member) supersedes(known Member) bool {
+	if m.Incarnation != known.Incarnation {
+		return m.Incarnation > known.Incarnation
+	}
+	return m.State > known.State
+}
+
+// Message is exchanged by members during pings. Every message carries
+// the sender itself and the recent membership updates known by the sender.
+type Message struct {
+	From    Member
+	Updates []Member
+}
@@ -0,0 +1,156 @@
+package gossip
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_PHI_THRESHOLD            = 8.0
+	DEFAULT_PHI_WINDOW_SIZE          = 100
+	DEFAULT_PHI_MIN_STD_DEV          = 500 * time.Millisecond
+	DEFAULT_PHI_ACCEPTABLE_PAUSE     = 0
+	DEFAULT_FIRST_HEARTBEAT_ESTIMATE = time.Second
+)
+
+// PhiConfig holds the settings of the phi accrual failure detectors
+type PhiConfig struct {
+	// Threshold is the phi value above which a member which doesn't respond
+	// to pings becomes suspected. Phi of 8 means the chance the member
+	// is alive but slow is about 10^-8 given its history of heartbeats.
+	Threshold float64
+	// WindowSize is the number of the latest heartbeat intervals kept
+	WindowSize int
+	// MinStdDev is the lower bound of the standard deviation of heartbeat
+	// intervals, it prevents too high phi values for very regular heartbeats
+	MinStdDev time.Duration
+	// AcceptablePause is added to the mean heartbeat interval,
+	// so pauses up to this duration (e.g. GC pauses) raise phi only slightly
+	AcceptablePause time.Duration
+	// FirstHeartbeatEstimate is the heartbeat interval assumed
+	// until intervals of the member are observed
+	FirstHeartbeatEstimate time.Duration
+}
+
+func (c PhiConfig) withDefaults() PhiConfig {
+	if c.Threshold <= 0 {
+		c.Threshold = DEFAULT_PHI_THRESHOLD
+	}
+	if c.WindowSize <= 0 {
+		c.WindowSize = DEFAULT_PHI_WINDOW_SIZE
+	}
+	if c.MinStdDev <= 0 {
+		c.MinStdDev = DEFAULT_PHI_MIN_STD_DEV
+	}
+	if c.AcceptablePause < 0 {
+		c.AcceptablePause = DEFAULT_PHI_ACCEPTABLE_PAUSE
+	}
+	if c.FirstHeartbeatEstimate <= 0 {
+		c.FirstHeartbeatEstimate = DEFAULT_FIRST_HEARTBEAT_ESTIMATE
+	}
+	return c
+}
+
+// PhiDetector is a phi accrual failure detector (Hayashibara et al.).
+//
+// Instead of a boolean verdict it returns the suspicion level phi, which grows
+// with the time since the last heartbeat, scaled by the observed distribution
+// of heartbeat intervals. A member with regular heartbeats is suspected soon
+// after it misses a few, a member with irregular ones is given more time.
+type PhiDetector struct {
+	mu  sync.Mutex
+	cfg PhiConfig
+	// intervals is a ring buffer of the latest heartbeat intervals in milliseconds
+	intervals []float64
+	next      int
+	last      time.Time
+}
+
+// NewPhiDetector creates PhiDetector without heartbeats
+func NewPhiDetector(cfg PhiConfig) *PhiDetector {
+	cfg = cfg.withDefaults()
+	return &PhiDetector{cfg: cfg, intervals: make([]float64, 0, cfg.WindowSize)}
+}
+
+// Heartbeat records a heartbeat received at the given time
+func (d *PhiDetector) Heartbeat(now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.last.IsZero() {
+		// the first interval is estimated, so phi is defined after a single heartbeat
+		estimate := float64(d.cfg.FirstHeartbeatEstimate.Milliseconds())
+		d.add(estimate - estimate/4)
+		d.add(estimate + estimate/4)
+	} else if now.After(d.last) {
+		d.add(float64(now.Sub(d.last).Milliseconds()))
+	}
+	if now.After(d.last) {
+		d.last = now
+	}
+}
+
+func (d *PhiDetector) add(interval float64) {
+	if len(d.intervals) < d.cfg.WindowSize {
+		d.intervals = append(d.intervals, interval)
+		return
+	}
+	d.intervals[d.next] = interval
+	d.next = (d.next + 1) % d.cfg.WindowSize
+}
+
+// LastSeen returns the time of the last heartbeat, which is zero if there were no heartbeats
+func (d *PhiDetector) LastSeen() time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.last
+}
+
+// Phi returns the suspicion level at the given time,
+// which is zero if there were no heartbeats
+func (d *PhiDetector) Phi(now time.Time) float64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.last.IsZero() {
+		return 0
+	}
+
+	mean, variance := 0.0, 0.0
+	for _, interval := range d.intervals {
+		mean += interval
+	}
+	mean /= float64(len(d.intervals))
+	for _, interval := range d.intervals {
+		variance += (interval - mean) * (interval - mean)
+	}
+	variance /= float64(len(d.intervals))
+
+	stdDev := max(math.Sqrt(variance), float64(d.cfg.MinStdDev.Milliseconds()))
+	mean += float64(d.cfg.AcceptablePause.Milliseconds())
+	elapsed := float64(now.Sub(d.last).Milliseconds())
+	return phi(elapsed, mean, stdDev)
+}
+
+// Suspicious returns true if phi at the given time exceeds the threshold,
+// or if there were no heartbeats at all
+func (d *PhiDetector) Suspicious(now time.Time) bool {
+	if d.LastSeen().IsZero() {
+		return true
+	}
+	return d.Phi(now) >= d.cfg.Threshold
+}
+
+// phi returns -log10 of the probability that a heartbeat arrives later than elapsed,
+// given normally distributed intervals. The logistic approximation
+// of the normal CDF is used, as in Akka and Cassandra.
+func phi(elapsed, mean, stdDev float64) float64 {
+	y := (elapsed - mean) / stdDev
+	e := math.Exp(-y * (1.5976 + 0.070566*y*y))
+	if elapsed > mean {
+		return -math.Log10(e / (1.0 + e))
+	}
+	return -math.Log10(1.0 - 1.0/(1.0+e))
+}
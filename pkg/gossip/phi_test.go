@@ -0,0 +1,72 @@
+package gossip
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPhiDetectorNoHeartbeats(t *testing.T) {
+	detector := NewPhiDetector(PhiConfig{})
+	now := time.Now()
+	assert.Zero(t, detector.Phi(now))
+	assert.True(t, detector.LastSeen().IsZero())
+	assert.True(t, detector.Suspicious(now))
+}
+
+func TestPhiDetector(t *testing.T) {
+	detector := NewPhiDetector(PhiConfig{MinStdDev: 10 * time.Millisecond, FirstHeartbeatEstimate: 100 * time.Millisecond})
+	start := time.Now()
+	for i := range 20 {
+		detector.Heartbeat(start.Add(time.Duration(i) * 100 * time.Millisecond))
+	}
+	last := detector.LastSeen()
+	assert.Equal(t, start.Add(1900*time.Millisecond), last)
+
+	assert.Less(t, detector.Phi(last.Add(50*time.Millisecond)), 1.0)
+	assert.False(t, detector.Suspicious(last.Add(100*time.Millisecond)))
+	assert.True(t, detector.Suspicious(last.Add(time.Second)))
+
+	previous := 0.0
+	for elapsed := time.Duration(0); elapsed < time.Second; elapsed += 50 * time.Millisecond {
+		phi := detector.Phi(last.Add(elapsed))
+		assert.GreaterOrEqual(t, phi, previous, "phi must not decrease over time")
+		previous = phi
+	}
+}
+
+func TestPhiDetectorIrregularHeartbeats(t *testing.T) {
+	cfg := PhiConfig{MinStdDev: 10 * time.Millisecond, FirstHeartbeatEstimate: 100 * time.Millisecond}
+	regular, irregular := NewPhiDetector(cfg), NewPhiDetector(cfg)
+	start := time.Now()
+	for i := range 20 {
+		regular.Heartbeat(start.Add(time.Duration(i) * 100 * time.Millisecond))
+		jitter := time.Duration(i%2) * 80 * time.Millisecond
+		irregular.Heartbeat(start.Add(time.Duration(i)*100*time.Millisecond + jitter))
+	}
+
+	// the same delay is more suspicious for the regular heartbeats
+	delay := 300 * time.Millisecond
+	assert.Greater(t, regular.Phi(regular.LastSeen().Add(delay)), irregular.Phi(irregular.LastSeen().Add(delay)))
+}
+
+func TestPhiDetectorAcceptablePause(t *testing.T) {
+	cfg := PhiConfig{MinStdDev: 10 * time.Millisecond, FirstHeartbeatEstimate: 100 * time.Millisecond}
+	strict := NewPhiDetector(cfg)
+	cfg.AcceptablePause = time.Second
+	tolerant := NewPhiDetector(cfg)
+
+	now := time.Now()
+	strict.Heartbeat(now)
+	tolerant.Heartbeat(now)
+	pause := now.Add(time.Second)
+	assert.True(t, strict.Suspicious(pause))
+	assert.False(t, tolerant.Suspicious(pause))
+}
+
+func TestPhi(t *testing.T) {
+	assert.InDelta(t, math.Log10(2), phi(100, 100, 10), 0.01, "half of heartbeats come later than mean")
+	assert.Greater(t, phi(200, 100, 10), 8.0)
+}
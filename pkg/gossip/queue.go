@@ -0,0 +1,68 @@
+package gossip
+
+import "sync"
+
+// changeQueue delivers membership changes to a channel without blocking
+// the protocol. Changes are sent right away while the channel has room,
+// the rest are queued and delivered in order by a separate goroutine.
+// Queued changes of the same member are coalesced, so only its latest
+// state is delivered.
+type changeQueue struct {
+	mu  sync.Mutex
+	out chan<- Member
+	// keys are keys of the members with queued changes, in the order of the changes
+	keys []string
+	// pending maps keys of the members to their latest queued changes
+	pending map[string]Member
+	// delivering is set while the queued changes are delivered
+	delivering bool
+}
+
+func newChangeQueue(out chan<- Member) *changeQueue {
+	return &changeQueue{out: out, keys: make([]string, 0), pending: make(map[string]Member)}
+}
+
+// push sends or queues the changes, it never blocks
+func (q *changeQueue) push(changes ...Member) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, change := range changes {
+		// changes are sent right away only if none are queued, so the order is kept
+		if !q.delivering {
+			select {
+			case q.out <- change:
+				continue
+			default:
+			}
+		}
+		key := change.Key()
+		if _, ok := q.pending[key]; !ok {
+			q.keys = append(q.keys, key)
+		}
+		q.pending[key] = change
+		if !q.delivering {
+			q.delivering = true
+			go q.deliver()
+		}
+	}
+}
+
+// deliver sends the queued changes until none are left
+func (q *changeQueue) deliver() {
+	for {
+		q.mu.Lock()
+		if len(q.keys) == 0 {
+			q.delivering = false
+			q.mu.Unlock()
+			return
+		}
+		key := q.keys[0]
+		q.keys = q.keys[1:]
+		change := q.pending[key]
+		delete(q.pending, key)
+		q.mu.Unlock()
+
+		q.out <- change
+	}
+}